@@ -0,0 +1,205 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJWTSecret(t *testing.T) {
+	t.Run("success development falls back to dev secret", func(t *testing.T) {
+		t.Setenv("ENV", "development")
+		t.Setenv("JWT_SECRET", "")
+
+		cfg := Load()
+		assert.Nil(t, cfg.Validate())
+		assert.NotEqual(t, "", cfg.JWT.Secret)
+	})
+
+	t.Run("error production without secret", func(t *testing.T) {
+		t.Setenv("ENV", EnvProduction)
+		t.Setenv("JWT_SECRET", "")
+
+		assert.NotNil(t, Load().Validate())
+	})
+
+	t.Run("success production with secret", func(t *testing.T) {
+		t.Setenv("ENV", EnvProduction)
+		t.Setenv("JWT_SECRET", "prod-secret")
+		t.Setenv("EMAIL_DRIVER", EmailDriverSMTP)
+		t.Setenv("SMTP_HOST", "smtp.example")
+		t.Setenv("EMAIL_FROM", "no-reply@mygram.example")
+
+		assert.Nil(t, Load().Validate())
+	})
+}
+
+func TestLoadVerboseErrors(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		env     string
+		verbose string
+		want    bool
+	}{
+		{desc: "success off when nothing is set"},
+		{desc: "success on for development", env: EnvDevelopment, want: true},
+		{desc: "success off for production", env: EnvProduction},
+		{desc: "success on when asked for", env: EnvProduction, verbose: "true", want: true},
+		{desc: "success development can turn it off", env: EnvDevelopment, verbose: "false"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Setenv("ENV", tC.env)
+			t.Setenv("VERBOSE_ERRORS", tC.verbose)
+
+			assert.Equal(t, tC.want, Load().VerboseErrors)
+		})
+	}
+}
+
+func TestValidateBcryptCost(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		cost  string
+		valid bool
+	}{
+		{desc: "success default", valid: true},
+		{desc: "success lowest cost", cost: "4", valid: true},
+		{desc: "success highest cost", cost: "31", valid: true},
+		{desc: "error below the lowest cost", cost: "3"},
+		{desc: "error above the highest cost", cost: "32"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Setenv("BCRYPT_COST", tC.cost)
+
+			assert.Equal(t, tC.valid, Load().Validate() == nil)
+		})
+	}
+}
+
+func TestValidateStorageDriver(t *testing.T) {
+	t.Setenv("STORAGE_DRIVER", "s3")
+
+	assert.NotNil(t, Load().Validate())
+}
+
+func TestValidateTokenStoreDriver(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		driver   string
+		redisURL string
+		valid    bool
+	}{
+		{desc: "success memory", driver: TokenStoreMemory, valid: true},
+		{desc: "success redis with url", driver: TokenStoreRedis, redisURL: "redis://localhost:6379/0", valid: true},
+		{desc: "error redis without url", driver: TokenStoreRedis},
+		{desc: "error unknown driver", driver: "memcached"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Setenv("TOKEN_STORE_DRIVER", tC.driver)
+			t.Setenv("REDIS_URL", tC.redisURL)
+
+			assert.Equal(t, tC.valid, Load().Validate() == nil)
+		})
+	}
+}
+
+func TestValidateEmailDriver(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		env    string
+		driver string
+		host   string
+		from   string
+		valid  bool
+	}{
+		{desc: "success log", driver: EmailDriverLog, valid: true},
+		{desc: "error log in production", env: EnvProduction, driver: EmailDriverLog},
+		{desc: "success smtp", driver: EmailDriverSMTP, host: "smtp.example", from: "no-reply@mygram.example", valid: true},
+		{desc: "error smtp without host", driver: EmailDriverSMTP, from: "no-reply@mygram.example"},
+		{desc: "error smtp without sender", driver: EmailDriverSMTP, host: "smtp.example"},
+		{desc: "error unknown driver", driver: "sendgrid"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Setenv("ENV", tC.env)
+			t.Setenv("JWT_SECRET", "prod-secret")
+			t.Setenv("EMAIL_DRIVER", tC.driver)
+			t.Setenv("SMTP_HOST", tC.host)
+			t.Setenv("EMAIL_FROM", tC.from)
+
+			assert.Equal(t, tC.valid, Load().Validate() == nil)
+		})
+	}
+}
+
+func TestValidateLegacyAdminUserIDs(t *testing.T) {
+	t.Setenv("ADMIN_USER_IDS", "1,2")
+
+	err := Load().Validate()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "WHERE id IN (1,2)")
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		url   string
+		valid bool
+	}{
+		{desc: "success disabled", valid: true},
+		{desc: "success https url", url: "https://crm.example/hooks/signup", valid: true},
+		{desc: "error no scheme", url: "crm.example/hooks/signup"},
+		{desc: "error unsupported scheme", url: "ftp://crm.example/hooks"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Setenv("WEBHOOK_SIGNUP_URL", tC.url)
+
+			assert.Equal(t, tC.valid, Load().Validate() == nil)
+		})
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	testCases := []struct {
+		desc string
+		env  string
+		path string
+	}{
+		{desc: "success default", env: "", path: "/api/v1"},
+		{desc: "success add leading slash", env: "api/v2", path: "/api/v2"},
+		{desc: "success drop trailing slash", env: "/api/v2/", path: "/api/v2"},
+		{desc: "success root", env: "/", path: ""},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			t.Setenv("API_BASE_PATH", tC.env)
+
+			assert.Equal(t, tC.path, Load().Server.BasePath)
+		})
+	}
+}
+
+func TestCSRFHeaderAllowedByCORS(t *testing.T) {
+	t.Run("success disabled leaves the headers alone", func(t *testing.T) {
+		t.Setenv("CSRF_ENABLED", "false")
+
+		assert.NotContains(t, Load().CORS.AllowedHeaders, "X-CSRF-Token")
+	})
+
+	t.Run("success enabled allows the header once", func(t *testing.T) {
+		t.Setenv("CSRF_ENABLED", "true")
+		t.Setenv("CORS_ALLOWED_HEADERS", "Authorization,x-csrf-token")
+
+		assert.Equal(t, []string{"Authorization", "x-csrf-token"}, Load().CORS.AllowedHeaders)
+	})
+
+	t.Run("success enabled adds the header", func(t *testing.T) {
+		t.Setenv("CSRF_ENABLED", "true")
+
+		assert.Contains(t, Load().CORS.AllowedHeaders, "X-CSRF-Token")
+	})
+}
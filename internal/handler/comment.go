@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/service"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler interface {
+	CreateComment(ctx *gin.Context)
+	GetComments(ctx *gin.Context)
+	GetPhotoComments(ctx *gin.Context)
+	CreatePhotoComment(ctx *gin.Context)
+	GetCommentByID(ctx *gin.Context)
+	UpdateComment(ctx *gin.Context)
+	DeleteComment(ctx *gin.Context)
+}
+
+type commentHandlerImpl struct {
+	commentService service.CommentService
+}
+
+func NewCommentHandler(commentService service.CommentService) CommentHandler {
+	return &commentHandlerImpl{
+		commentService: commentService,
+	}
+}
+
+// CreateComment godoc
+//
+//	@Summary		Create a comment
+//	@Description	verified accounts only. A retried request with the same Idempotency-Key replays the first response
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Idempotency-Key	header		string				false	"unique key of this create"
+//	@Param			request			body		model.CommentPost	true	"photo and message"
+//	@Success		201				{object}	model.Comment
+//	@Header			201				{string}	Location	"url of the new comment"
+//	@Failure		400				{object}	pkg.ErrorResponse
+//	@Failure		401				{object}	pkg.ErrorResponse
+//	@Failure		403				{object}	pkg.ErrorResponse
+//	@Failure		404				{object}	pkg.ErrorResponse
+//	@Failure		409				{object}	pkg.ErrorResponse
+//	@Failure		422				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/comments [post]
+func (h *commentHandlerImpl) CreateComment(ctx *gin.Context) {
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	var comment model.CommentPost
+	if err := ctx.ShouldBindJSON(&comment); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return
+	}
+
+	if err := comment.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+
+	createdComment, err := h.commentService.CreateComment(ctx, userID, comment)
+	if err != nil {
+		h.writeCommentError(ctx, err)
+		return
+	}
+	pkg.SetLocation(ctx, "comments/"+strconv.FormatUint(createdComment.ID, 10))
+	ctx.JSON(http.StatusCreated, createdComment)
+}
+
+// GetComments godoc
+//
+//	@Summary		Show comments
+//	@Description	most recent comments of the photos the current user can see, of a single photo when photo_id is given
+//	@Tags			comments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			photo_id	query		int		false	"only comments of this photo"
+//	@Param			cursor		query		string	false	"next_cursor of the previous page"
+//	@Param			limit		query		int		false	"page size, default 20, max 100"
+//	@Success		200			{object}	pkg.CursorPage[model.Comment]
+//	@Failure		400			{object}	pkg.ErrorResponse
+//	@Failure		401			{object}	pkg.ErrorResponse
+//	@Failure		403			{object}	pkg.ErrorResponse
+//	@Failure		404			{object}	pkg.ErrorResponse
+//	@Failure		500			{object}	pkg.ErrorResponse
+//	@Router			/comments [get]
+func (h *commentHandlerImpl) GetComments(ctx *gin.Context) {
+	var photoID uint64
+	if photoIDStr := ctx.Query("photo_id"); photoIDStr != "" {
+		id, err := strconv.ParseUint(photoIDStr, 10, 64)
+		if id == 0 || err != nil {
+			pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+			return
+		}
+		photoID = id
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	after, limit, ok := cursorParams(ctx)
+	if !ok {
+		return
+	}
+
+	comments, err := h.commentService.GetComments(ctx, userID, photoID, after, limit)
+	if err != nil {
+		h.writeCommentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, comments)
+}
+
+// GetPhotoComments godoc
+//
+//	@Summary		Show comments of a photo
+//	@Description	comments of the photo with their author, newest first
+//	@Tags			comments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int		true	"Photo ID"
+//	@Param			cursor	query		string	false	"next_cursor of the previous page"
+//	@Param			limit	query		int		false	"page size, default 20, max 100"
+//	@Success		200		{object}	pkg.CursorPage[model.Comment]
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/photos/{id}/comments [get]
+func (h *commentHandlerImpl) GetPhotoComments(ctx *gin.Context) {
+	photoID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if photoID == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	after, limit, ok := cursorParams(ctx)
+	if !ok {
+		return
+	}
+
+	comments, err := h.commentService.GetPhotoComments(ctx, userID, photoID, after, limit)
+	if err != nil {
+		h.writeCommentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, comments)
+}
+
+// CreatePhotoComment godoc
+//
+//	@Summary		Comment on a photo
+//	@Description	verified accounts only, the photo is taken from the path. A retried request with the same Idempotency-Key replays the first response
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id				path		int					true	"Photo ID"
+//	@Param			Idempotency-Key	header		string				false	"unique key of this create"
+//	@Param			request			body		model.CommentUpdate	true	"message"
+//	@Success		201				{object}	model.Comment
+//	@Header			201				{string}	Location	"url of the new comment"
+//	@Failure		400				{object}	pkg.ErrorResponse
+//	@Failure		401				{object}	pkg.ErrorResponse
+//	@Failure		403				{object}	pkg.ErrorResponse
+//	@Failure		404				{object}	pkg.ErrorResponse
+//	@Failure		409				{object}	pkg.ErrorResponse
+//	@Failure		422				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/photos/{id}/comments [post]
+func (h *commentHandlerImpl) CreatePhotoComment(ctx *gin.Context) {
+	photoID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if photoID == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	var body model.CommentUpdate
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return
+	}
+	comment := model.CommentPost{PhotoID: photoID, Message: body.Message}
+	if err := comment.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+
+	createdComment, err := h.commentService.CreateComment(ctx, userID, comment)
+	if err != nil {
+		h.writeCommentError(ctx, err)
+		return
+	}
+	// the comment lives at /comments/{id} next to /photos, not under the photo
+	apiRoot := strings.TrimSuffix(ctx.Request.URL.Path, "/photos/"+ctx.Param("id")+"/comments")
+	pkg.SetLocation(ctx, apiRoot+"/comments/"+strconv.FormatUint(createdComment.ID, 10))
+	ctx.JSON(http.StatusCreated, createdComment)
+}
+
+// GetCommentByID godoc
+//
+//	@Summary		Show a comment
+//	@Description	the author may add the message as posted, before the markup was stripped, with include=raw. It is returned as raw_message
+//	@Tags			comments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int		true	"Comment ID"
+//	@Param			include	query		string	false	"raw to add the message as posted, author only"
+//	@Success		200		{object}	model.Comment
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/comments/{id} [get]
+func (h *commentHandlerImpl) GetCommentByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid comment id")
+		return
+	}
+	includeRaw := false
+	switch ctx.Query("include") {
+	case "":
+	case "raw":
+		includeRaw = true
+	default:
+		pkg.WriteError(ctx, http.StatusBadRequest, "include must be raw")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	comment, err := h.commentService.GetCommentByID(ctx, userID, id)
+	if err != nil {
+		h.writeCommentError(ctx, err)
+		return
+	}
+	if includeRaw {
+		if comment.UserID != userID {
+			pkg.WriteError(ctx, http.StatusForbidden, "only the author can read the raw message")
+			return
+		}
+		ctx.JSON(http.StatusOK, comment.ToRawResponse())
+		return
+	}
+	ctx.JSON(http.StatusOK, comment)
+}
+
+// UpdateComment godoc
+//
+//	@Summary		Update a comment
+//	@Description	will replace the message of a comment owned by the current user, only within the edit window after it was posted
+//	@Tags			comments
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int					true	"Comment ID"
+//	@Param			request	body		model.CommentUpdate	true	"message"
+//	@Success		200		{object}	model.Comment
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/comments/{id} [put]
+func (h *commentHandlerImpl) UpdateComment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid comment id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	var commentUpdate model.CommentUpdate
+	if err := ctx.ShouldBindJSON(&commentUpdate); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return
+	}
+
+	if err := commentUpdate.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+
+	updatedComment, err := h.commentService.UpdateComment(ctx, userID, id, commentUpdate)
+	if err != nil {
+		h.writeCommentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, updatedComment)
+}
+
+// DeleteComment godoc
+//
+//	@Summary		Delete a comment
+//	@Description	will delete a comment owned by the current user
+//	@Tags			comments
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Comment ID"
+//	@Success		200	{object}	map[string]string
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/comments/{id} [delete]
+func (h *commentHandlerImpl) DeleteComment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid comment id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	err = h.commentService.DeleteComment(ctx, userID, id)
+	if err != nil {
+		h.writeCommentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+func (h *commentHandlerImpl) writeCommentError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrPhotoNotFound):
+		writeServiceError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrCommentNotOwner), errors.Is(err, service.ErrCommentEditWindowExpired), errors.Is(err, service.ErrPhotoNotVisible):
+		writeServiceError(ctx, http.StatusForbidden, err)
+	default:
+		pkg.WriteServerError(ctx, err, err.Error())
+	}
+}
@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/internal/middleware"
+	"go-mygram/internal/model"
+	"go-mygram/internal/service"
+	"go-mygram/internal/service/mocks"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentPhotoVisibility(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		svcErr error
+		code   int
+	}{
+		{desc: "error followers only photo of a user not followed", svcErr: service.ErrPhotoNotVisible, code: http.StatusForbidden},
+		{desc: "error private photo of another user", svcErr: service.ErrPhotoNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run("create comment "+tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBufferString(`{"photo_id":3,"message":"nice"}`))
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			svcMock.On("CreateComment", g, uint64(7), model.CommentPost{PhotoID: 3, Message: "nice"}).Return(model.Comment{}, tC.svcErr)
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.CreateComment(g)
+
+			assert.Equal(t, tC.code, rec.Result().StatusCode)
+		})
+
+		t.Run("create photo comment "+tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/photos/3/comments", bytes.NewBufferString(`{"message":"nice"}`))
+			g.Params = gin.Params{{Key: "id", Value: "3"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			svcMock.On("CreateComment", g, uint64(7), model.CommentPost{PhotoID: 3, Message: "nice"}).Return(model.Comment{}, tC.svcErr)
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.CreatePhotoComment(g)
+
+			assert.Equal(t, tC.code, rec.Result().StatusCode)
+		})
+
+		t.Run("get comments by photo id "+tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/comments?photo_id=3", nil)
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			svcMock.On("GetComments", g, uint64(7), uint64(3), (*pkg.Cursor)(nil), 20).Return(pkg.CursorPage[model.Comment]{}, tC.svcErr)
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.GetComments(g)
+
+			assert.Equal(t, tC.code, rec.Result().StatusCode)
+		})
+
+		t.Run("get photo comments "+tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/photos/3/comments", nil)
+			g.Params = gin.Params{{Key: "id", Value: "3"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			svcMock.On("GetPhotoComments", g, uint64(7), uint64(3), (*pkg.Cursor)(nil), 20).Return(pkg.CursorPage[model.Comment]{}, tC.svcErr)
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.GetPhotoComments(g)
+
+			assert.Equal(t, tC.code, rec.Result().StatusCode)
+		})
+	}
+}
+
+func TestGetPhotoComments(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		photoID string
+		call    bool
+		svcErr  error
+		code    int
+	}{
+		{desc: "success list photo comments", photoID: "3", call: true, code: http.StatusOK},
+		{desc: "error invalid photo id", photoID: "abc", code: http.StatusBadRequest},
+		{desc: "error photo id zero", photoID: "0", code: http.StatusBadRequest},
+		{desc: "error photo not found", photoID: "3", call: true, svcErr: service.ErrPhotoNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/photos/"+tC.photoID+"/comments", nil)
+			g.Params = gin.Params{{Key: "id", Value: tC.photoID}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			if tC.call {
+				svcMock.On("GetPhotoComments", g, uint64(7), uint64(3), (*pkg.Cursor)(nil), 20).
+					Return(pkg.CursorPage[model.Comment]{Data: []model.Comment{{ID: 5, PhotoID: 3}}}, tC.svcErr)
+			}
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.GetPhotoComments(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestCreatePhotoComment(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		photoID string
+		body    string
+		call    bool
+		svcErr  error
+		code    int
+	}{
+		{desc: "success comment on a photo", photoID: "3", body: `{"message":"nice"}`, call: true, code: http.StatusCreated},
+		{desc: "error invalid photo id", photoID: "abc", body: `{"message":"nice"}`, code: http.StatusBadRequest},
+		{desc: "error photo id zero", photoID: "0", body: `{"message":"nice"}`, code: http.StatusBadRequest},
+		{desc: "error photo not found", photoID: "3", body: `{"message":"nice"}`, call: true, svcErr: service.ErrPhotoNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/photos/"+tC.photoID+"/comments", bytes.NewBufferString(tC.body))
+			g.Params = gin.Params{{Key: "id", Value: tC.photoID}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			if tC.call {
+				svcMock.On("CreateComment", g, uint64(7), model.CommentPost{PhotoID: 3, Message: "nice"}).
+					Return(model.Comment{ID: 5, PhotoID: 3, Message: "nice"}, tC.svcErr)
+			}
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.CreatePhotoComment(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestGetCommentByID(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success comment on a visible photo", code: http.StatusOK},
+		{desc: "error comment not found", svcErr: service.ErrCommentNotFound, code: http.StatusNotFound},
+		{desc: "error followers only photo of a user not followed", svcErr: service.ErrPhotoNotVisible, code: http.StatusForbidden},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/comments/5", nil)
+			g.Params = gin.Params{{Key: "id", Value: "5"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			svcMock.On("GetCommentByID", g, uint64(7), uint64(5)).Return(model.Comment{ID: 5}, tC.svcErr)
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.GetCommentByID(g)
+
+			assert.Equal(t, tC.code, rec.Result().StatusCode)
+		})
+	}
+}
+
+func TestGetCommentByIDRaw(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		authorID uint64
+		code     int
+	}{
+		{desc: "success author asks for the raw message", authorID: 7, code: http.StatusOK},
+		{desc: "error raw message of another user", authorID: 2, code: http.StatusForbidden},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/comments/5?include=raw", nil)
+			g.Params = gin.Params{{Key: "id", Value: "5"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			svcMock.On("GetCommentByID", g, uint64(7), uint64(5)).
+				Return(model.Comment{ID: 5, UserID: tC.authorID, Message: "nice", RawMessage: "<i>nice</i>"}, nil)
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.GetCommentByID(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+			if tC.code == http.StatusOK {
+				assert.Contains(t, rec.Body.String(), `"raw_message":"\u003ci\u003enice\u003c/i\u003e"`)
+			}
+		})
+	}
+}
+
+func TestUpdateComment(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success update own comment", code: http.StatusOK},
+		{desc: "error edit window expired", svcErr: service.ErrCommentEditWindowExpired, code: http.StatusForbidden},
+		{desc: "error comment of another user", svcErr: service.ErrCommentNotOwner, code: http.StatusForbidden},
+		{desc: "error comment not found", svcErr: service.ErrCommentNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPut, "/comments/5", bytes.NewBufferString(`{"message":"edited"}`))
+			g.Params = gin.Params{{Key: "id", Value: "5"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			svcMock.On("UpdateComment", g, uint64(7), uint64(5), model.CommentUpdate{Message: "edited"}).
+				Return(model.Comment{ID: 5, UserID: 7, Message: "edited"}, tC.svcErr)
+
+			hdl := commentHandlerImpl{commentService: svcMock}
+			hdl.UpdateComment(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+			if tC.svcErr == service.ErrCommentEditWindowExpired {
+				assert.Contains(t, rec.Body.String(), pkg.CodeEditWindowExpired)
+			}
+		})
+	}
+}
+
+func TestCreateCommentLocation(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		path   string
+		params gin.Params
+		body   string
+		create func(hdl commentHandlerImpl, g *gin.Context)
+	}{
+		{desc: "success create comment", path: "/api/v1/comments", body: `{"photo_id":3,"message":"nice"}`, create: func(hdl commentHandlerImpl, g *gin.Context) { hdl.CreateComment(g) }},
+		{desc: "success create photo comment", path: "/api/v1/photos/3/comments", params: gin.Params{{Key: "id", Value: "3"}}, body: `{"message":"nice"}`, create: func(hdl commentHandlerImpl, g *gin.Context) { hdl.CreatePhotoComment(g) }},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, tC.path, bytes.NewBufferString(tC.body))
+			g.Params = tC.params
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewCommentService(t)
+			svcMock.On("CreateComment", g, uint64(7), model.CommentPost{PhotoID: 3, Message: "nice"}).Return(model.Comment{ID: 5, PhotoID: 3}, nil)
+
+			tC.create(commentHandlerImpl{commentService: svcMock}, g)
+
+			assert.Equal(t, http.StatusCreated, rec.Result().StatusCode)
+			assert.Equal(t, "/api/v1/comments/5", rec.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"go-mygram/internal/service"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serviceErrorCodes gives the sentinel errors of the services their code,
+// handlers still pick the status but never spell a code themselves
+var serviceErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{service.ErrUserNotFound, pkg.CodeUserNotFound},
+	{service.ErrEmailAlreadyExists, pkg.CodeEmailTaken},
+	{service.ErrUsernameAlreadyExists, pkg.CodeUsernameTaken},
+	{service.ErrUserModified, pkg.CodeVersionConflict},
+	{service.ErrInvalidCredentials, pkg.CodeInvalidCredentials},
+	{service.ErrAccountLocked, pkg.CodeAccountLocked},
+	{service.ErrEmailNotVerified, pkg.CodeEmailNotVerified},
+	{service.ErrWrongCurrentPassword, pkg.CodeWrongPassword},
+	{service.ErrPasswordUnchanged, pkg.CodePasswordUnchanged},
+	{service.ErrInvalidRefreshToken, pkg.CodeInvalidToken},
+	{service.ErrRefreshTokenExpired, pkg.CodeTokenExpired},
+	{service.ErrRefreshTokenRevoked, pkg.CodeTokenRevoked},
+	{service.ErrInvalidVerificationToken, pkg.CodeInvalidToken},
+	{service.ErrVerificationTokenExpired, pkg.CodeTokenExpired},
+	{service.ErrInvalidResetToken, pkg.CodeInvalidToken},
+	{service.ErrResetTokenExpired, pkg.CodeTokenExpired},
+	{service.ErrUnsupportedImageType, pkg.CodeUnsupportedImage},
+	{service.ErrFollowSelf, pkg.CodeFollowSelf},
+	{service.ErrPhotoNotFound, pkg.CodePhotoNotFound},
+	{service.ErrPhotoNotOwner, pkg.CodeNotOwner},
+	{service.ErrPhotoNotVisible, pkg.CodePhotoNotVisible},
+	{service.ErrPhotoLimitReached, pkg.CodePhotoLimitReached},
+	{service.ErrCommentNotFound, pkg.CodeCommentNotFound},
+	{service.ErrCommentNotOwner, pkg.CodeNotOwner},
+	{service.ErrCommentEditWindowExpired, pkg.CodeEditWindowExpired},
+	{service.ErrSocialMediaNotFound, pkg.CodeSocialMediaNotFound},
+	{service.ErrSocialMediaNotOwner, pkg.CodeNotOwner},
+}
+
+// errorCode returns the code of the sentinel error err wraps, or the
+// generic code of status
+func errorCode(err error, status int) string {
+	for _, c := range serviceErrorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return pkg.CodeForStatus(status)
+}
+
+// writeServiceError writes a sentinel error of a service with its code and
+// its own text as the message
+func writeServiceError(ctx *gin.Context, status int, err error) {
+	pkg.WriteErrorCode(ctx, status, errorCode(err, status), err.Error())
+}
+
+// writeError reports err of a service with the status of its kind, see
+// pkg.HTTPStatusFromError. An error of no kind is a server error, message
+// is what the client is told about it outside production
+func writeError(ctx *gin.Context, err error, message string) {
+	var verrs pkg.ValidationErrors
+	status := pkg.HTTPStatusFromError(err)
+	switch {
+	case errors.As(err, &verrs):
+		pkg.WriteValidationError(ctx, verrs)
+	case status == http.StatusInternalServerError:
+		pkg.WriteServerError(ctx, err, message)
+	default:
+		writeServiceError(ctx, status, err)
+	}
+}
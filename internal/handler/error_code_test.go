@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-mygram/internal/service"
+	"go-mygram/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		err    error
+		status int
+		code   string
+	}{
+		{desc: "success sentinel", err: service.ErrUserNotFound, status: http.StatusNotFound, code: pkg.CodeUserNotFound},
+		{desc: "success wrapped sentinel", err: fmt.Errorf("%w, you can keep at most 5 photos", service.ErrPhotoLimitReached), status: http.StatusForbidden, code: pkg.CodePhotoLimitReached},
+		{desc: "success not owner of a photo", err: service.ErrPhotoNotOwner, status: http.StatusForbidden, code: pkg.CodeNotOwner},
+		{desc: "success not owner of a social media", err: service.ErrSocialMediaNotOwner, status: http.StatusForbidden, code: pkg.CodeNotOwner},
+		{desc: "success social media not found", err: service.ErrSocialMediaNotFound, status: http.StatusNotFound, code: pkg.CodeSocialMediaNotFound},
+		{desc: "success unknown error falls back to the status", err: errors.New("boom"), status: http.StatusConflict, code: pkg.CodeConflict},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.code, errorCode(tC.err, tC.status))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-mygram/internal/service"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedHandler interface {
+	GetFeed(ctx *gin.Context)
+	GetFollowingFeed(ctx *gin.Context)
+}
+
+type feedHandlerImpl struct {
+	feedService service.FeedService
+}
+
+func NewFeedHandler(feedService service.FeedService) FeedHandler {
+	return &feedHandlerImpl{feedService: feedService}
+}
+
+// GetFeed godoc
+//
+//	@Summary		Show the feed
+//	@Description	most recent photos of every user with owner, like count and latest comments
+//	@Tags			feed
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			cursor	query		string	false	"next_cursor of the previous page"
+//	@Param			limit	query		int		false	"page size, default 20, max 100"
+//	@Success		200		{object}	pkg.SuccessResponse{data=pkg.CursorPage[model.FeedItem]}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/feed [get]
+func (h *feedHandlerImpl) GetFeed(ctx *gin.Context) {
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	after, limit, ok := cursorParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := h.feedService.GetFeed(ctx, userID, after, limit)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to get feed")
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, page)
+}
+
+// GetFollowingFeed godoc
+//
+//	@Summary		Show the following feed
+//	@Description	the feed limited to the users the current user follows, empty when following nobody
+//	@Tags			feed
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			cursor	query		string	false	"next_cursor of the previous page"
+//	@Param			limit	query		int		false	"page size, default 20, max 100"
+//	@Success		200		{object}	pkg.SuccessResponse{data=pkg.CursorPage[model.FeedItem]}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/feed/following [get]
+func (h *feedHandlerImpl) GetFollowingFeed(ctx *gin.Context) {
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	after, limit, ok := cursorParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := h.feedService.GetFollowingFeed(ctx, userID, after, limit)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to get feed")
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, page)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/internal/middleware"
+	"go-mygram/internal/model"
+	"go-mygram/internal/service/mocks"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFeed(t *testing.T) {
+	t.Run("error invalid cursor", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/feed?cursor=garbage", nil)
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		hdl := feedHandlerImpl{}
+		hdl.GetFeed(g)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("success limit is capped", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/feed?limit=1000", nil)
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		svcMock := mocks.NewFeedService(t)
+		svcMock.On("GetFeed", g, uint64(7), (*pkg.Cursor)(nil), pkg.MaxPageLimit).Return(pkg.CursorPage[model.FeedItem]{Data: []model.FeedItem{}}, nil)
+
+		hdl := feedHandlerImpl{feedService: svcMock}
+		hdl.GetFeed(g)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
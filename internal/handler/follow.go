@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-mygram/internal/service"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowHandler interface {
+	Follow(ctx *gin.Context)
+	Unfollow(ctx *gin.Context)
+	GetFollowers(ctx *gin.Context)
+	GetFollowing(ctx *gin.Context)
+}
+
+type followHandlerImpl struct {
+	followService service.FollowService
+}
+
+func NewFollowHandler(followService service.FollowService) FollowHandler {
+	return &followHandlerImpl{followService: followService}
+}
+
+// Follow godoc
+//
+//	@Summary		Follow a user
+//	@Description	following a user twice is not an error
+//	@Tags			follows
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path	int	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/users/{id}/follow [post]
+func (h *followHandlerImpl) Follow(ctx *gin.Context) {
+	id, followerID, ok := followParams(ctx)
+	if !ok {
+		return
+	}
+	if err := h.followService.Follow(ctx, followerID, id); err != nil {
+		h.writeFollowError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// Unfollow godoc
+//
+//	@Summary		Unfollow a user
+//	@Description	a user that wasn't followed is not an error
+//	@Tags			follows
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path	int	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/users/{id}/follow [delete]
+func (h *followHandlerImpl) Unfollow(ctx *gin.Context) {
+	id, followerID, ok := followParams(ctx)
+	if !ok {
+		return
+	}
+	if err := h.followService.Unfollow(ctx, followerID, id); err != nil {
+		h.writeFollowError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetFollowers godoc
+//
+//	@Summary		Show followers of a user
+//	@Description	users following the user, latest follow first
+//	@Tags			follows
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int		true	"User ID"
+//	@Param			cursor	query		string	false	"next_cursor of the previous page"
+//	@Param			limit	query		int		false	"page size, default 20, max 100"
+//	@Success		200		{object}	pkg.SuccessResponse{data=pkg.CursorPage[model.FollowUser]}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/{id}/followers [get]
+func (h *followHandlerImpl) GetFollowers(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	after, limit, ok := cursorParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := h.followService.GetFollowers(ctx, id, after, limit)
+	if err != nil {
+		h.writeFollowError(ctx, err)
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, page)
+}
+
+// GetFollowing godoc
+//
+//	@Summary		Show users followed by a user
+//	@Description	users the user follows, latest follow first
+//	@Tags			follows
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int		true	"User ID"
+//	@Param			cursor	query		string	false	"next_cursor of the previous page"
+//	@Param			limit	query		int		false	"page size, default 20, max 100"
+//	@Success		200		{object}	pkg.SuccessResponse{data=pkg.CursorPage[model.FollowUser]}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/{id}/following [get]
+func (h *followHandlerImpl) GetFollowing(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	after, limit, ok := cursorParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := h.followService.GetFollowing(ctx, id, after, limit)
+	if err != nil {
+		h.writeFollowError(ctx, err)
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, page)
+}
+
+// followParams reads the followed user from the path and the follower from
+// the session, on failure it writes the response and returns false
+func followParams(ctx *gin.Context) (uint64, uint64, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid user id")
+		return 0, 0, false
+	}
+	followerID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return 0, 0, false
+	}
+	return id, followerID, true
+}
+
+func (h *followHandlerImpl) writeFollowError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrFollowSelf):
+		writeServiceError(ctx, http.StatusBadRequest, err)
+	case errors.Is(err, service.ErrUserNotFound):
+		writeServiceError(ctx, http.StatusNotFound, err)
+	default:
+		pkg.WriteServerError(ctx, err, err.Error())
+	}
+}
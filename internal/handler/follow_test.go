@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/internal/middleware"
+	"go-mygram/internal/service"
+	"go-mygram/internal/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollow(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		param  string
+		id     uint64
+		err    error
+		mock   bool
+		status int
+	}{
+		{desc: "error invalid user id", param: "abc", status: http.StatusBadRequest},
+		{desc: "error follow yourself", param: "7", id: 7, err: service.ErrFollowSelf, mock: true, status: http.StatusBadRequest},
+		{desc: "error unknown user", param: "9", id: 9, err: service.ErrUserNotFound, mock: true, status: http.StatusNotFound},
+		{desc: "success follow", param: "9", id: 9, mock: true, status: http.StatusNoContent},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/users/"+tC.param+"/follow", nil)
+			g.Params = gin.Params{{Key: "id", Value: tC.param}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewFollowService(t)
+			if tC.mock {
+				svcMock.On("Follow", g, uint64(7), tC.id).Return(tC.err)
+			}
+
+			hdl := followHandlerImpl{followService: svcMock}
+			hdl.Follow(g)
+			// gin only flushes a bare status once something writes the body
+			g.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tC.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go-mygram/internal/infrastructure"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler interface {
+	Healthz(ctx *gin.Context)
+	Readyz(ctx *gin.Context)
+	DBStats(ctx *gin.Context)
+}
+
+// DBPoolStats is the subset of sql.DBStats worth watching under load
+type DBPoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	// WaitDurationMs is the total time spent waiting for a free connection
+	WaitDurationMs    int64 `json:"wait_duration_ms"`
+	MaxIdleClosed     int64 `json:"max_idle_closed"`
+	MaxLifetimeClosed int64 `json:"max_lifetime_closed"`
+}
+
+type healthHandlerImpl struct {
+	db        infrastructure.GormPostgres
+	dbTimeout time.Duration
+}
+
+func NewHealthHandler(db infrastructure.GormPostgres, dbTimeout time.Duration) HealthHandler {
+	return &healthHandlerImpl{db: db, dbTimeout: dbTimeout}
+}
+
+// Healthz godoc
+//
+//	@Summary		Liveness probe
+//	@Description	returns 200 as long as the process is up
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	pkg.SuccessResponse
+//	@Router			/healthz [get]
+func (h *healthHandlerImpl) Healthz(ctx *gin.Context) {
+	pkg.WriteMessage(ctx, http.StatusOK, "ok")
+}
+
+// Readyz godoc
+//
+//	@Summary		Readiness probe
+//	@Description	returns 200 only when the database answers a ping
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	pkg.SuccessResponse
+//	@Failure		503	{object}	pkg.ErrorResponse
+//	@Router			/readyz [get]
+func (h *healthHandlerImpl) Readyz(ctx *gin.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx.Request.Context(), h.dbTimeout)
+	defer cancel()
+
+	if err := h.db.Ping(pingCtx); err != nil {
+		// the cause goes to the request log, probes are often public
+		_ = ctx.Error(err)
+		pkg.WriteError(ctx, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+	pkg.WriteMessage(ctx, http.StatusOK, "ok")
+}
+
+// DBStats godoc
+//
+//	@Summary		Database pool stats
+//	@Description	internal endpoint, only mounted when DEBUG_ENDPOINTS_ENABLED is set
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	pkg.SuccessResponse{data=handler.DBPoolStats}
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/debug/db [get]
+func (h *healthHandlerImpl) DBStats(ctx *gin.Context) {
+	stats, err := h.db.Stats()
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to read pool stats")
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, DBPoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	})
+}
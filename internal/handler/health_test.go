@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-mygram/internal/infrastructure/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReadyz(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		pingErr error
+		code    int
+	}{
+		{desc: "success database reachable", code: http.StatusOK},
+		{desc: "error database unreachable", pingErr: errors.New("connection refused"), code: http.StatusServiceUnavailable},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+			dbMock := mocks.NewGormPostgres(t)
+			dbMock.On("Ping", mock.Anything).Return(tC.pingErr)
+
+			hdl := healthHandlerImpl{db: dbMock, dbTimeout: time.Second}
+			hdl.Readyz(g)
+
+			assert.Equal(t, tC.code, rec.Result().StatusCode)
+			if tC.pingErr != nil {
+				assert.NotContains(t, rec.Body.String(), tC.pingErr.Error())
+				assert.Contains(t, g.Errors.String(), tC.pingErr.Error())
+			}
+		})
+	}
+}
+
+func TestDBStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	g, _ := gin.CreateTestContext(rec)
+	g.Request = httptest.NewRequest(http.MethodGet, "/debug/db", nil)
+
+	dbMock := mocks.NewGormPostgres(t)
+	dbMock.On("Stats").Return(sql.DBStats{MaxOpenConnections: 25, OpenConnections: 3, InUse: 2, Idle: 1, WaitDuration: 1500 * time.Millisecond}, nil)
+
+	hdl := healthHandlerImpl{db: dbMock}
+	hdl.DBStats(g)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data DBPoolStats `json:"data"`
+	}
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, DBPoolStats{MaxOpenConnections: 25, OpenConnections: 3, InUse: 2, Idle: 1, WaitDurationMs: 1500}, body.Data)
+}
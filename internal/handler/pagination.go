@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"net/http"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cursorParams reads ?cursor and ?limit, on invalid input it writes a 400
+// and returns false
+func cursorParams(ctx *gin.Context) (*pkg.Cursor, int, bool) {
+	limit, err := pkg.ParseLimit(ctx)
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return nil, 0, false
+	}
+
+	raw := ctx.Query("cursor")
+	if raw == "" {
+		return nil, limit, true
+	}
+	cursor, err := pkg.DecodeCursor(raw)
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid cursor param")
+		return nil, 0, false
+	}
+	return &cursor, limit, true
+}
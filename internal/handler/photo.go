@@ -1,121 +1,510 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"go-mygram/internal/model"
 	"go-mygram/internal/service"
+	"go-mygram/pkg"
 
 	"github.com/gin-gonic/gin"
 )
 
 type PhotoHandler interface {
 	GetPhotos(ctx *gin.Context)
+	GetPhotosByUserID(ctx *gin.Context)
 	GetPhotoByID(ctx *gin.Context)
 	UpdatePhoto(ctx *gin.Context)
-	DeletePhotoByID(ctx *gin.Context)
+	DeletePhoto(ctx *gin.Context)
 	CreatePhoto(ctx *gin.Context)
+	LikePhoto(ctx *gin.Context)
+	UnlikePhoto(ctx *gin.Context)
+	GetPhotoLikers(ctx *gin.Context)
+	UploadPhotoImage(ctx *gin.Context)
+	GetPopularTags(ctx *gin.Context)
 }
 
 type photoHandlerImpl struct {
-	photoService service.PhotoService
+	photoService   service.PhotoService
+	uploadMaxBytes int64
 }
 
-func NewPhotoHandler(photoService service.PhotoService) PhotoHandler {
+func NewPhotoHandler(photoService service.PhotoService, uploadMaxBytes int64) PhotoHandler {
 	return &photoHandlerImpl{
-		photoService: photoService,
+		photoService:   photoService,
+		uploadMaxBytes: uploadMaxBytes,
 	}
 }
 
+// GetPhotos godoc
+//
+//	@Summary		Show photos
+//	@Description	photos of every user the current user may see, newest first. sort=popular ranks the photos of the last days by like count instead
+//	@Tags			photos
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			sort	query		string	false	"newest or popular, default newest"
+//	@Param			tag		query		string	false	"only photos carrying the tag"
+//	@Param			cursor	query		string	false	"next_cursor of the previous page"
+//	@Param			limit	query		int		false	"page size, default 20, max 100"
+//	@Success		200		{object}	pkg.CursorPage[model.Photo]
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/photos [get]
 func (h *photoHandlerImpl) GetPhotos(ctx *gin.Context) {
-	photos, err := h.photoService.GetPhotos(ctx)
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	after, limit, ok := cursorParams(ctx)
+	if !ok {
+		return
+	}
+	tag := model.NormalizeTag(ctx.Query("tag"))
+	if tag != "" && !model.ValidTag(tag) {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid tag param")
+		return
+	}
+
+	var (
+		photos pkg.CursorPage[model.Photo]
+		err    error
+	)
+	switch ctx.Query("sort") {
+	case "", "newest":
+		photos, err = h.photoService.GetPhotos(ctx, userID, tag, after, limit)
+	case "popular":
+		photos, err = h.photoService.GetPopularPhotos(ctx, userID, tag, after, limit)
+	default:
+		pkg.WriteError(ctx, http.StatusBadRequest, "sort must be newest or popular")
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		pkg.WriteServerError(ctx, err, err.Error())
 		return
 	}
 	ctx.JSON(http.StatusOK, photos)
 }
 
-func (h *photoHandlerImpl) GetPhotoByID(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+// GetPhotosByUserID godoc
+//
+//	@Summary		Show photos of a user
+//	@Description	most recent photos posted by the user and visible to the current user, newest first
+//	@Tags			photos
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int		true	"User ID"
+//	@Param			cursor	query		string	false	"next_cursor of the previous page"
+//	@Param			limit	query		int		false	"page size, default 20, max 100"
+//	@Success		200		{object}	pkg.CursorPage[model.Photo]
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/{id}/photos [get]
+func (h *photoHandlerImpl) GetPhotosByUserID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	viewerID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	after, limit, ok := cursorParams(ctx)
+	if !ok {
+		return
+	}
+
+	photos, err := h.photoService.GetPhotosByUserID(ctx, viewerID, id, after, limit)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+		h.writePhotoError(ctx, err)
 		return
 	}
+	ctx.JSON(http.StatusOK, photos)
+}
 
-	photo, err := h.photoService.GetPhotoByID(ctx, id)
+// GetPhotoByID godoc
+//
+//	@Summary		Show a photo
+//	@Description	will return the photo with its like count and mentions, a private photo of another user is not found and a followers only one is forbidden. The owner may add the caption as posted, before the markup was stripped, with include=raw. It is returned as raw_caption
+//	@Tags			photos
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id				path	int		true	"Photo ID"
+//	@Param			include			query	string	false	"raw to add the caption as posted, owner only"
+//	@Param			If-None-Match	header	string	false	"ETag of a previous response"
+//	@Success		200	{object}	model.Photo
+//	@Success		304	"not modified"
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/photos/{id} [get]
+func (h *photoHandlerImpl) GetPhotoByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+		return
+	}
+	includeRaw := false
+	switch ctx.Query("include") {
+	case "":
+	case "raw":
+		includeRaw = true
+	default:
+		pkg.WriteError(ctx, http.StatusBadRequest, "include must be raw")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	photo, err := h.photoService.GetPhotoByID(ctx, userID, id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writePhotoError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, photo)
+	if includeRaw {
+		// the raw caption still holds the markup, only its owner gets it
+		if photo.UserID != userID {
+			pkg.WriteError(ctx, http.StatusForbidden, "only the owner can read the raw caption")
+			return
+		}
+		pkg.WriteJSONWithETag(ctx, http.StatusOK, photo.ToRawResponse())
+		return
+	}
+	pkg.WriteJSONWithETag(ctx, http.StatusOK, photo)
 }
 
+// UpdatePhoto godoc
+//
+//	@Summary		Update a photo
+//	@Description	will replace the title, caption and url of a photo owned by the current user
+//	@Tags			photos
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int				true	"Photo ID"
+//	@Param			request	body		model.PhotoPost	true	"photo fields"
+//	@Success		200		{object}	model.Photo
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/photos/{id} [put]
 func (h *photoHandlerImpl) UpdatePhoto(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
 		return
 	}
 
 	var updatedPhoto model.PhotoPost
-	if err := ctx.BindJSON(&updatedPhoto); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := ctx.ShouldBindJSON(&updatedPhoto); err != nil {
+		pkg.WriteBindError(ctx, err)
 		return
 	}
 
-	updatedPhotoResult, err := h.photoService.UpdatePhoto(ctx, id, updatedPhoto)
+	if err := updatedPhoto.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+
+	updatedPhotoResult, err := h.photoService.UpdatePhoto(ctx, userID, id, updatedPhoto)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writePhotoError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, updatedPhotoResult)
 }
 
-func (h *photoHandlerImpl) DeletePhotoByID(ctx *gin.Context) {
-	idStr := ctx.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo ID"})
+// DeletePhoto godoc
+//
+//	@Summary		Delete a photo
+//	@Description	will delete a photo owned by the current user
+//	@Tags			photos
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Photo ID"
+//	@Success		200	{object}	map[string]string
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/photos/{id} [delete]
+func (h *photoHandlerImpl) DeletePhoto(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
 		return
 	}
 
-	err = h.photoService.DeletePhotoByID(ctx, id)
+	err = h.photoService.DeletePhoto(ctx, userID, id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writePhotoError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Photo deleted successfully"})
 }
 
+// CreatePhoto godoc
+//
+//	@Summary		Create a photo
+//	@Description	verified accounts only, @username in the caption mentions that user. A retried request with the same Idempotency-Key replays the first response. 403 once the user reached the photo limit
+//	@Tags			photos
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Idempotency-Key	header		string			false	"unique key of this create"
+//	@Param			request			body		model.PhotoPost	true	"photo fields"
+//	@Success		201				{object}	model.Photo
+//	@Header			201				{string}	Location	"url of the new photo"
+//	@Failure		400				{object}	pkg.ErrorResponse
+//	@Failure		401				{object}	pkg.ErrorResponse
+//	@Failure		403				{object}	pkg.ErrorResponse
+//	@Failure		409				{object}	pkg.ErrorResponse
+//	@Failure		422				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/photos [post]
 func (h *photoHandlerImpl) CreatePhoto(ctx *gin.Context) {
 	var photo model.PhotoPost
-	if err := ctx.BindJSON(&photo); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := ctx.ShouldBindJSON(&photo); err != nil {
+		pkg.WriteBindError(ctx, err)
 		return
 	}
 
-	// Ambil userID dari JWT header
-	userID, exists := ctx.Get("user_id")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	if err := photo.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
 		return
 	}
 
-	// Konversi userID menjadi uint64
-	userIDUint64, ok := userID.(uint64)
+	// Ambil userID dari JWT header
+	userID, ok := sessionUserID(ctx)
 	if !ok {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID"})
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
 		return
 	}
 
-	createdPhoto, err := h.photoService.CreatePhoto(ctx, userIDUint64, photo)
+	createdPhoto, err := h.photoService.CreatePhoto(ctx, userID, photo)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writePhotoError(ctx, err)
 		return
 	}
+	pkg.SetLocation(ctx, "photos/"+strconv.FormatUint(createdPhoto.ID, 10))
 	ctx.JSON(http.StatusCreated, createdPhoto)
 }
+
+// LikePhoto godoc
+//
+//	@Summary		Like a photo
+//	@Description	liking a photo twice is not an error
+//	@Tags			photos
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path	int	true	"Photo ID"
+//	@Success		204
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/photos/{id}/like [post]
+func (h *photoHandlerImpl) LikePhoto(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	if err := h.photoService.LikePhoto(ctx, userID, id); err != nil {
+		h.writePhotoError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// UnlikePhoto godoc
+//
+//	@Summary		Unlike a photo
+//	@Description	removes the like of the current user, a photo that wasn't liked is not an error
+//	@Tags			photos
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path	int	true	"Photo ID"
+//	@Success		204
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/photos/{id}/like [delete]
+func (h *photoHandlerImpl) UnlikePhoto(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	if err := h.photoService.UnlikePhoto(ctx, userID, id); err != nil {
+		h.writePhotoError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetPhotoLikers godoc
+//
+//	@Summary		Show who liked a photo
+//	@Tags			photos
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Photo ID"
+//	@Success		200	{array}		model.PublicUser
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/photos/{id}/likes [get]
+func (h *photoHandlerImpl) GetPhotoLikers(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid photo id")
+		return
+	}
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	users, err := h.photoService.GetPhotoLikers(ctx, userID, id)
+	if err != nil {
+		h.writePhotoError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, model.ToPublicUsers(users))
+}
+
+// UploadPhotoImage godoc
+//
+//	@Summary		Upload a photo image
+//	@Description	verified accounts only, stores a jpeg or png and returns its url to send as photo_url when creating the photo
+//	@Tags			photos
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			image	formData	file	true	"jpeg or png image"
+//	@Success		201		{object}	map[string]string
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		413		{object}	pkg.ErrorResponse
+//	@Failure		415		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/photos/images [post]
+func (h *photoHandlerImpl) UploadPhotoImage(ctx *gin.Context) {
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	file, contentType, closeFile, ok := readUpload(ctx, "image", h.uploadMaxBytes)
+	if !ok {
+		return
+	}
+	defer closeFile()
+
+	url, err := h.photoService.UploadPhotoImage(ctx, userID, file, contentType)
+	if errors.Is(err, service.ErrUnsupportedImageType) {
+		writeServiceError(ctx, http.StatusUnsupportedMediaType, err)
+		return
+	}
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to save image")
+		return
+	}
+	pkg.SetLocation(ctx, url)
+	ctx.JSON(http.StatusCreated, gin.H{"url": url})
+}
+
+// GetPopularTags godoc
+//
+//	@Summary		Show popular tags
+//	@Description	tags carried by the most public photos, most used first
+//	@Tags			photos
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit	query		int	false	"number of tags, default 20, max 100"
+//	@Success		200		{array}		model.TagCount
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/tags/popular [get]
+func (h *photoHandlerImpl) GetPopularTags(ctx *gin.Context) {
+	limit, err := pkg.ParseLimit(ctx)
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tags, err := h.photoService.GetPopularTags(ctx, limit)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to get popular tags")
+		return
+	}
+	ctx.JSON(http.StatusOK, tags)
+}
+
+func (h *photoHandlerImpl) writePhotoError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPhotoNotFound), errors.Is(err, service.ErrUserNotFound):
+		writeServiceError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrPhotoNotOwner), errors.Is(err, service.ErrPhotoNotVisible), errors.Is(err, service.ErrPhotoLimitReached):
+		writeServiceError(ctx, http.StatusForbidden, err)
+	default:
+		pkg.WriteServerError(ctx, err, err.Error())
+	}
+}
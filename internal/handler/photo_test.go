@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/internal/middleware"
+	"go-mygram/internal/model"
+	"go-mygram/internal/service"
+	"go-mygram/internal/service/mocks"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeletePhoto(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success delete own photo", code: http.StatusOK},
+		{desc: "error photo of another user", svcErr: service.ErrPhotoNotOwner, code: http.StatusForbidden},
+		{desc: "error photo not found", svcErr: service.ErrPhotoNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodDelete, "/photos/3", nil)
+			g.Params = gin.Params{{Key: "id", Value: "3"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewPhotoService(t)
+			svcMock.On("DeletePhoto", g, uint64(7), uint64(3)).Return(tC.svcErr)
+
+			hdl := photoHandlerImpl{photoService: svcMock}
+			hdl.DeletePhoto(g)
+
+			assert.Equal(t, tC.code, rec.Result().StatusCode)
+		})
+	}
+
+	t.Run("error missing session", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodDelete, "/photos/3", nil)
+		g.Params = gin.Params{{Key: "id", Value: "3"}}
+
+		hdl := photoHandlerImpl{}
+		hdl.DeletePhoto(g)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Result().StatusCode)
+	})
+}
+
+func TestLikePhoto(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success like photo", code: http.StatusNoContent},
+		{desc: "error photo not found", svcErr: service.ErrPhotoNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/photos/3/like", nil)
+			g.Params = gin.Params{{Key: "id", Value: "3"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewPhotoService(t)
+			svcMock.On("LikePhoto", g, uint64(7), uint64(3)).Return(tC.svcErr)
+
+			hdl := photoHandlerImpl{photoService: svcMock}
+			hdl.LikePhoto(g)
+
+			assert.Equal(t, tC.code, g.Writer.Status())
+		})
+	}
+}
+
+func TestUnlikePhoto(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success unlike photo", code: http.StatusNoContent},
+		{desc: "error followers only photo of a user not followed", svcErr: service.ErrPhotoNotVisible, code: http.StatusForbidden},
+		{desc: "error private photo of another user", svcErr: service.ErrPhotoNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodDelete, "/photos/3/like", nil)
+			g.Params = gin.Params{{Key: "id", Value: "3"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewPhotoService(t)
+			svcMock.On("UnlikePhoto", g, uint64(7), uint64(3)).Return(tC.svcErr)
+
+			hdl := photoHandlerImpl{photoService: svcMock}
+			hdl.UnlikePhoto(g)
+
+			assert.Equal(t, tC.code, g.Writer.Status())
+		})
+	}
+}
+
+func TestGetPhotoByIDRaw(t *testing.T) {
+	photo := model.Photo{ID: 3, UserID: 7, Caption: "sunset", RawCaption: "<b>sunset</b>"}
+	testCases := []struct {
+		desc    string
+		query   string
+		ownerID uint64
+		code    int
+		raw     bool
+	}{
+		{desc: "success raw caption hidden by default", ownerID: 7, code: http.StatusOK},
+		{desc: "success owner asks for the raw caption", query: "?include=raw", ownerID: 7, code: http.StatusOK, raw: true},
+		{desc: "error raw caption of another user", query: "?include=raw", ownerID: 2, code: http.StatusForbidden},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/photos/3"+tC.query, nil)
+			g.Params = gin.Params{{Key: "id", Value: "3"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			owned := photo
+			owned.UserID = tC.ownerID
+			svcMock := mocks.NewPhotoService(t)
+			svcMock.On("GetPhotoByID", g, uint64(7), uint64(3)).Return(owned, nil)
+
+			hdl := photoHandlerImpl{photoService: svcMock}
+			hdl.GetPhotoByID(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+			if tC.code == http.StatusOK {
+				assert.Equal(t, tC.raw, bytes.Contains(rec.Body.Bytes(), []byte(`"raw_caption":"\u003cb\u003esunset\u003c/b\u003e"`)))
+			}
+		})
+	}
+
+	t.Run("error unknown include", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/photos/3?include=all", nil)
+		g.Params = gin.Params{{Key: "id", Value: "3"}}
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		hdl := photoHandlerImpl{photoService: mocks.NewPhotoService(t)}
+		hdl.GetPhotoByID(g)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestGetPhotoLikers(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success likers of a visible photo", code: http.StatusOK},
+		{desc: "error followers only photo of a user not followed", svcErr: service.ErrPhotoNotVisible, code: http.StatusForbidden},
+		{desc: "error private photo of another user", svcErr: service.ErrPhotoNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/photos/3/likes", nil)
+			g.Params = gin.Params{{Key: "id", Value: "3"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewPhotoService(t)
+			svcMock.On("GetPhotoLikers", g, uint64(7), uint64(3)).Return([]model.User{}, tC.svcErr)
+
+			hdl := photoHandlerImpl{photoService: svcMock}
+			hdl.GetPhotoLikers(g)
+
+			assert.Equal(t, tC.code, rec.Result().StatusCode)
+		})
+	}
+}
+
+func TestUploadPhotoImage(t *testing.T) {
+	jpeg := append([]byte{0xff, 0xd8, 0xff, 0xe0}, bytes.Repeat([]byte("x"), 100)...)
+
+	testCases := []struct {
+		desc    string
+		field   string
+		maxSize int64
+		svcErr  error
+		code    int
+	}{
+		{desc: "success upload jpeg", field: "image", maxSize: 1 << 20, code: http.StatusCreated},
+		{desc: "error file over the limit", field: "image", maxSize: 10, code: http.StatusRequestEntityTooLarge},
+		{desc: "error missing image field", field: "file", maxSize: 1 << 20, code: http.StatusBadRequest},
+		{desc: "error unsupported type", field: "image", maxSize: 1 << 20, svcErr: service.ErrUnsupportedImageType, code: http.StatusUnsupportedMediaType},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			var body bytes.Buffer
+			w := multipart.NewWriter(&body)
+			part, err := w.CreateFormFile(tC.field, "a.jpg")
+			assert.Nil(t, err)
+			_, _ = part.Write(jpeg)
+			assert.Nil(t, w.Close())
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/photos/images", &body)
+			g.Request.Header.Set("Content-Type", w.FormDataContentType())
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewPhotoService(t)
+			if tC.code == http.StatusCreated || tC.svcErr != nil {
+				svcMock.On("UploadPhotoImage", g, uint64(7), mock.Anything, "image/jpeg").
+					Return("/uploads/photos/7-a.jpg", tC.svcErr)
+			}
+
+			hdl := photoHandlerImpl{photoService: svcMock, uploadMaxBytes: tC.maxSize}
+			hdl.UploadPhotoImage(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestGetPhotosSort(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		sort   string
+		method string
+		code   int
+	}{
+		{desc: "success default newest", method: "GetPhotos", code: http.StatusOK},
+		{desc: "success newest", sort: "newest", method: "GetPhotos", code: http.StatusOK},
+		{desc: "success popular", sort: "popular", method: "GetPopularPhotos", code: http.StatusOK},
+		{desc: "error unknown sort", sort: "oldest", code: http.StatusBadRequest},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/photos?sort="+tC.sort, nil)
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewPhotoService(t)
+			if tC.method != "" {
+				svcMock.On(tC.method, g, uint64(7), "", (*pkg.Cursor)(nil), 20).Return(pkg.CursorPage[model.Photo]{Data: []model.Photo{}}, nil)
+			}
+
+			hdl := photoHandlerImpl{photoService: svcMock}
+			hdl.GetPhotos(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestGetPhotosTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success normalized tag", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/photos?tag=%23Sunset", nil)
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		svcMock := mocks.NewPhotoService(t)
+		svcMock.On("GetPhotos", g, uint64(7), "sunset", (*pkg.Cursor)(nil), 20).Return(pkg.CursorPage[model.Photo]{Data: []model.Photo{}}, nil)
+
+		hdl := photoHandlerImpl{photoService: svcMock}
+		hdl.GetPhotos(g)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("error invalid tag", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/photos?tag=sun-set", nil)
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		hdl := photoHandlerImpl{}
+		hdl.GetPhotos(g)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestGetPopularTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	g, _ := gin.CreateTestContext(rec)
+	g.Request = httptest.NewRequest(http.MethodGet, "/tags/popular?limit=5", nil)
+
+	svcMock := mocks.NewPhotoService(t)
+	svcMock.On("GetPopularTags", g, 5).Return([]model.TagCount{{Name: "sunset", PhotoCount: 4}}, nil)
+
+	hdl := photoHandlerImpl{photoService: svcMock}
+	hdl.GetPopularTags(g)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"name":"sunset","photo_count":4}]`, rec.Body.String())
+}
+
+func TestGetPhotosByUserID(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		page   pkg.CursorPage[model.Photo]
+		svcErr error
+		code   int
+		body   string
+	}{
+		{desc: "success user with photos", page: pkg.CursorPage[model.Photo]{Data: []model.Photo{{ID: 1, LikeCount: 2}}}, code: http.StatusOK, body: `"like_count":2`},
+		{desc: "success user without photos", page: pkg.CursorPage[model.Photo]{Data: []model.Photo{}}, code: http.StatusOK, body: `"data":[]`},
+		{desc: "error user not found", svcErr: service.ErrUserNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/users/4/photos", nil)
+			g.Params = gin.Params{{Key: "id", Value: "4"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewPhotoService(t)
+			svcMock.On("GetPhotosByUserID", g, uint64(7), uint64(4), (*pkg.Cursor)(nil), 20).Return(tC.page, tC.svcErr)
+
+			hdl := photoHandlerImpl{photoService: svcMock}
+			hdl.GetPhotosByUserID(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+			assert.Contains(t, rec.Body.String(), tC.body)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queryTime reads an optional RFC3339 timestamp from the query, nil when
+// the param is empty
+func queryTime(ctx *gin.Context, key string) (*time.Time, error) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", key)
+	}
+	return &t, nil
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/service"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler interface {
+	ReportPhoto(ctx *gin.Context)
+	ReportComment(ctx *gin.Context)
+	GetReports(ctx *gin.Context)
+}
+
+type reportHandlerImpl struct {
+	reportService service.ReportService
+}
+
+func NewReportHandler(reportService service.ReportService) ReportHandler {
+	return &reportHandlerImpl{reportService: reportService}
+}
+
+// ReportPhoto godoc
+//
+//	@Summary		Report a photo
+//	@Description	reporting a photo twice is not an error, the first reason is kept
+//	@Tags			reports
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path	int					true	"Photo ID"
+//	@Param			report	body	model.ReportPost	true	"Report"
+//	@Success		204
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/photos/{id}/report [post]
+func (h *reportHandlerImpl) ReportPhoto(ctx *gin.Context) {
+	id, reporterID, report, ok := reportParams(ctx, "invalid photo id")
+	if !ok {
+		return
+	}
+	if err := h.reportService.ReportPhoto(ctx, reporterID, id, report); err != nil {
+		h.writeReportError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ReportComment godoc
+//
+//	@Summary		Report a comment
+//	@Description	reporting a comment twice is not an error, the first reason is kept
+//	@Tags			reports
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path	int					true	"Comment ID"
+//	@Param			report	body	model.ReportPost	true	"Report"
+//	@Success		204
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/comments/{id}/report [post]
+func (h *reportHandlerImpl) ReportComment(ctx *gin.Context) {
+	id, reporterID, report, ok := reportParams(ctx, "invalid comment id")
+	if !ok {
+		return
+	}
+	if err := h.reportService.ReportComment(ctx, reporterID, id, report); err != nil {
+		h.writeReportError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetReports godoc
+//
+//	@Summary		Show reported content
+//	@Description	admin only, photos and comments with their reports, the most reported first
+//	@Tags			reports
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			page	query		int	false	"page number, default 1"
+//	@Param			offset	query		int	false	"items to skip, instead of page"
+//	@Param			limit	query		int	false	"page size, default 20, max 100"
+//	@Success		200		{object}	pkg.SuccessResponse{data=pkg.Paginated[model.ReportedContent]}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/reports [get]
+func (h *reportHandlerImpl) GetReports(ctx *gin.Context) {
+	pagination, err := pkg.ParsePagination(ctx)
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, total, err := h.reportService.GetReportedContent(ctx, pagination)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, err.Error())
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, pkg.NewPaginated(items, pagination.Page(), pagination.Limit(), total))
+}
+
+// reportParams reads the reported item from the path, the reporter from
+// the session and the report from the body, on failure it writes the
+// response and returns false
+func reportParams(ctx *gin.Context, invalidID string) (uint64, uint64, model.ReportPost, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, invalidID)
+		return 0, 0, model.ReportPost{}, false
+	}
+	reporterID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return 0, 0, model.ReportPost{}, false
+	}
+	var report model.ReportPost
+	if err := ctx.ShouldBindJSON(&report); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return 0, 0, model.ReportPost{}, false
+	}
+	if err := report.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return 0, 0, model.ReportPost{}, false
+	}
+	return id, reporterID, report, true
+}
+
+func (h *reportHandlerImpl) writeReportError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPhotoNotFound), errors.Is(err, service.ErrCommentNotFound):
+		writeServiceError(ctx, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrPhotoNotVisible):
+		writeServiceError(ctx, http.StatusForbidden, err)
+	default:
+		pkg.WriteServerError(ctx, err, err.Error())
+	}
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mygram/internal/middleware"
+	"go-mygram/internal/model"
+	"go-mygram/internal/service"
+	"go-mygram/internal/service/mocks"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportPhoto(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		param  string
+		body   string
+		id     uint64
+		err    error
+		mock   bool
+		status int
+	}{
+		{desc: "error invalid photo id", param: "abc", body: `{"reason":"spam"}`, status: http.StatusBadRequest},
+		{desc: "error missing reason", param: "3", body: `{}`, status: http.StatusBadRequest},
+		{desc: "error blank reason", param: "3", body: `{"reason":"   "}`, status: http.StatusBadRequest},
+		{desc: "error photo not found", param: "3", body: `{"reason":"spam"}`, id: 3, err: service.ErrPhotoNotFound, mock: true, status: http.StatusNotFound},
+		{desc: "error photo not visible", param: "3", body: `{"reason":"spam"}`, id: 3, err: service.ErrPhotoNotVisible, mock: true, status: http.StatusForbidden},
+		{desc: "success report photo", param: "3", body: `{"reason":"spam"}`, id: 3, mock: true, status: http.StatusNoContent},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/photos/"+tC.param+"/report", strings.NewReader(tC.body))
+			g.Request.Header.Set("Content-Type", "application/json")
+			g.Params = gin.Params{{Key: "id", Value: tC.param}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewReportService(t)
+			if tC.mock {
+				svcMock.On("ReportPhoto", g, uint64(7), tC.id, model.ReportPost{Reason: "spam"}).Return(tC.err)
+			}
+
+			hdl := reportHandlerImpl{reportService: svcMock}
+			hdl.ReportPhoto(g)
+			g.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tC.status, rec.Code)
+		})
+	}
+}
+
+func TestReportComment(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		err    error
+		status int
+	}{
+		{desc: "error comment not found", err: service.ErrCommentNotFound, status: http.StatusNotFound},
+		{desc: "success report comment", status: http.StatusNoContent},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/comments/4/report", strings.NewReader(`{"reason":"rude"}`))
+			g.Request.Header.Set("Content-Type", "application/json")
+			g.Params = gin.Params{{Key: "id", Value: "4"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewReportService(t)
+			svcMock.On("ReportComment", g, uint64(7), uint64(4), model.ReportPost{Reason: "rude"}).Return(tC.err)
+
+			hdl := reportHandlerImpl{reportService: svcMock}
+			hdl.ReportComment(g)
+			g.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tC.status, rec.Code)
+		})
+	}
+}
+
+func TestGetReports(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	g, _ := gin.CreateTestContext(rec)
+	g.Request = httptest.NewRequest(http.MethodGet, "/reports?page=2&limit=1", nil)
+
+	svcMock := mocks.NewReportService(t)
+	svcMock.On("GetReportedContent", g, pkg.NewPagination(2, 1)).
+		Return([]model.ReportedContent{{TargetType: model.ReportTargetPhoto, TargetID: 3, ReportCount: 2, LatestReason: "spam"}}, int64(2), nil)
+
+	hdl := reportHandlerImpl{reportService: svcMock}
+	hdl.GetReports(g)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"target_type":"photo","target_id":3,"report_count":2`)
+	assert.Contains(t, rec.Body.String(), `"total":2`)
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"go-mygram/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionUserID returns the user id claim set by the auth middleware,
+// json numbers in the token claim are decoded as float64
+func sessionUserID(ctx *gin.Context) (uint64, bool) {
+	userId, ok := ctx.Get(middleware.CLAIM_USER_ID)
+	if !ok {
+		return 0, false
+	}
+	userIdFloat, ok := userId.(float64)
+	if !ok || userIdFloat <= 0 {
+		return 0, false
+	}
+	return uint64(userIdFloat), true
+}
@@ -1,20 +1,23 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"go-mygram/internal/model"
 	"go-mygram/internal/service"
+	"go-mygram/pkg"
 
 	"github.com/gin-gonic/gin"
 )
 
 type SocialMediaHandler interface {
 	CreateSocialMedia(c *gin.Context)
+	GetSocialMedias(c *gin.Context)
 	GetSocialMediaByID(c *gin.Context)
 	UpdateSocialMedia(c *gin.Context)
-	DeleteSocialMediaByID(c *gin.Context)
+	DeleteSocialMedia(c *gin.Context)
 }
 
 type socialMediaHandlerImpl struct {
@@ -27,86 +30,220 @@ func NewSocialMediaHandler(socialMediaService service.SocialMediaService) Social
 	}
 }
 
+// CreateSocialMedia godoc
+//
+//	@Summary		Create a social media
+//	@Description	will link a social media profile to the current user, the url is normalized
+//	@Tags			socialmedias
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		model.SocialMediaPost	true	"name and url"
+//	@Success		201		{object}	model.SocialMedia
+//	@Header			201		{string}	Location	"url of the new social media"
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/socialmedias [post]
 func (h *socialMediaHandlerImpl) CreateSocialMedia(c *gin.Context) {
 	var socialMediaPost model.SocialMediaPost
 	if err := c.ShouldBindJSON(&socialMediaPost); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		pkg.WriteBindError(c, err)
 		return
 	}
 
-	// Ambil userID dari JWT header
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	if err := socialMediaPost.Validate(); err != nil {
+		pkg.WriteValidationError(c, err)
 		return
 	}
 
-	// Konversi userID menjadi uint64
-	userIDUint64, ok := userID.(uint64)
+	// Ambil userID dari JWT header
+	userID, ok := sessionUserID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID"})
+		pkg.WriteError(c, http.StatusUnauthorized, "invalid user session")
 		return
 	}
 
-	socialMedia, err := h.socialMediaService.CreateSocialMedia(c.Request.Context(), userIDUint64, socialMediaPost)
+	socialMedia, err := h.socialMediaService.CreateSocialMedia(c.Request.Context(), userID, socialMediaPost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create social media"})
+		pkg.WriteServerError(c, err, "Failed to create social media")
 		return
 	}
 
+	pkg.SetLocation(c, "socialmedias/"+strconv.FormatUint(socialMedia.ID, 10))
 	c.JSON(http.StatusCreated, socialMedia)
 }
 
+// GetSocialMedias godoc
+//
+//	@Summary		Show social medias
+//	@Description	social medias of the current user, or of user_id when given
+//	@Tags			socialmedias
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			user_id	query		int		false	"owner, default the current user"
+//	@Param			include	query		string	false	"user to embed the owner"
+//	@Success		200		{array}		model.SocialMedia
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/socialmedias [get]
+func (h *socialMediaHandlerImpl) GetSocialMedias(c *gin.Context) {
+	userID, ok := sessionUserID(c)
+	if !ok {
+		pkg.WriteError(c, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		id, err := strconv.ParseUint(userIDStr, 10, 64)
+		if id == 0 || err != nil {
+			pkg.WriteError(c, http.StatusBadRequest, "invalid user id")
+			return
+		}
+		userID = id
+	}
+	includeUser := false
+	switch c.Query("include") {
+	case "":
+	case "user":
+		includeUser = true
+	default:
+		pkg.WriteError(c, http.StatusBadRequest, "include must be user")
+		return
+	}
+
+	socialMedias, err := h.socialMediaService.GetSocialMedias(c.Request.Context(), userID, includeUser)
+	if err != nil {
+		pkg.WriteServerError(c, err, "Failed to get social medias")
+		return
+	}
+
+	c.JSON(http.StatusOK, socialMedias)
+}
+
+// GetSocialMediaByID godoc
+//
+//	@Summary		Show a social media
+//	@Tags			socialmedias
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Social media ID"
+//	@Success		200	{object}	model.SocialMedia
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/socialmedias/{id} [get]
 func (h *socialMediaHandlerImpl) GetSocialMediaByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid social media ID"})
+		pkg.WriteError(c, http.StatusBadRequest, "Invalid social media ID")
 		return
 	}
 
 	socialMedia, err := h.socialMediaService.GetSocialMediaByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Social media not found"})
+		h.writeSocialMediaError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, socialMedia)
 }
 
+// UpdateSocialMedia godoc
+//
+//	@Summary		Update a social media
+//	@Description	will replace the name and url of a social media owned by the current user
+//	@Tags			socialmedias
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"Social media ID"
+//	@Param			request	body		model.SocialMediaPost	true	"name and url"
+//	@Success		200		{object}	model.SocialMedia
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/socialmedias/{id} [put]
 func (h *socialMediaHandlerImpl) UpdateSocialMedia(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid social media ID"})
+		pkg.WriteError(c, http.StatusBadRequest, "Invalid social media ID")
+		return
+	}
+
+	userID, ok := sessionUserID(c)
+	if !ok {
+		pkg.WriteError(c, http.StatusUnauthorized, "invalid user session")
 		return
 	}
 
 	var socialMediaPost model.SocialMediaPost
 	if err := c.ShouldBindJSON(&socialMediaPost); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		pkg.WriteBindError(c, err)
+		return
+	}
+
+	if err := socialMediaPost.Validate(); err != nil {
+		pkg.WriteValidationError(c, err)
 		return
 	}
 
-	updatedSocialMedia, err := h.socialMediaService.UpdateSocialMedia(c.Request.Context(), id, socialMediaPost)
+	updatedSocialMedia, err := h.socialMediaService.UpdateSocialMedia(c.Request.Context(), userID, id, socialMediaPost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update social media"})
+		h.writeSocialMediaError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, updatedSocialMedia)
 }
 
-func (h *socialMediaHandlerImpl) DeleteSocialMediaByID(c *gin.Context) {
+// DeleteSocialMedia godoc
+//
+//	@Summary		Delete a social media
+//	@Description	will delete a social media owned by the current user
+//	@Tags			socialmedias
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Social media ID"
+//	@Success		200	{object}	map[string]string
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/socialmedias/{id} [delete]
+func (h *socialMediaHandlerImpl) DeleteSocialMedia(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid social media ID"})
+		pkg.WriteError(c, http.StatusBadRequest, "Invalid social media ID")
 		return
 	}
 
-	err = h.socialMediaService.DeleteSocialMediaByID(c.Request.Context(), id)
+	userID, ok := sessionUserID(c)
+	if !ok {
+		pkg.WriteError(c, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	err = h.socialMediaService.DeleteSocialMedia(c.Request.Context(), userID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete social media"})
+		h.writeSocialMediaError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, "You social media has been successfully deleted")
+	c.JSON(http.StatusOK, gin.H{"message": "Social media deleted successfully"})
+}
+
+func (h *socialMediaHandlerImpl) writeSocialMediaError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSocialMediaNotFound):
+		writeServiceError(c, http.StatusNotFound, err)
+	case errors.Is(err, service.ErrSocialMediaNotOwner):
+		writeServiceError(c, http.StatusForbidden, err)
+	default:
+		pkg.WriteServerError(c, err, err.Error())
+	}
 }
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/internal/middleware"
+	"go-mygram/internal/model"
+	"go-mygram/internal/service"
+	"go-mygram/internal/service/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSocialMedias(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		query       string
+		includeUser bool
+		code        int
+	}{
+		{desc: "success without include", query: "", code: http.StatusOK},
+		{desc: "success include user", query: "?include=user", includeUser: true, code: http.StatusOK},
+		{desc: "error unknown include", query: "?include=photos", code: http.StatusBadRequest},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/socialmedias"+tC.query, nil)
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewSocialMediaService(t)
+			if tC.code == http.StatusOK {
+				svcMock.On("GetSocialMedias", g.Request.Context(), uint64(7), tC.includeUser).Return([]model.SocialMedia{{ID: 1, UserID: 7}}, nil)
+			}
+
+			hdl := socialMediaHandlerImpl{socialMediaService: svcMock}
+			hdl.GetSocialMedias(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestDeleteSocialMedia(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success delete own social media", code: http.StatusOK},
+		{desc: "error social media of another user", svcErr: service.ErrSocialMediaNotOwner, code: http.StatusForbidden},
+		{desc: "error social media not found", svcErr: service.ErrSocialMediaNotFound, code: http.StatusNotFound},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodDelete, "/socialmedias/3", nil)
+			g.Params = gin.Params{{Key: "id", Value: "3"}}
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewSocialMediaService(t)
+			svcMock.On("DeleteSocialMedia", g.Request.Context(), uint64(7), uint64(3)).Return(tC.svcErr)
+
+			hdl := socialMediaHandlerImpl{socialMediaService: svcMock}
+			hdl.DeleteSocialMedia(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+			if tC.code == http.StatusOK {
+				assert.JSONEq(t, `{"message":"Social media deleted successfully"}`, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead leaves room for the boundaries and part headers around
+// the uploaded file
+const multipartOverhead = 64 << 10
+
+// UploadBodyLimit is the request body size to allow on a route taking a file
+// of up to maxFileBytes
+func UploadBodyLimit(maxFileBytes int64) int64 {
+	return maxFileBytes + multipartOverhead
+}
+
+// readUpload opens the multipart file in field and sniffs its content type
+// from the first bytes, the type sent by the client can't be trusted. On
+// failure it writes the response and returns false, otherwise the caller
+// must call close
+func readUpload(ctx *gin.Context, field string, maxBytes int64) (file io.Reader, contentType string, close func(), ok bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, UploadBodyLimit(maxBytes))
+	fileHeader, err := ctx.FormFile(field)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			pkg.WriteError(ctx, http.StatusRequestEntityTooLarge, field+" is too large")
+			return nil, "", nil, false
+		}
+		pkg.WriteError(ctx, http.StatusBadRequest, field+" file is required")
+		return nil, "", nil, false
+	}
+	if fileHeader.Size > maxBytes {
+		pkg.WriteError(ctx, http.StatusRequestEntityTooLarge, field+" is too large")
+		return nil, "", nil, false
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to read "+field)
+		return nil, "", nil, false
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		f.Close()
+		pkg.WriteError(ctx, http.StatusBadRequest, field+" file is empty")
+		return nil, "", nil, false
+	}
+	return io.MultiReader(bytes.NewReader(head[:n]), f), http.DetectContentType(head[:n]), func() { f.Close() }, true
+}
@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"go-mygram/internal/middleware"
 	"go-mygram/internal/model"
 	"go-mygram/internal/service"
 	"go-mygram/pkg"
+	"go-mygram/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,198 +19,832 @@ import (
 type UserHandler interface {
 	// users
 	GetUsers(ctx *gin.Context)
+	GetUserCount(ctx *gin.Context)
 	GetUsersById(ctx *gin.Context)
+	GetUsersBatch(ctx *gin.Context)
+	GetCurrentUser(ctx *gin.Context)
 	UpdateUserByID(ctx *gin.Context)
+	ReplaceCurrentUser(ctx *gin.Context)
 	DeleteUsersById(ctx *gin.Context)
+	DeleteCurrentUser(ctx *gin.Context)
+	HardDeleteUser(ctx *gin.Context)
+	UsernameAvailable(ctx *gin.Context)
+	SearchUsers(ctx *gin.Context)
+	UploadAvatar(ctx *gin.Context)
 
 	// activity
 	UserSignUp(ctx *gin.Context)
 	UserSignIn(ctx *gin.Context)
+	RefreshToken(ctx *gin.Context)
+	UserSignOut(ctx *gin.Context)
+	VerifyEmail(ctx *gin.Context)
+	ForgotPassword(ctx *gin.Context)
+	ResetPassword(ctx *gin.Context)
+	ChangePassword(ctx *gin.Context)
 }
 
 type userHandlerImpl struct {
-	svc service.UserService
+	svc            service.UserService
+	avatarMaxBytes int64
 }
 
-func NewUserHandler(svc service.UserService) UserHandler {
+// the keys ?fields may select on the user list and detail
+var (
+	adminUserFields = pkg.JSONFields(model.AdminUserResponse{})
+	userFields      = pkg.JSONFields(model.UserProfileResponse{})
+)
+
+func NewUserHandler(svc service.UserService, avatarMaxBytes int64) UserHandler {
 	return &userHandlerImpl{
-		svc: svc,
+		svc:            svc,
+		avatarMaxBytes: avatarMaxBytes,
 	}
 }
 
 // ShowUsers godoc
 //
 //	@Summary		Show users list
-//	@Description	will fetch 3rd party server to get users data
+//	@Description	admin only, will fetch 3rd party server to get users data
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{object}	[]model.User
-//	@Failure		400	{object}	pkg.ErrorResponse
-//	@Failure		404	{object}	pkg.ErrorResponse
-//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Security		BearerAuth
+//	@Param			page	query		int	false	"page number, default 1"
+//	@Param			offset	query		int	false	"items to skip, instead of page"
+//	@Param			limit	query		int	false	"page size, default 20, max 100"
+//	@Param			sort_by	query		string	false	"created_at, username or id, default created_at"
+//	@Param			order	query		string	false	"asc or desc, default desc"
+//	@Param			search	query		string	false	"case-insensitive match on username or email"
+//	@Param			created_after	query	string	false	"RFC3339, only users registered after it"
+//	@Param			created_before	query	string	false	"RFC3339, only users registered before it"
+//	@Param			fields	query		string	false	"comma separated keys to return, default all"
+//	@Success		200		{object}	pkg.SuccessResponse{data=pkg.Paginated[model.AdminUserResponse]}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		403		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
 //	@Router			/users [get]
 func (u *userHandlerImpl) GetUsers(ctx *gin.Context) {
-	users, err := u.svc.GetUsers(ctx)
+	pagination, err := pkg.ParsePagination(ctx)
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	createdAfter, err := queryTime(ctx, "created_after")
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	createdBefore, err := queryTime(ctx, "created_before")
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := model.UserListParams{
+		Pagination:    pagination,
+		SortBy:        ctx.DefaultQuery("sort_by", model.DefaultUserSortBy),
+		Order:         strings.ToLower(ctx.DefaultQuery("order", model.SortDesc)),
+		Search:        ctx.Query("search"),
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+	}
+	if err := params.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+	fields, err := pkg.ParseFields(ctx, adminUserFields)
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, total, err := u.svc.GetUsers(ctx, params)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, err.Error())
+		return
+	}
+	res := model.ToAdminUserResponses(users)
+	if fields == nil {
+		pkg.WriteSuccess(ctx, http.StatusOK, pkg.NewPaginated(res, pagination.Page(), pagination.Limit(), total))
+		return
+	}
+	selected, err := pkg.SelectFieldsAll(res, fields)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to encode response")
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, pkg.NewPaginated(selected, pagination.Page(), pagination.Limit(), total))
+}
+
+// GetUserCount godoc
+//
+//	@Summary		Count users
+//	@Description	admin only, the total may be up to 30 seconds old
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			search			query		string	false	"case-insensitive match on username or email"
+//	@Param			created_after	query		string	false	"RFC3339, only users registered after it"
+//	@Param			created_before	query		string	false	"RFC3339, only users registered before it"
+//	@Success		200				{object}	pkg.SuccessResponse{data=model.UserCount}
+//	@Failure		400				{object}	pkg.ErrorResponse
+//	@Failure		401				{object}	pkg.ErrorResponse
+//	@Failure		403				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/users/count [get]
+func (u *userHandlerImpl) GetUserCount(ctx *gin.Context) {
+	createdAfter, err := queryTime(ctx, "created_after")
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
-	ctx.JSON(http.StatusOK, users)
+	createdBefore, err := queryTime(ctx, "created_before")
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params := model.UserCountParams{
+		Search:        ctx.Query("search"),
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+	}
+	if err := params.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+
+	count, err := u.svc.GetUserCount(ctx, params)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, err.Error())
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, model.UserCount{Count: count})
 }
 
 // ShowUsersById godoc
 //
 //	@Summary		Show users detail
-//	@Description	will fetch 3rd party server to get users data to get detail user
+//	@Description	will look the user up by id, or by username when the param is not a known id
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Param			id	path		int	true	"User ID"
-//	@Success		200	{object}	model.User
+//	@Security		BearerAuth
+//	@Param			id				path	string	true	"User ID or username"
+//	@Param			fields			query	string	false	"comma separated keys to return, default all"
+//	@Param			include			query	string	false	"stats to add the photo, comment and follow counts"
+//	@Param			If-None-Match	header	string	false	"ETag of a previous response"
+//	@Success		200	{object}	pkg.SuccessResponse{data=model.UserProfileResponse}
+//	@Success		304	"not modified"
 //	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
 //	@Failure		404	{object}	pkg.ErrorResponse
 //	@Failure		500	{object}	pkg.ErrorResponse
 //	@Router			/users/{id} [get]
 func (u *userHandlerImpl) GetUsersById(ctx *gin.Context) {
-	// get id user
-	id, err := strconv.Atoi(ctx.Param("id"))
-	if id == 0 || err != nil {
-		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+	fields, err := pkg.ParseFields(ctx, userFields)
+	if err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeStats := false
+	switch ctx.Query("include") {
+	case "":
+	case "stats":
+		includeStats = true
+	default:
+		pkg.WriteError(ctx, http.StatusBadRequest, "include must be stats")
 		return
 	}
-	user, err := u.svc.GetUsersById(ctx, uint64(id))
+
+	user, err := u.svc.GetUserByIDOrUsername(ctx, ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		writeError(ctx, err, err.Error())
+		return
+	}
+	res := model.UserProfileResponse{UserResponse: user.ToResponse()}
+	if includeStats {
+		viewerID, ok := sessionUserID(ctx)
+		if !ok {
+			pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+			return
+		}
+		stats, err := u.svc.GetUserStats(ctx, viewerID, user.ID)
+		if err != nil {
+			writeError(ctx, err, "failed to count user stats")
+			return
+		}
+		res.Stats = &stats
+	}
+	if fields == nil {
+		pkg.WriteSuccessWithETag(ctx, http.StatusOK, res)
 		return
 	}
-	if user.ID == 0 {
-		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "user not found"})
+	selected, err := pkg.SelectFields(res, fields)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to encode response")
 		return
 	}
-	ctx.JSON(http.StatusOK, user)
+	pkg.WriteSuccessWithETag(ctx, http.StatusOK, selected)
 }
 
-func (u *userHandlerImpl) UserSignUp(ctx *gin.Context) {
-	// binding sign-up body
-	userSignUp := model.UserSignUp{}
-	if err := ctx.Bind(&userSignUp); err != nil {
-		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+// GetUsersBatch godoc
+//
+//	@Summary		Show several users
+//	@Description	will fetch the users with the given ids in one call, unknown ids are left out
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		model.UserBatchRequest	true	"user ids, at most 100"
+//	@Success		200		{object}	pkg.SuccessResponse{data=[]model.UserResponse}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/batch [post]
+func (u *userHandlerImpl) GetUsersBatch(ctx *gin.Context) {
+	var req model.UserBatchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
 		return
 	}
 
-	if err := userSignUp.Validate(); err != nil {
-		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+	users, err := u.svc.GetUsersByIDs(ctx, req.IDs)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, "failed to get users")
 		return
 	}
+	pkg.WriteSuccess(ctx, http.StatusOK, model.ToUserResponses(users))
+}
 
-	user, err := u.svc.SignUp(ctx, userSignUp)
+// GetCurrentUser godoc
+//
+//	@Summary		Show current user
+//	@Description	will return the profile of the user owning the bearer token
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200				{object}	pkg.SuccessResponse{data=model.UserResponse}
+//	@Failure		401				{object}	pkg.ErrorResponse
+//	@Failure		404				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/users/me [get]
+func (u *userHandlerImpl) GetCurrentUser(ctx *gin.Context) {
+	userId, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+	user, err := u.svc.GetUsersById(ctx, userId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		writeError(ctx, err, err.Error())
 		return
 	}
+	pkg.WriteSuccess(ctx, http.StatusOK, user.ToResponse())
+}
 
-	ctx.JSON(http.StatusCreated, user)
+// UploadAvatar godoc
+//
+//	@Summary		Upload avatar
+//	@Description	replaces the profile picture of the current user, jpeg or png only
+//	@Tags			users
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			avatar			formData	file	true	"jpeg or png image"
+//	@Success		200				{object}	pkg.SuccessResponse{data=model.UserResponse}
+//	@Failure		400				{object}	pkg.ErrorResponse
+//	@Failure		401				{object}	pkg.ErrorResponse
+//	@Failure		404				{object}	pkg.ErrorResponse
+//	@Failure		413				{object}	pkg.ErrorResponse
+//	@Failure		415				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/users/me/avatar [post]
+func (u *userHandlerImpl) UploadAvatar(ctx *gin.Context) {
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+
+	file, contentType, closeFile, ok := readUpload(ctx, "avatar", u.avatarMaxBytes)
+	if !ok {
+		return
+	}
+	defer closeFile()
+
+	user, err := u.svc.UpdateAvatar(ctx, userID, file, contentType)
+	if errors.Is(err, service.ErrUnsupportedImageType) {
+		writeServiceError(ctx, http.StatusUnsupportedMediaType, err)
+		return
+	}
+	if err != nil {
+		writeError(ctx, err, "failed to save avatar")
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, user.ToResponse())
 }
 
+// UserSignUp godoc
+//
+//	@Summary		Sign up
+//	@Description	will create the account, sign it in and mail the email verification link. Weak but accepted passwords and likely email typos come back as warnings
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.UserSignUp	true	"account details"
+//	@Success		201		{object}	pkg.SuccessResponse{data=model.SignUpResponse}
+//	@Header			201		{string}	Location	"url of the new user"
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		409		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/register [post]
+func (u *userHandlerImpl) UserSignUp(ctx *gin.Context) {
+	// the route already checked the binding tags
+	userSignUp := model.UserSignUp{}
+	if !pkg.BindAndValidate(ctx, &userSignUp) {
+		return
+	}
+
+	user, tokens, err := u.svc.SignUpWithTokens(ctx, userSignUp)
+	if err != nil {
+		writeError(ctx, err, "failed to sign up")
+		return
+	}
+
+	// resolved against /users/register, this is /users/{id}
+	pkg.SetLocation(ctx, strconv.FormatUint(user.ID, 10))
+	pkg.WriteSuccessWithWarnings(ctx, http.StatusCreated, model.SignUpResponse{
+		UserResponse: user.ToResponse(),
+		TokenPair:    tokens,
+	}, userSignUp.Warnings())
+}
+
+// UserSignIn godoc
+//
+//	@Summary		Sign in
+//	@Description	will exchange email and password for an access and a refresh token, expires_in and expires_at tell when the access token runs out
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.UserSignIn	true	"email and password"
+//	@Success		200		{object}	pkg.SuccessResponse{data=model.TokenPair}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		423		{object}	pkg.ErrorResponse
+//	@Failure		429		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/login [post]
 func (u *userHandlerImpl) UserSignIn(ctx *gin.Context) {
 	var signInReq model.UserSignIn
-	if err := ctx.BindJSON(&signInReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+	if !pkg.BindAndValidate(ctx, &signInReq) {
 		return
 	}
 
 	user, err := u.svc.SignIn(ctx, signInReq)
+	if errors.Is(err, service.ErrAccountLocked) {
+		writeServiceError(ctx, http.StatusLocked, err)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		metrics.FailedLogins.Inc()
+	}
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, pkg.ErrorResponse{Message: err.Error()})
+		writeError(ctx, err, "failed to sign in")
 		return
 	}
 
-	token, err := u.svc.GenerateUserAccessToken(ctx, user)
+	accessToken, err := u.svc.GenerateUserAccessToken(ctx, user)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		pkg.WriteServerError(ctx, err, err.Error())
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"token": token})
+	refreshToken, err := u.svc.GenerateRefreshToken(ctx, user)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, err.Error())
+		return
+	}
 
+	pkg.WriteSuccess(ctx, http.StatusOK, model.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	})
 }
 
-func (u *userHandlerImpl) UpdateUserByID(ctx *gin.Context) {
-	userId, ok := ctx.Get(middleware.CLAIM_USER_ID)
+// RefreshToken godoc
+//
+//	@Summary		Refresh access token
+//	@Description	will issue a new access token from a valid refresh token
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.RefreshTokenRequest	true	"refresh token"
+//	@Success		200		{object}	pkg.SuccessResponse{data=model.AccessToken}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/refresh [post]
+func (u *userHandlerImpl) RefreshToken(ctx *gin.Context) {
+	var refreshReq model.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&refreshReq); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return
+	}
+
+	accessToken, err := u.svc.RefreshAccessToken(ctx, refreshReq.RefreshToken)
+	if err != nil {
+		writeError(ctx, err, err.Error())
+		return
+	}
+
+	pkg.WriteSuccess(ctx, http.StatusOK, accessToken)
+}
+
+// VerifyEmail godoc
+//
+//	@Summary		Verify email
+//	@Description	will mark the email of the account as verified using the token mailed on sign up
+//	@Tags			users
+//	@Produce		json
+//	@Param			token	query		string	true	"verification token"
+//	@Success		200		{object}	pkg.SuccessResponse
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/verify [get]
+func (u *userHandlerImpl) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		pkg.WriteError(ctx, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := u.svc.VerifyEmail(ctx, token); err != nil {
+		writeError(ctx, err, "failed to verify email")
+		return
+	}
+	pkg.WriteMessage(ctx, http.StatusOK, "email verified")
+}
+
+// ForgotPassword godoc
+//
+//	@Summary		Request a password reset
+//	@Description	will mail a reset link when the email is registered, the response is the same either way
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.ForgotPasswordRequest	true	"account email"
+//	@Success		200		{object}	pkg.SuccessResponse
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Router			/users/forgot-password [post]
+func (u *userHandlerImpl) ForgotPassword(ctx *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+
+	// a failure is only logged, answering differently would tell which
+	// emails are registered
+	if err := u.svc.RequestPasswordReset(ctx, req.Email); err != nil {
+		_ = ctx.Error(err)
+	}
+	pkg.WriteMessage(ctx, http.StatusOK, "if the email is registered, a reset link has been sent")
+}
+
+// ResetPassword godoc
+//
+//	@Summary		Reset password
+//	@Description	will set a new password using the token of a reset link, the token only works once and every session of the user is signed out
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		model.ResetPasswordRequest	true	"reset token and new password"
+//	@Success		200		{object}	pkg.SuccessResponse
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/reset-password [post]
+func (u *userHandlerImpl) ResetPassword(ctx *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+
+	if err := u.svc.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		writeError(ctx, err, "failed to reset password")
+		return
+	}
+	pkg.WriteMessage(ctx, http.StatusOK, "password has been reset")
+}
+
+// ChangePassword godoc
+//
+//	@Summary		Change password
+//	@Description	will replace the password of the current user, the current password is required. Every other session is signed out, the one of refresh_token is kept
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request			body		model.ChangePasswordRequest	true	"current and new password"
+//	@Success		200				{object}	pkg.SuccessResponse
+//	@Failure		400				{object}	pkg.ErrorResponse
+//	@Failure		401				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/users/me/password [post]
+func (u *userHandlerImpl) ChangePassword(ctx *gin.Context) {
+	userID, ok := sessionUserID(ctx)
 	if !ok {
-		ctx.JSON(http.StatusUnauthorized, pkg.ErrorResponse{Message: "invalid user session"})
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
 		return
 	}
-	userIdInt, ok := userId.(float64)
+
+	var req model.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		pkg.WriteBindError(ctx, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		pkg.WriteValidationError(ctx, err)
+		return
+	}
+
+	err := u.svc.ChangePassword(ctx, userID, req.CurrentPassword, req.NewPassword, req.RefreshToken)
+	switch {
+	case err == nil:
+		pkg.WriteMessage(ctx, http.StatusOK, "password has been changed")
+	case errors.Is(err, service.ErrUserNotFound):
+		// the session belongs to an account that is gone
+		writeServiceError(ctx, http.StatusUnauthorized, err)
+	default:
+		writeError(ctx, err, "failed to change password")
+	}
+}
+
+// UserSignOut godoc
+//
+//	@Summary		Sign out current user
+//	@Description	will revoke the bearer token used on this request
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200				{object}	pkg.SuccessResponse
+//	@Failure		401				{object}	pkg.ErrorResponse
+//	@Failure		500				{object}	pkg.ErrorResponse
+//	@Router			/users/signout [post]
+func (u *userHandlerImpl) UserSignOut(ctx *gin.Context) {
+	jti := ctx.GetString(middleware.CLAIM_JTI)
+	if jti == "" {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
+		return
+	}
+	// exp claim is decoded from json as float64
+	exp, _ := ctx.Get(middleware.CLAIM_EXP)
+	expFloat, _ := exp.(float64)
+
+	if err := u.svc.SignOut(ctx, jti, time.Unix(int64(expFloat), 0)); err != nil {
+		pkg.WriteServerError(ctx, err, err.Error())
+		return
+	}
+
+	pkg.WriteMessage(ctx, http.StatusOK, "signed out successfully")
+}
+
+// UpdateUserByID godoc
+//
+//	@Summary		Update current user
+//	@Description	will update only the fields sent. With version, the update is rejected with 409 when the user changed since that version was fetched
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		model.UserUpdate	true	"fields to update"
+//	@Success		200		{object}	pkg.SuccessResponse{data=model.UserResponse}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		409		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/me [patch]
+func (u *userHandlerImpl) UpdateUserByID(ctx *gin.Context) {
+	userId, ok := sessionUserID(ctx)
 	if !ok {
-		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid user id session"})
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
 		return
 	}
 
-	// Bind update user request body
 	var updateUser model.UserUpdate
-	if err := ctx.BindJSON(&updateUser); err != nil {
-		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: err.Error()})
+	if !pkg.BindAndValidate(ctx, &updateUser) {
 		return
 	}
 
-	// Update user by ID
-	updatedUser, err := u.svc.UpdateUserByID(ctx, uint64(userIdInt), updateUser)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+	updatedUser, err := u.svc.UpdateUserByID(ctx, userId, updateUser)
+	u.writeUpdatedUser(ctx, updatedUser, err)
+}
+
+// ReplaceCurrentUser godoc
+//
+//	@Summary		Replace current user
+//	@Description	will overwrite every editable field, all of them are required. With version, the update is rejected with 409 when the user changed since that version was fetched
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		model.UserReplace	true	"every editable field"
+//	@Success		200		{object}	pkg.SuccessResponse{data=model.UserResponse}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		404		{object}	pkg.ErrorResponse
+//	@Failure		409		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/me [put]
+func (u *userHandlerImpl) ReplaceCurrentUser(ctx *gin.Context) {
+	userId, ok := sessionUserID(ctx)
+	if !ok {
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
 		return
 	}
 
-	ctx.JSON(http.StatusOK, updatedUser)
+	var replaceUser model.UserReplace
+	if !pkg.BindAndValidate(ctx, &replaceUser) {
+		return
+	}
+
+	updatedUser, err := u.svc.ReplaceUser(ctx, userId, replaceUser)
+	u.writeUpdatedUser(ctx, updatedUser, err)
+}
+
+func (u *userHandlerImpl) writeUpdatedUser(ctx *gin.Context, user model.User, err error) {
+	if err != nil {
+		writeError(ctx, err, err.Error())
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, user.ToResponse())
 }
 
 // DeleteUsersById godoc
 //
-//		@Summary		Delete user by selected id
-//		@Description	will delete user with given id from param
-//		@Tags			users
-//		@Accept			json
-//		@Produce		json
-//	 	@Param 			Authorization header string true "bearer token"
-//		@Param			id	path		int	true	"User ID"
-//		@Success		200	{object}	model.User
-//		@Failure		400	{object}	pkg.ErrorResponse
-//		@Failure		404	{object}	pkg.ErrorResponse
-//		@Failure		500	{object}	pkg.ErrorResponse
-//		@Router			/users/{id} [delete]
+//	@Summary		Delete user by selected id
+//	@Description	admin only, will delete user with given id from param
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	pkg.SuccessResponse{data=model.UserResponse}
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/users/{id} [delete]
 func (u *userHandlerImpl) DeleteUsersById(ctx *gin.Context) {
 	// get id user
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if id == 0 || err != nil {
-		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid required param"})
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid required param")
 		return
 	}
 
-	// check user id session from context
-	userId, ok := ctx.Get(middleware.CLAIM_USER_ID)
-	if !ok {
-		ctx.JSON(http.StatusUnauthorized, pkg.ErrorResponse{Message: "invalid user session"})
+	user, err := u.svc.DeleteUsersById(ctx, uint64(id))
+	if err != nil {
+		writeError(ctx, err, err.Error())
 		return
 	}
-	userIdInt, ok := userId.(float64)
+	pkg.WriteSuccess(ctx, http.StatusOK, user.ToResponse())
+}
+
+// DeleteCurrentUser godoc
+//
+//	@Summary		Delete current user
+//	@Description	will delete the account of the session user, tokens issued for it stop working
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		204
+//	@Failure		401	{object}	pkg.ErrorResponse
+//	@Failure		404	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/users/me [delete]
+func (u *userHandlerImpl) DeleteCurrentUser(ctx *gin.Context) {
+	userID, ok := sessionUserID(ctx)
 	if !ok {
-		ctx.JSON(http.StatusBadRequest, pkg.ErrorResponse{Message: "invalid user id session"})
+		pkg.WriteError(ctx, http.StatusUnauthorized, "invalid user session")
 		return
 	}
-	if id != int(userIdInt) {
-		ctx.JSON(http.StatusUnauthorized, pkg.ErrorResponse{Message: "invalid user request"})
+
+	if _, err := u.svc.DeleteUsersById(ctx, userID); err != nil {
+		writeError(ctx, err, err.Error())
 		return
 	}
+	ctx.Status(http.StatusNoContent)
+}
 
-	user, err := u.svc.DeleteUsersById(ctx, uint64(id))
+// HardDeleteUser godoc
+//
+//	@Summary		Permanently delete a user
+//	@Description	admin only, removes the user and everything the user owns even after a soft delete
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		204
+//	@Failure		400	{object}	pkg.ErrorResponse
+//	@Failure		403	{object}	pkg.ErrorResponse
+//	@Failure		500	{object}	pkg.ErrorResponse
+//	@Router			/admin/users/{id} [delete]
+func (u *userHandlerImpl) HardDeleteUser(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if id == 0 || err != nil {
+		pkg.WriteError(ctx, http.StatusBadRequest, "invalid required param")
+		return
+	}
+
+	if err := u.svc.HardDeleteUser(ctx, id); err != nil {
+		pkg.WriteServerError(ctx, err, err.Error())
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// UsernameAvailable godoc
+//
+//	@Summary		Check username availability
+//	@Description	reports whether a username can still be used to sign up
+//	@Tags			users
+//	@Produce		json
+//	@Param			username	query		string	true	"username to check"
+//	@Success		200			{object}	pkg.SuccessResponse{data=model.UsernameAvailability}
+//	@Failure		400			{object}	pkg.ErrorResponse
+//	@Failure		429			{object}	pkg.ErrorResponse
+//	@Failure		500			{object}	pkg.ErrorResponse
+//	@Router			/users/username-available [get]
+func (u *userHandlerImpl) UsernameAvailable(ctx *gin.Context) {
+	username := strings.TrimSpace(ctx.Query("username"))
+	if username == "" {
+		pkg.WriteError(ctx, http.StatusBadRequest, "username is required")
+		return
+	}
+
+	available, err := u.svc.IsUsernameAvailable(ctx, username)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{Message: err.Error()})
+		pkg.WriteServerError(ctx, err, err.Error())
+		return
+	}
+	pkg.WriteSuccess(ctx, http.StatusOK, model.UsernameAvailability{Available: available})
+}
+
+// SearchUsers godoc
+//
+//	@Summary		Autocomplete usernames
+//	@Description	users whose username starts with prefix, the closest match first
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			prefix	query		string	true	"start of the username, a leading @ is ignored"
+//	@Param			limit	query		int		false	"number of users, default 5, max 20"
+//	@Success		200		{object}	pkg.SuccessResponse{data=[]model.MentionedUser}
+//	@Failure		400		{object}	pkg.ErrorResponse
+//	@Failure		401		{object}	pkg.ErrorResponse
+//	@Failure		429		{object}	pkg.ErrorResponse
+//	@Failure		500		{object}	pkg.ErrorResponse
+//	@Router			/users/search [get]
+func (u *userHandlerImpl) SearchUsers(ctx *gin.Context) {
+	prefix := strings.TrimSpace(ctx.Query("prefix"))
+	if prefix == "" {
+		pkg.WriteError(ctx, http.StatusBadRequest, "prefix is required")
 		return
 	}
-	if user.ID == 0 {
-		ctx.JSON(http.StatusNotFound, pkg.ErrorResponse{Message: "user not found"})
+	limit := model.DefaultUserSearchLimit
+	if raw := ctx.Query("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			pkg.WriteError(ctx, http.StatusBadRequest, pkg.ErrInvalidLimit.Error())
+			return
+		}
+		limit = min(limit, model.MaxUserSearchLimit)
+	}
+
+	users, err := u.svc.SearchUsernames(ctx, prefix, limit)
+	if err != nil {
+		pkg.WriteServerError(ctx, err, err.Error())
 		return
 	}
-	ctx.JSON(http.StatusOK, user)
+	pkg.WriteSuccess(ctx, http.StatusOK, users)
 }
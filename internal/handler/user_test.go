@@ -2,16 +2,24 @@ package handler
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
+	"go-mygram/internal/middleware"
 	"go-mygram/internal/model"
+	"go-mygram/internal/service"
 	"go-mygram/internal/service/mocks"
+	"go-mygram/pkg"
 )
 
 func TestUserSignUp(t *testing.T) {
@@ -55,14 +63,948 @@ func TestUserSignUp(t *testing.T) {
 		g, _ := gin.CreateTestContext(rec)
 		g.Request = req
 
+		// email and age are required, the service is never reached
+		svcMock := mocks.NewUserService(t)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.UserSignUp(g)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	})
+
+	t.Run("error sign up with tokens service", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		req := httptest.NewRequest(http.MethodPost, "/users/sign-up", bytes.NewBuffer([]byte(`{"username":"username","password":"abc12345","email":"user@mail.com","age":20}`)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = req
+
 		svcMock := mocks.NewUserService(t)
 		svcMock.
-			On("SignUp", g, model.UserSignUp{Username: "username", Password: "abc12345"}).
-			Return(model.User{}, errors.New("some error"))
+			On("SignUpWithTokens", g, model.UserSignUp{Username: "username", Password: "abc12345", Email: "user@mail.com", Age: 20}).
+			Return(model.User{}, model.TokenPair{}, errors.New("some error"))
 
 		usrHdl := userHandlerImpl{svc: svcMock}
 		usrHdl.UserSignUp(g)
 
 		assert.Equal(t, http.StatusInternalServerError, rec.Result().StatusCode)
 	})
+
+	t.Run("error duplicate email", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		req := httptest.NewRequest(http.MethodPost, "/users/sign-up", bytes.NewBuffer([]byte(`{"username":"username","password":"abc12345","email":"user@mail.com","age":20}`)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = req
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.
+			On("SignUpWithTokens", g, model.UserSignUp{Username: "username", Password: "abc12345", Email: "user@mail.com", Age: 20}).
+			Return(model.User{}, model.TokenPair{}, service.ErrEmailAlreadyExists)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.UserSignUp(g)
+
+		assert.Equal(t, http.StatusConflict, rec.Result().StatusCode)
+	})
+
+	t.Run("success sign up returns tokens", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		req := httptest.NewRequest(http.MethodPost, "/users/sign-up", bytes.NewBuffer([]byte(`{"username":"username","password":"abc12345","email":"user@mail.com","age":20}`)))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = req
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.
+			On("SignUpWithTokens", g, model.UserSignUp{Username: "username", Password: "abc12345", Email: "user@mail.com", Age: 20}).
+			Return(model.User{ID: 1, Username: "username"}, model.TokenPair{AccessToken: model.AccessToken{Token: "access"}, RefreshToken: "refresh"}, nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.UserSignUp(g)
+
+		assert.Equal(t, http.StatusCreated, rec.Result().StatusCode)
+		assert.Contains(t, rec.Body.String(), `"username":"username"`)
+		assert.Contains(t, rec.Body.String(), `"access_token":"access"`)
+		assert.Contains(t, rec.Body.String(), `"refresh_token":"refresh"`)
+		// abc12345 passes validation but is short and has no symbol
+		assert.Contains(t, rec.Body.String(), `"warnings":[{"field":"password"`)
+		assert.Equal(t, "/users/1", rec.Header().Get("Location"))
+	})
+}
+
+func TestGetUsers(t *testing.T) {
+	for _, query := range []string{"page=0", "page=-1", "limit=-5", "page=abc", "sort_by=password", "sort_by=id%3Bdrop", "order=sideways",
+		"created_after=yesterday", "created_before=2024-01-01", "created_after=2024-02-01T00:00:00Z&created_before=2024-01-01T00:00:00Z",
+		"fields=id,password", "fields=,"} {
+		t.Run("error invalid "+query, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			req := httptest.NewRequest(http.MethodGet, "/users?"+query, nil)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = req
+
+			usrHdl := userHandlerImpl{}
+			usrHdl.GetUsers(g)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+		})
+	}
+
+	t.Run("success cap limit", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		req := httptest.NewRequest(http.MethodGet, "/users?page=2&limit=500", nil)
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = req
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.
+			On("GetUsers", g, model.UserListParams{Pagination: pkg.NewPagination(2, 100), SortBy: "created_at", Order: "desc"}).
+			Return([]model.User{{ID: 101}}, int64(101), nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUsers(g)
+
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+		// the list is admin only, so it carries the activity columns
+		assert.Contains(t, rec.Body.String(), `"last_login_at":null`)
+	})
+
+	t.Run("success select fields", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		req := httptest.NewRequest(http.MethodGet, "/users?fields=id,username,last_login_at", nil)
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = req
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.
+			On("GetUsers", g, model.UserListParams{Pagination: pkg.NewPagination(1, 20), SortBy: "created_at", Order: "desc"}).
+			Return([]model.User{{ID: 4, Username: "user4", Email: "user4@mail.com"}}, int64(1), nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUsers(g)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":{"data":[{"id":4,"username":"user4","last_login_at":null}],"page":1,"limit":20,"total":1,"total_pages":1}}`, rec.Body.String())
+	})
+
+	t.Run("success filter by registration date", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		req := httptest.NewRequest(http.MethodGet, "/users?created_after=2024-01-01T00:00:00Z&created_before=2024-02-01T07:00:00%2B07:00&sort_by=id", nil)
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = req
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.
+			On("GetUsers", g, mock.MatchedBy(func(params model.UserListParams) bool {
+				return params.SortBy == "id" &&
+					params.CreatedAfter.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+					params.CreatedBefore.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+			})).
+			Return([]model.User{{ID: 1}}, int64(1), nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUsers(g)
+
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	})
+}
+
+func TestGetUserCount(t *testing.T) {
+	for _, query := range []string{"created_after=yesterday", "created_after=2024-02-01T00:00:00Z&created_before=2024-01-01T00:00:00Z"} {
+		t.Run("error invalid "+query, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/users/count?"+query, nil)
+
+			usrHdl := userHandlerImpl{}
+			usrHdl.GetUserCount(g)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+
+	t.Run("success count with filters", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/count?search=bob&created_before=2024-02-01T00:00:00Z", nil)
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.
+			On("GetUserCount", g, mock.MatchedBy(func(params model.UserCountParams) bool {
+				return params.Search == "bob" && params.CreatedAfter == nil &&
+					params.CreatedBefore.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+			})).
+			Return(int64(12), nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUserCount(g)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":{"count":12}}`, rec.Body.String())
+	})
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	t.Run("error missing session", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/me", nil)
+
+		usrHdl := userHandlerImpl{}
+		usrHdl.GetCurrentUser(g)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Result().StatusCode)
+	})
+
+	t.Run("success get current user", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/me", nil)
+		// claim decoded from jwt
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.On("GetUsersById", g, uint64(7)).Return(model.User{ID: 7, Username: "user7", Password: "hash"}, nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetCurrentUser(g)
+
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+		assert.NotContains(t, rec.Body.String(), "hash")
+
+		var body struct {
+			Data model.UserResponse `json:"data"`
+		}
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, uint64(7), body.Data.ID)
+		assert.Equal(t, "user7", body.Data.Username)
+	})
+}
+
+func TestGetUsersBatch(t *testing.T) {
+	newContext := func(body string) (*gin.Context, *httptest.ResponseRecorder) {
+		gin.SetMode(gin.TestMode)
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodPost, "/users/batch", strings.NewReader(body))
+		g.Request.Header.Set("Content-Type", "application/json")
+		return g, rec
+	}
+
+	t.Run("success missing ids are left out", func(t *testing.T) {
+		g, rec := newContext(`{"ids":[1,2,3]}`)
+		svcMock := mocks.NewUserService(t)
+		svcMock.On("GetUsersByIDs", g, []uint64{1, 2, 3}).Return([]model.User{{ID: 1}, {ID: 3}}, nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUsersBatch(g)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"id":1`)
+		assert.Contains(t, rec.Body.String(), `"id":3`)
+		assert.NotContains(t, rec.Body.String(), `"id":2`)
+	})
+
+	for desc, body := range map[string]string{
+		"error batch over the cap": `{"ids":[` + strings.TrimSuffix(strings.Repeat("1,", model.MaxUserBatchSize+1), ",") + `]}`,
+		"error empty ids":          `{"ids":[]}`,
+		"error ids not numbers":    `{"ids":["a"]}`,
+	} {
+		t.Run(desc, func(t *testing.T) {
+			g, rec := newContext(body)
+
+			usrHdl := userHandlerImpl{}
+			usrHdl.GetUsersBatch(g)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		query  string
+		svcErr error
+		code   int
+	}{
+		{desc: "success verify", query: "token=abc", code: http.StatusOK},
+		{desc: "error missing token", query: "", code: http.StatusBadRequest},
+		{desc: "error unknown token", query: "token=abc", svcErr: service.ErrInvalidVerificationToken, code: http.StatusBadRequest},
+		{desc: "error expired token", query: "token=abc", svcErr: service.ErrVerificationTokenExpired, code: http.StatusBadRequest},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/users/verify?"+tC.query, nil)
+
+			svcMock := mocks.NewUserService(t)
+			if tC.query != "" {
+				svcMock.On("VerifyEmail", g, "abc").Return(tC.svcErr)
+			}
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.VerifyEmail(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	// the answer must not depend on whether the email is registered
+	for desc, svcErr := range map[string]error{
+		"success known or unknown email": nil,
+		"success service failure":        errors.New("smtp down"),
+	} {
+		t.Run(desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/users/forgot-password", strings.NewReader(`{"email":"foo@example.com"}`))
+			g.Request.Header.Set("Content-Type", "application/json")
+
+			svcMock := mocks.NewUserService(t)
+			svcMock.On("RequestPasswordReset", g, "foo@example.com").Return(svcErr)
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.ForgotPassword(g)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		body   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success reset", body: `{"token":"abc","new_password":"newpass123"}`, code: http.StatusOK},
+		{desc: "error weak password", body: `{"token":"abc","new_password":"short"}`, code: http.StatusBadRequest},
+		{desc: "error invalid token", body: `{"token":"abc","new_password":"newpass123"}`, svcErr: service.ErrInvalidResetToken, code: http.StatusBadRequest},
+		{desc: "error expired token", body: `{"token":"abc","new_password":"newpass123"}`, svcErr: service.ErrResetTokenExpired, code: http.StatusBadRequest},
+		{desc: "error password of the account", body: `{"token":"abc","new_password":"newpass123"}`, svcErr: pkg.ValidationErrors{{Field: "password", Message: "password must not be the same as the username"}}, code: http.StatusBadRequest},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/users/reset-password", strings.NewReader(tC.body))
+			g.Request.Header.Set("Content-Type", "application/json")
+
+			svcMock := mocks.NewUserService(t)
+			if tC.desc != "error weak password" {
+				svcMock.On("ResetPassword", g, "abc", "newpass123").Return(tC.svcErr)
+			}
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.ResetPassword(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		body   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success change", body: `{"current_password":"oldpass123","new_password":"newpass123"}`, code: http.StatusOK},
+		{desc: "error weak password", body: `{"current_password":"oldpass123","new_password":"short"}`, code: http.StatusBadRequest},
+		{desc: "error wrong current password", body: `{"current_password":"oldpass123","new_password":"newpass123"}`, svcErr: service.ErrWrongCurrentPassword, code: http.StatusBadRequest},
+		{desc: "error password unchanged", body: `{"current_password":"oldpass123","new_password":"newpass123"}`, svcErr: service.ErrPasswordUnchanged, code: http.StatusBadRequest},
+		{desc: "error server", body: `{"current_password":"oldpass123","new_password":"newpass123"}`, svcErr: errors.New("some error"), code: http.StatusInternalServerError},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/users/me/password", strings.NewReader(tC.body))
+			g.Request.Header.Set("Content-Type", "application/json")
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewUserService(t)
+			if tC.desc != "error weak password" {
+				svcMock.On("ChangePassword", g, uint64(7), "oldpass123", "newpass123", "").Return(tC.svcErr)
+			}
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.ChangePassword(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestUpdateUserByID(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		body   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success update", body: `{"username":"user7","version":2}`, code: http.StatusOK},
+		{desc: "error invalid version", body: `{"username":"user7","version":0}`, code: http.StatusBadRequest},
+		{desc: "error bio too long", body: `{"bio":"` + strings.Repeat("a", model.MaxBioLength+1) + `"}`, code: http.StatusBadRequest},
+		{desc: "error user modified", body: `{"username":"user7","version":2}`, svcErr: service.ErrUserModified, code: http.StatusConflict},
+		{desc: "error username taken", body: `{"username":"user7","version":2}`, svcErr: service.ErrUsernameAlreadyExists, code: http.StatusConflict},
+		{desc: "error email domain not allowed", body: `{"email":"user7@mail.com","version":2}`, code: http.StatusBadRequest},
+	}
+	defer func(old []string) { model.AllowedEmailDomains = old }(model.AllowedEmailDomains)
+	model.AllowedEmailDomains = []string{"corp.com"}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPatch, "/users/me", strings.NewReader(tC.body))
+			g.Request.Header.Set("Content-Type", "application/json")
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewUserService(t)
+			if tC.code != http.StatusBadRequest {
+				username, version := "user7", int64(2)
+				svcMock.
+					On("UpdateUserByID", g, uint64(7), model.UserUpdate{Username: &username, Version: &version}).
+					Return(model.User{ID: 7, Username: "user7", Version: 3}, tC.svcErr)
+			}
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.UpdateUserByID(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestReplaceCurrentUser(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		body   string
+		svcErr error
+		code   int
+	}{
+		{desc: "success replace", body: `{"username":"user7","email":"user7@mail.com"}`, code: http.StatusOK},
+		{desc: "error missing email", body: `{"username":"user7"}`, code: http.StatusBadRequest},
+		{desc: "error missing username", body: `{"email":"user7@mail.com"}`, code: http.StatusBadRequest},
+		{desc: "error bio too long", body: `{"username":"user7","email":"user7@mail.com","bio":"` + strings.Repeat("a", model.MaxBioLength+1) + `"}`, code: http.StatusBadRequest},
+		{desc: "error email taken", body: `{"username":"user7","email":"user7@mail.com"}`, svcErr: service.ErrEmailAlreadyExists, code: http.StatusConflict},
+		{desc: "error email domain not allowed", body: `{"username":"user7","email":"user7@other.com"}`, code: http.StatusBadRequest},
+	}
+	defer func(old []string) { model.AllowedEmailDomains = old }(model.AllowedEmailDomains)
+	model.AllowedEmailDomains = []string{"mail.com"}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPut, "/users/me", strings.NewReader(tC.body))
+			g.Request.Header.Set("Content-Type", "application/json")
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewUserService(t)
+			if tC.code != http.StatusBadRequest {
+				svcMock.
+					On("ReplaceUser", g, uint64(7), model.UserReplace{Username: "user7", Email: "user7@mail.com"}).
+					Return(model.User{ID: 7, Username: "user7", Email: "user7@mail.com", Version: 3}, tC.svcErr)
+			}
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.ReplaceCurrentUser(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
+}
+
+func TestUsernameAvailable(t *testing.T) {
+	t.Run("error missing username", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/username-available", nil)
+
+		usrHdl := userHandlerImpl{}
+		usrHdl.UsernameAvailable(g)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+	})
+
+	t.Run("success username taken", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/username-available?username=foo", nil)
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.On("IsUsernameAvailable", g, "foo").Return(false, nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.UsernameAvailable(g)
+
+		assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+		assert.JSONEq(t, `{"data":{"available":false}}`, rec.Body.String())
+	})
+}
+
+func TestSearchUsers(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		query  string
+		limit  int
+		mock   bool
+		status int
+	}{
+		{desc: "error missing prefix", query: "", status: http.StatusBadRequest},
+		{desc: "error invalid limit", query: "prefix=jo&limit=0", status: http.StatusBadRequest},
+		{desc: "success default limit", query: "prefix=jo", limit: model.DefaultUserSearchLimit, mock: true, status: http.StatusOK},
+		{desc: "success limit clamped", query: "prefix=jo&limit=500", limit: model.MaxUserSearchLimit, mock: true, status: http.StatusOK},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/users/search?"+tC.query, nil)
+
+			svcMock := mocks.NewUserService(t)
+			if tC.mock {
+				svcMock.On("SearchUsernames", g, "jo", tC.limit).Return([]model.MentionedUser{{ID: 1, Username: "jo"}, {ID: 2, Username: "john"}}, nil)
+			}
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.SearchUsers(g)
+
+			assert.Equal(t, tC.status, rec.Code)
+			if tC.mock {
+				assert.JSONEq(t, `{"data":[{"id":1,"username":"jo"},{"id":2,"username":"john"}]}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeleteUsersById(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	g, _ := gin.CreateTestContext(rec)
+	g.Request = httptest.NewRequest(http.MethodDelete, "/users/8", nil)
+	g.Params = gin.Params{{Key: "id", Value: "8"}}
+	g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+	svcMock := mocks.NewUserService(t)
+	svcMock.On("DeleteUsersById", g, uint64(8)).Return(model.User{ID: 8}, nil)
+
+	usrHdl := userHandlerImpl{svc: svcMock}
+	usrHdl.DeleteUsersById(g)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+}
+
+func TestDeleteCurrentUser(t *testing.T) {
+	t.Run("success delete session user", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodDelete, "/users/me", nil)
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.On("DeleteUsersById", g, uint64(7)).Return(model.User{ID: 7}, nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.DeleteCurrentUser(g)
+
+		assert.Equal(t, http.StatusNoContent, g.Writer.Status())
+	})
+
+	t.Run("error missing session", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodDelete, "/users/me", nil)
+
+		usrHdl := userHandlerImpl{}
+		usrHdl.DeleteCurrentUser(g)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestUserSignInLocked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	g, _ := gin.CreateTestContext(rec)
+	g.Request = httptest.NewRequest(http.MethodPost, "/users/login", strings.NewReader(`{"email":"foo@example.com","password":"abc12345"}`))
+	g.Request.Header.Set("Content-Type", "application/json")
+
+	svcMock := mocks.NewUserService(t)
+	svcMock.On("SignIn", g, model.UserSignIn{Email: "foo@example.com", Password: "abc12345"}).Return(model.User{}, service.ErrAccountLocked)
+
+	usrHdl := userHandlerImpl{svc: svcMock}
+	usrHdl.UserSignIn(g)
+
+	assert.Equal(t, http.StatusLocked, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"ACCOUNT_LOCKED"`)
+}
+
+func TestUserSignInInvalidCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	g, _ := gin.CreateTestContext(rec)
+	g.Request = httptest.NewRequest(http.MethodPost, "/users/login", strings.NewReader(`{"email":"foo@example.com","password":"abc12345"}`))
+	g.Request.Header.Set("Content-Type", "application/json")
+
+	svcMock := mocks.NewUserService(t)
+	svcMock.On("SignIn", g, model.UserSignIn{Email: "foo@example.com", Password: "abc12345"}).Return(model.User{}, service.ErrInvalidCredentials)
+
+	usrHdl := userHandlerImpl{svc: svcMock}
+	usrHdl.UserSignIn(g)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"code":"INVALID_CREDENTIALS","message":"invalid email or password"}`, rec.Body.String())
+}
+
+func TestUserSignInRefreshToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	g, _ := gin.CreateTestContext(rec)
+	g.Request = httptest.NewRequest(http.MethodPost, "/users/login", strings.NewReader(`{"email":"foo@example.com","password":"abc12345"}`))
+	g.Request.Header.Set("Content-Type", "application/json")
+
+	user := model.User{ID: 1, Email: "foo@example.com"}
+	svcMock := mocks.NewUserService(t)
+	svcMock.On("SignIn", g, model.UserSignIn{Email: "foo@example.com", Password: "abc12345"}).Return(user, nil)
+	svcMock.On("GenerateUserAccessToken", g, user).Return(model.AccessToken{Token: "access"}, nil)
+	svcMock.On("GenerateRefreshToken", g, user).Return("refresh", nil)
+
+	usrHdl := userHandlerImpl{svc: svcMock}
+	usrHdl.UserSignIn(g)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"access_token":"access"`)
+	assert.Contains(t, rec.Body.String(), `"refresh_token":"refresh"`)
+}
+
+func TestRefreshToken(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		body   string
+		call   bool
+		svcErr error
+		code   int
+	}{
+		{desc: "success new access token", body: `{"refresh_token":"refresh"}`, call: true, code: http.StatusOK},
+		{desc: "error missing refresh token", body: `{}`, code: http.StatusBadRequest},
+		{desc: "error invalid refresh token", body: `{"refresh_token":"refresh"}`, call: true, svcErr: service.ErrInvalidRefreshToken, code: http.StatusUnauthorized},
+		{desc: "error refresh token expired", body: `{"refresh_token":"refresh"}`, call: true, svcErr: service.ErrRefreshTokenExpired, code: http.StatusUnauthorized},
+		{desc: "error refresh token revoked", body: `{"refresh_token":"refresh"}`, call: true, svcErr: service.ErrRefreshTokenRevoked, code: http.StatusUnauthorized},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/users/refresh", strings.NewReader(tC.body))
+			g.Request.Header.Set("Content-Type", "application/json")
+
+			svcMock := mocks.NewUserService(t)
+			if tC.call {
+				svcMock.On("RefreshAccessToken", g, "refresh").Return(model.AccessToken{Token: "access"}, tC.svcErr)
+			}
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.RefreshToken(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+			if tC.code == http.StatusOK {
+				assert.Contains(t, rec.Body.String(), `"access_token":"access"`)
+			}
+		})
+	}
+}
+
+func TestUserSignInBindError(t *testing.T) {
+	testCases := []struct {
+		desc string
+		body string
+		want string
+	}{
+		{
+			desc: "error number where string expected",
+			body: `{"email":"user@mail.com","password":123}`,
+			want: `{"code":"VALIDATION_FAILED","message":"field password expected type string","errors":[{"field":"password","message":"field password expected type string"}]}`,
+		},
+		{
+			desc: "error malformed json",
+			body: `{"email":`,
+			want: `{"code":"BAD_REQUEST","message":"malformed json body","errors":["unexpected end of input"]}`,
+		},
+		{
+			desc: "error empty body",
+			body: ``,
+			want: `{"code":"BAD_REQUEST","message":"request body is empty"}`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewBufferString(tC.body))
+			g.Request.Header.Set("Content-Type", "application/json")
+
+			usrHdl := userHandlerImpl{}
+			usrHdl.UserSignIn(g)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+			assert.JSONEq(t, tC.want, rec.Body.String())
+		})
+	}
+}
+
+func TestGetUsersByIdFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("error unknown field", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/3?fields=id,last_login_at", nil)
+		g.Params = gin.Params{{Key: "id", Value: "3"}}
+
+		usrHdl := userHandlerImpl{}
+		usrHdl.GetUsersById(g)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `unknown field \"last_login_at\"`)
+	})
+
+	t.Run("success select fields", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/3?fields=username,follower_count", nil)
+		g.Params = gin.Params{{Key: "id", Value: "3"}}
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.On("GetUserByIDOrUsername", g, "3").Return(model.User{ID: 3, Username: "user3", FollowerCount: 2}, nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUsersById(g)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":{"username":"user3","follower_count":2}}`, rec.Body.String())
+	})
+}
+
+func TestGetUsersByIdETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := model.User{ID: 3, Username: "user3", UpdatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/3", nil)
+		g.Request.Header.Set("If-None-Match", ifNoneMatch)
+		g.Params = gin.Params{{Key: "id", Value: "3"}}
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.On("GetUserByIDOrUsername", g, "3").Return(user, nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUsersById(g)
+		return rec
+	}
+
+	first := get("")
+	assert.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	second := get(etag)
+	assert.Equal(t, http.StatusNotModified, second.Code)
+	assert.Empty(t, second.Body.String())
+
+	// a newer updated_at changes the tag
+	user.UpdatedAt = user.UpdatedAt.Add(time.Minute)
+	third := get(etag)
+	assert.Equal(t, http.StatusOK, third.Code)
+	assert.NotEqual(t, etag, third.Header().Get("ETag"))
+}
+
+func TestGetUsersByIdUsername(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		param  string
+		user   model.User
+		err    error
+		status int
+	}{
+		{desc: "success by username", param: "alice", user: model.User{ID: 3, Username: "alice"}, status: http.StatusOK},
+		{desc: "error unknown username", param: "ghost", err: service.ErrUserNotFound, status: http.StatusNotFound},
+		{desc: "error unknown id", param: "404", err: service.ErrUserNotFound, status: http.StatusNotFound},
+		{desc: "error database", param: "alice", err: errors.New("connection refused"), status: http.StatusInternalServerError},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/users/"+tC.param, nil)
+			g.Params = gin.Params{{Key: "id", Value: tC.param}}
+
+			svcMock := mocks.NewUserService(t)
+			svcMock.On("GetUserByIDOrUsername", g, tC.param).Return(tC.user, tC.err)
+
+			usrHdl := userHandlerImpl{svc: svcMock}
+			usrHdl.GetUsersById(g)
+
+			assert.Equal(t, tC.status, rec.Code)
+		})
+	}
+}
+
+func TestGetUsersByIdStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("error unknown include", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/3?include=photos", nil)
+		g.Params = gin.Params{{Key: "id", Value: "3"}}
+
+		usrHdl := userHandlerImpl{}
+		usrHdl.GetUsersById(g)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "include must be stats")
+	})
+
+	t.Run("success include stats", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/3?include=stats&fields=username,stats", nil)
+		g.Params = gin.Params{{Key: "id", Value: "3"}}
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.On("GetUserByIDOrUsername", g, "3").Return(model.User{ID: 3, Username: "user3"}, nil)
+		svcMock.On("GetUserStats", g, uint64(7), uint64(3)).Return(model.UserStats{PhotoCount: 4, CommentCount: 2, FollowerCount: 5, FollowingCount: 1}, nil)
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUsersById(g)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data":{"username":"user3","stats":{"photo_count":4,"comment_count":2,"follower_count":5,"following_count":1}}}`, rec.Body.String())
+	})
+
+	t.Run("error count stats", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/users/3?include=stats", nil)
+		g.Params = gin.Params{{Key: "id", Value: "3"}}
+		g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+		svcMock := mocks.NewUserService(t)
+		svcMock.On("GetUserByIDOrUsername", g, "3").Return(model.User{ID: 3, Username: "user3"}, nil)
+		svcMock.On("GetUserStats", g, uint64(7), uint64(3)).Return(model.UserStats{}, errors.New("connection refused"))
+
+		usrHdl := userHandlerImpl{svc: svcMock}
+		usrHdl.GetUsersById(g)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestUploadAvatar(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("x", 100))
+
+	newRequest := func(t *testing.T, content []byte) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		part, err := w.CreateFormFile("avatar", "me.png")
+		assert.Nil(t, err)
+		_, _ = part.Write(content)
+		assert.Nil(t, w.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/users/me/avatar", &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		return req
+	}
+
+	testCases := []struct {
+		desc    string
+		content []byte
+		maxSize int64
+		svcErr  error
+		code    int
+	}{
+		{desc: "success upload png", content: png, maxSize: 1 << 20, code: http.StatusOK},
+		{desc: "error file over the limit", content: png, maxSize: 10, code: http.StatusRequestEntityTooLarge},
+		{desc: "error body far over the limit", content: bytes.Repeat(png, 2000), maxSize: 10, code: http.StatusRequestEntityTooLarge},
+		{desc: "error not an image", content: []byte("hello"), maxSize: 1 << 20, svcErr: service.ErrUnsupportedImageType, code: http.StatusUnsupportedMediaType},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = newRequest(t, tC.content)
+			g.Set(middleware.CLAIM_USER_ID, float64(7))
+
+			svcMock := mocks.NewUserService(t)
+			if tC.code == http.StatusOK || tC.svcErr != nil {
+				svcMock.On("UpdateAvatar", g, uint64(7), mock.Anything, http.DetectContentType(tC.content)).
+					Return(model.User{ID: 7, AvatarURL: "/uploads/avatars/7-a.png"}, tC.svcErr)
+			}
+
+			usrHdl := userHandlerImpl{svc: svcMock, avatarMaxBytes: tC.maxSize}
+			usrHdl.UploadAvatar(g)
+
+			assert.Equal(t, tC.code, rec.Code)
+		})
+	}
 }
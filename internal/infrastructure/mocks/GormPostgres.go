@@ -3,9 +3,13 @@
 package mocks
 
 import (
+	context "context"
+
 	gorm "gorm.io/gorm"
 
 	mock "github.com/stretchr/testify/mock"
+
+	sql "database/sql"
 )
 
 // GormPostgres is an autogenerated mock type for the GormPostgres type
@@ -13,6 +17,24 @@ type GormPostgres struct {
 	mock.Mock
 }
 
+// Close provides a mock function with given fields:
+func (_m *GormPostgres) Close() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Close")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetConnection provides a mock function with given fields:
 func (_m *GormPostgres) GetConnection() *gorm.DB {
 	ret := _m.Called()
@@ -33,6 +55,52 @@ func (_m *GormPostgres) GetConnection() *gorm.DB {
 	return r0
 }
 
+// Ping provides a mock function with given fields: ctx
+func (_m *GormPostgres) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Stats provides a mock function with given fields:
+func (_m *GormPostgres) Stats() (sql.DBStats, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 sql.DBStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (sql.DBStats, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() sql.DBStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(sql.DBStats)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewGormPostgres creates a new instance of GormPostgres. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewGormPostgres(t interface {
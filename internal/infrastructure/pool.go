@@ -0,0 +1,21 @@
+package infrastructure
+
+import (
+	"database/sql"
+
+	"go-mygram/internal/config"
+)
+
+// configurePool applies the pool limits, the database/sql defaults allow
+// unlimited open connections which exhausts postgres under load
+func configurePool(sqlDB *sql.DB, cfg config.DatabaseConfig) {
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+}
@@ -0,0 +1,32 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"go-mygram/internal/config"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurePool(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		cfg     config.DatabaseConfig
+		maxOpen int
+	}{
+		{desc: "success limits applied", cfg: config.DatabaseConfig{MaxOpenConns: 25, MaxIdleConns: 10, ConnMaxLifetime: time.Minute}, maxOpen: 25},
+		{desc: "success zero keeps the default", cfg: config.DatabaseConfig{}, maxOpen: 0},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			sqlDB, _, err := sqlmock.New()
+			assert.Nil(t, err)
+			defer sqlDB.Close()
+
+			configurePool(sqlDB, tC.cfg)
+			assert.Equal(t, tC.maxOpen, sqlDB.Stats().MaxOpenConnections)
+		})
+	}
+}
@@ -1,27 +1,35 @@
 package infrastructure
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 
+	"go-mygram/internal/config"
+	"go-mygram/pkg/metrics"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 type GormPostgres interface {
 	GetConnection() *gorm.DB
+	Ping(ctx context.Context) error
+	Stats() (sql.DBStats, error)
+	Close() error
 }
 
 type gormPostgresImpl struct {
 	master *gorm.DB
 }
 
-func NewGormPostgres() GormPostgres {
+func NewGormPostgres(cfg config.DatabaseConfig) GormPostgres {
 	return &gormPostgresImpl{
-		master: connect(),
+		master: connect(cfg),
 	}
 }
 
-func connect() *gorm.DB {
+func connect(cfg config.DatabaseConfig) *gorm.DB {
 	host := "127.0.0.1"
 	port := "5432"
 	user := "postgres"
@@ -33,9 +41,47 @@ func connect() *gorm.DB {
 	if err != nil {
 		panic(err)
 	}
+	if err := metrics.RegisterGormCallbacks(db); err != nil {
+		panic(err)
+	}
+	if err := registerQueryTimeout(db, cfg.QueryTimeout); err != nil {
+		panic(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic(err)
+	}
+	configurePool(sqlDB, cfg)
 	return db
 }
 
 func (g *gormPostgresImpl) GetConnection() *gorm.DB {
 	return g.master
 }
+
+// Ping checks that the database is reachable
+func (g *gormPostgresImpl) Ping(ctx context.Context) error {
+	sqlDB, err := g.master.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats reports the state of the connection pool
+func (g *gormPostgresImpl) Stats() (sql.DBStats, error) {
+	sqlDB, err := g.master.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDB.Stats(), nil
+}
+
+// Close releases the underlying connection pool
+func (g *gormPostgresImpl) Close() error {
+	sqlDB, err := g.master.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
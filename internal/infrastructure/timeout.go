@@ -0,0 +1,81 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const timeoutCancelKey = "timeout:cancel"
+
+type noTimeoutKey struct{}
+
+// WithoutQueryTimeout lifts the statement timeout for work that is
+// expected to run long, such as migrations
+func WithoutQueryTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noTimeoutKey{}, true)
+}
+
+// registerQueryTimeout bounds every statement to timeout on top of the
+// deadline already carried by the request context. A statement cut short
+// fails with an error matching context.DeadlineExceeded whatever the
+// driver reports
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	before := func(tx *gorm.DB) {
+		if skip, _ := tx.Statement.Context.Value(noTimeoutKey{}).(bool); skip {
+			return
+		}
+		ctx, cancel := context.WithTimeout(tx.Statement.Context, timeout)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(timeoutCancelKey, cancel)
+	}
+	after := func(tx *gorm.DB) {
+		if tx.Error != nil && errors.Is(tx.Statement.Context.Err(), context.DeadlineExceeded) && !errors.Is(tx.Error, context.DeadlineExceeded) {
+			tx.Error = fmt.Errorf("%w: %w", context.DeadlineExceeded, tx.Error)
+		}
+		if cancel, ok := tx.InstanceGet(timeoutCancelKey); ok {
+			cancel.(context.CancelFunc)()
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:begin_transaction").Register("timeout:before_create", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:commit_or_rollback_transaction").Register("timeout:after_create", after); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("timeout:before_query", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:after_query").Register("timeout:after_query", after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:begin_transaction").Register("timeout:before_update", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:commit_or_rollback_transaction").Register("timeout:after_update", after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:begin_transaction").Register("timeout:before_delete", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:commit_or_rollback_transaction").Register("timeout:after_delete", after); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("timeout:before_raw", before); err != nil {
+		return err
+	}
+	if err := cb.Raw().After("gorm:raw").Register("timeout:after_raw", after); err != nil {
+		return err
+	}
+	// Rows() hands the open cursor back to the caller, so the context can't be
+	// cancelled here, it is released once the timeout fires
+	return cb.Row().Before("gorm:row").Register("timeout:before_row", before)
+}
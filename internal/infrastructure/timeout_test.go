@@ -0,0 +1,72 @@
+package infrastructure
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newTimeoutGorm(t *testing.T, timeout time.Duration) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.Nil(t, err)
+	assert.Nil(t, registerQueryTimeout(db, timeout))
+	return db, mock
+}
+
+func TestQueryTimeout(t *testing.T) {
+	t.Run("error slow query is cut at the timeout", func(t *testing.T) {
+		db, mock := newTimeoutGorm(t, 20*time.Millisecond)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		start := time.Now()
+		var rows []map[string]any
+		err := db.WithContext(context.Background()).Table("users").Find(&rows).Error
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("success fast query", func(t *testing.T) {
+		db, mock := newTimeoutGorm(t, time.Second)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		var rows []map[string]any
+		err := db.WithContext(context.Background()).Table("users").Find(&rows).Error
+		assert.Nil(t, err)
+		assert.Len(t, rows, 1)
+	})
+
+	t.Run("success slow query without timeout", func(t *testing.T) {
+		db, mock := newTimeoutGorm(t, 20*time.Millisecond)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+			WillDelayFor(50 * time.Millisecond).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		var rows []map[string]any
+		err := db.WithContext(WithoutQueryTimeout(context.Background())).Table("users").Find(&rows).Error
+		assert.Nil(t, err)
+	})
+
+	t.Run("error request already cancelled", func(t *testing.T) {
+		db, mock := newTimeoutGorm(t, time.Second)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).
+			WillDelayFor(time.Second).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var rows []map[string]any
+		err := db.WithContext(ctx).Table("users").Find(&rows).Error
+		assert.NotNil(t, err)
+	})
+}
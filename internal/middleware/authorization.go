@@ -1,49 +1,136 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"math"
 	"net/http"
 	"strings"
 
+	"go-mygram/internal/model"
 	"go-mygram/pkg"
 	"go-mygram/pkg/helper"
+	"go-mygram/pkg/tokenstore"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	CLAIM_USER_ID  = "claim_user_id"
 	CLAIM_USERNAME = "claim_username"
+	CLAIM_JTI      = "claim_jti"
+	CLAIM_EXP      = "claim_exp"
+	// not token claims, they are read from the user loaded for the request
+	// so a demoted admin loses access before the token expires
+	CLAIM_EMAIL_VERIFIED = "claim_email_verified"
+	CLAIM_ROLE           = "claim_role"
 )
 
-func CheckAuthBearer(ctx *gin.Context) {
+type AuthMiddleware interface {
+	CheckAuthBearer(ctx *gin.Context)
+}
+
+// UserFinder looks up the account behind a token, it is satisfied by
+// repository.UserQuery which skips soft deleted users
+type UserFinder interface {
+	GetUsersByID(ctx context.Context, id uint64) (model.User, error)
+}
+
+type authMiddlewareImpl struct {
+	jwt        helper.JWTManager
+	tokenStore tokenstore.Store
+	users      UserFinder
+}
+
+func NewAuthMiddleware(jwt helper.JWTManager, tokenStore tokenstore.Store, users UserFinder) AuthMiddleware {
+	return &authMiddlewareImpl{jwt: jwt, tokenStore: tokenStore, users: users}
+}
+
+func (a *authMiddlewareImpl) CheckAuthBearer(ctx *gin.Context) {
 	auth := ctx.GetHeader("Authorization")
 
 	authArr := strings.Split(auth, " ")
 	if len(authArr) < 2 {
-		ctx.AbortWithStatusJSON(http.StatusUnauthorized, pkg.ErrorResponse{
-			Message: "unauthorized",
-			Errors:  []string{"invalid token"},
-		})
+		pkg.AbortWithError(ctx, http.StatusUnauthorized, "unauthorized", "invalid token")
 		return
 	}
 	if authArr[0] != "Bearer" {
-		ctx.AbortWithStatusJSON(http.StatusUnauthorized, pkg.ErrorResponse{
-			Message: "unauthorized",
-			Errors:  []string{"invalid authorization method"},
-		})
+		pkg.AbortWithError(ctx, http.StatusUnauthorized, "unauthorized", "invalid authorization method")
 		return
 	}
 
 	token := authArr[1]
-	claims, err := helper.ValidateToken(token)
+	claims, err := a.jwt.ValidateToken(token)
+	if errors.Is(err, helper.ErrTokenExpired) {
+		pkg.AbortWithErrorCode(ctx, http.StatusUnauthorized, pkg.CodeTokenExpired, "unauthorized", "token has expired")
+		return
+	}
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusUnauthorized, pkg.ErrorResponse{
-			Message: "unauthorized",
-			Errors:  []string{"invalid token", "failed to decode"},
-		})
+		pkg.AbortWithErrorCode(ctx, http.StatusUnauthorized, pkg.CodeInvalidToken, "unauthorized", "invalid token", "failed to decode")
+		return
+	}
+	// the jwt library only checks exp when it is present
+	if _, ok := claims["exp"].(float64); !ok {
+		pkg.AbortWithError(ctx, http.StatusUnauthorized, "unauthorized", "token has no expiry")
+		return
+	}
+	userID, msg := claimUserID(claims)
+	if msg != "" {
+		pkg.AbortWithError(ctx, http.StatusUnauthorized, "unauthorized", msg)
 		return
 	}
+
+	// reject tokens that were signed out before they expired
+	jti, _ := claims["jti"].(string)
+	revoked, err := a.tokenStore.IsRevoked(ctx, jti)
+	if err != nil {
+		pkg.AbortWithError(ctx, http.StatusInternalServerError, "failed to check token")
+		return
+	}
+	if revoked {
+		pkg.AbortWithErrorCode(ctx, http.StatusUnauthorized, pkg.CodeTokenRevoked, "unauthorized", "token has been revoked")
+		return
+	}
+
+	// a token outlives the account it was issued for when the user is deleted
+	user, err := a.users.GetUsersByID(ctx, userID)
+	if err != nil {
+		pkg.AbortWithError(ctx, http.StatusInternalServerError, "failed to check user")
+		return
+	}
+	if user.ID == 0 {
+		pkg.AbortWithError(ctx, http.StatusUnauthorized, "unauthorized", "user no longer exists")
+		return
+	}
+
 	ctx.Set(CLAIM_USER_ID, claims["user_id"])
 	ctx.Set(CLAIM_USERNAME, claims["username"])
+	ctx.Set(CLAIM_JTI, jti)
+	ctx.Set(CLAIM_EXP, claims["exp"])
+	ctx.Set(CLAIM_EMAIL_VERIFIED, user.EmailVerified)
+	ctx.Set(CLAIM_ROLE, user.Role)
 	ctx.Next()
 }
+
+// hasBearer reports whether the request authenticates with a bearer token
+// rather than a cookie, see CSRF
+func hasBearer(ctx *gin.Context) bool {
+	scheme, token, ok := strings.Cut(ctx.GetHeader("Authorization"), " ")
+	return ok && scheme == "Bearer" && token != ""
+}
+
+// claimUserID returns the user_id claim, or a message explaining why the
+// claim can't be used
+func claimUserID(claims jwt.MapClaims) (uint64, string) {
+	raw, ok := claims["user_id"]
+	if !ok {
+		return 0, "missing user_id claim"
+	}
+	// json numbers decode as float64, anything else is not an id
+	id, ok := raw.(float64)
+	if !ok || id < 1 || id != math.Trunc(id) {
+		return 0, "invalid user_id claim"
+	}
+	return uint64(id), ""
+}
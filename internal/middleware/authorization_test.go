@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository/mocks"
+	"go-mygram/pkg"
+	"go-mygram/pkg/helper"
+	"go-mygram/pkg/tokenstore"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newJWTManager(t *testing.T) helper.JWTManager {
+	manager, err := helper.NewJWTManager(helper.JWTConfig{Algorithm: "HS256", Secret: "test-secret", Issuer: "go-mygram", Audience: "go-mygram-api"})
+	assert.Nil(t, err)
+	return manager
+}
+
+func newAccessToken(t *testing.T, jti string) string {
+	now := time.Now()
+	token, err := newJWTManager(t).GenerateToken(model.AccessClaim{
+		StandardClaim: model.StandardClaim{
+			Jti: jti,
+			Exp: uint64(now.Add(time.Hour).Unix()),
+			Iat: uint64(now.Unix()),
+			Nbf: uint64(now.Unix()),
+		},
+		UserID:   1,
+		Username: "user1",
+	})
+	assert.Nil(t, err)
+	return token
+}
+
+func doAuthRequest(auth AuthMiddleware, token string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+
+	rec := httptest.NewRecorder()
+	_, r := gin.CreateTestContext(rec)
+	r.GET("/protected", auth.CheckAuthBearer, func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+// activeUsers returns a user finder that knows user 1 from newAccessToken
+func activeUsers(t *testing.T) *mocks.UserQuery {
+	users := mocks.NewUserQuery(t)
+	users.On("GetUsersByID", mock.Anything, uint64(1)).Return(model.User{ID: 1, Username: "user1"}, nil).Maybe()
+	return users
+}
+
+func TestCheckAuthBearer(t *testing.T) {
+	t.Run("success valid token", func(t *testing.T) {
+		auth := NewAuthMiddleware(newJWTManager(t), tokenstore.NewMemoryStore(), activeUsers(t))
+		rec := doAuthRequest(auth, newAccessToken(t, "jti-1"))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("error revoked token", func(t *testing.T) {
+		store := tokenstore.NewMemoryStore()
+		auth := NewAuthMiddleware(newJWTManager(t), store, activeUsers(t))
+		token := newAccessToken(t, "jti-2")
+
+		err := store.Revoke(context.Background(), "jti-2", time.Now().Add(time.Hour))
+		assert.Nil(t, err)
+
+		rec := doAuthRequest(auth, token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("error soft deleted user", func(t *testing.T) {
+		users := mocks.NewUserQuery(t)
+		// soft deleted rows are filtered out, so the lookup comes back empty
+		users.On("GetUsersByID", mock.Anything, uint64(1)).Return(model.User{}, nil).Once()
+		auth := NewAuthMiddleware(newJWTManager(t), tokenstore.NewMemoryStore(), users)
+
+		rec := doAuthRequest(auth, newAccessToken(t, "jti-3"))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestCheckAuthBearerRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doAdminRequest := func(role string) int {
+		users := mocks.NewUserQuery(t)
+		users.On("GetUsersByID", mock.Anything, uint64(1)).Return(model.User{ID: 1, Username: "user1", Role: role}, nil).Once()
+		auth := NewAuthMiddleware(newJWTManager(t), tokenstore.NewMemoryStore(), users)
+
+		rec := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(rec)
+		r.GET("/admin", auth.CheckAuthBearer, RequireRole(model.RoleAdmin), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+newAccessToken(t, "jti-role-"+role))
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, doAdminRequest(model.RoleAdmin))
+	assert.Equal(t, http.StatusForbidden, doAdminRequest(model.RoleUser))
+}
+
+func TestCheckAuthBearerCraftedClaims(t *testing.T) {
+	now := time.Now()
+	exp := now.Add(time.Hour).Unix()
+
+	testCases := []struct {
+		desc   string
+		claims map[string]any
+		reason string
+	}{
+		{
+			desc:   "error missing user_id",
+			claims: map[string]any{"jti": "jti-a", "exp": exp},
+			reason: "missing user_id claim",
+		},
+		{
+			desc:   "error non numeric user_id",
+			claims: map[string]any{"jti": "jti-b", "exp": exp, "user_id": "1"},
+			reason: "invalid user_id claim",
+		},
+		{
+			desc:   "error fractional user_id",
+			claims: map[string]any{"jti": "jti-c", "exp": exp, "user_id": 1.5},
+			reason: "invalid user_id claim",
+		},
+		{
+			desc:   "error zero user_id",
+			claims: map[string]any{"jti": "jti-d", "exp": exp, "user_id": 0},
+			reason: "invalid user_id claim",
+		},
+		{
+			desc:   "error expired token",
+			claims: map[string]any{"jti": "jti-e", "exp": now.Add(-time.Minute).Unix(), "user_id": 1},
+			reason: "token has expired",
+		},
+		{
+			desc:   "error missing exp",
+			claims: map[string]any{"jti": "jti-f", "user_id": 1},
+			reason: "token has no expiry",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			manager := newJWTManager(t)
+			token, err := manager.GenerateToken(tC.claims)
+			assert.Nil(t, err)
+
+			// the user lookup must never be reached
+			auth := NewAuthMiddleware(manager, tokenstore.NewMemoryStore(), mocks.NewUserQuery(t))
+			rec := doAuthRequest(auth, token)
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			var body pkg.ErrorResponse
+			assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, []string{tC.reason}, body.Errors)
+		})
+	}
+}
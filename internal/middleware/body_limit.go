@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rawBodyKey keeps the unlimited request body so a route can replace the
+// global limit instead of nesting a second one inside it
+const rawBodyKey = "raw_request_body"
+
+// BodyLimit rejects request bodies over limit bytes with a 413, before
+// anything is read when Content-Length already tells. Bodies without a
+// length are cut off while reading, pkg.WriteBindError turns that into the
+// same 413. Used on the engine for the default and again on upload routes,
+// the last one applied wins
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+			ctx.Next()
+			return
+		}
+		if ctx.Request.ContentLength > limit {
+			pkg.AbortWithError(ctx, http.StatusRequestEntityTooLarge, "request body is too large", fmt.Sprintf("must be at most %d bytes", limit))
+			return
+		}
+
+		raw, ok := ctx.Value(rawBodyKey).(io.ReadCloser)
+		if !ok {
+			raw = ctx.Request.Body
+			ctx.Set(rawBodyKey, raw)
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, raw, limit)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBodyLimitRouter(limit int64, route ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(BodyLimit(limit))
+	handlers := append(route, func(ctx *gin.Context) {
+		var body map[string]string
+		if err := ctx.ShouldBindJSON(&body); err != nil {
+			pkg.WriteBindError(ctx, err)
+			return
+		}
+		ctx.Status(http.StatusOK)
+	})
+	r.POST("/", handlers...)
+	return r
+}
+
+// unsized hides the length of the body, like a chunked upload
+type unsized struct{ io.Reader }
+
+func TestBodyLimit(t *testing.T) {
+	body := `{"message":"` + strings.Repeat("a", 50) + `"}`
+
+	testCases := []struct {
+		desc   string
+		limit  int64
+		route  []gin.HandlerFunc
+		sized  bool
+		status int
+	}{
+		{desc: "success under the limit", limit: 100, sized: true, status: http.StatusOK},
+		{desc: "error content length over the limit", limit: 10, sized: true, status: http.StatusRequestEntityTooLarge},
+		{desc: "error unsized body over the limit", limit: 10, status: http.StatusRequestEntityTooLarge},
+		{desc: "success route raises the limit", limit: 10, route: []gin.HandlerFunc{BodyLimit(100)}, status: http.StatusOK},
+		{desc: "error route lowers the limit", limit: 100, route: []gin.HandlerFunc{BodyLimit(10)}, status: http.StatusRequestEntityTooLarge},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r := newBodyLimitRouter(tC.limit, tC.route...)
+
+			var req *http.Request
+			if tC.sized {
+				req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			} else {
+				req = httptest.NewRequest(http.MethodPost, "/", unsized{strings.NewReader(body)})
+			}
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tC.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// TrustProxies makes ctx.ClientIP, which the rate limiters and the request
+// logger key on, honour X-Forwarded-For only for requests sent by one of
+// proxies. Anyone else is identified by RemoteAddr, so a client can't pick
+// its own ip. proxies are ips or CIDRs, none trusts nobody
+func TrustProxies(g *gin.Engine, proxies []string) error {
+	if len(proxies) == 0 {
+		proxies = nil
+	}
+	return g.SetTrustedProxies(proxies)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustProxies(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		proxies    []string
+		remoteAddr string
+		ip         string
+	}{
+		{desc: "success forwarded by trusted proxy", proxies: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.5:1234", ip: "203.0.113.7"},
+		{desc: "success trusted proxy by ip", proxies: []string{"10.0.0.5"}, remoteAddr: "10.0.0.5:1234", ip: "203.0.113.7"},
+		{desc: "success untrusted sender uses remote addr", proxies: []string{"10.0.0.0/8"}, remoteAddr: "192.0.2.1:1234", ip: "192.0.2.1"},
+		{desc: "success no proxies trusts nobody", remoteAddr: "10.0.0.5:1234", ip: "10.0.0.5"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			assert.Nil(t, TrustProxies(r, tC.proxies))
+			var ip string
+			r.GET("/", func(ctx *gin.Context) {
+				ip = ctx.ClientIP()
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tC.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tC.ip, ip)
+		})
+	}
+
+	t.Run("error malformed proxy", func(t *testing.T) {
+		assert.NotNil(t, TrustProxies(gin.New(), []string{"not-an-ip"}))
+	})
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mygram/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat("a", 2048)
+	doRequest := func(acceptEncoding, body, contentType string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(rec)
+		r.Use(Compress(config.CompressionConfig{Enabled: true, MinBytes: 1024}))
+		r.GET("/feed", func(ctx *gin.Context) {
+			ctx.Header("ETag", `"abc"`)
+			ctx.Data(http.StatusOK, contentType, []byte(body))
+		})
+		req := httptest.NewRequest(http.MethodGet, "/feed", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("success large json is compressed", func(t *testing.T) {
+		rec := doRequest("gzip, deflate", large, "application/json")
+
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `W/"abc"`, rec.Header().Get("ETag"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+		gz, err := gzip.NewReader(rec.Body)
+		assert.Nil(t, err)
+		plain, err := io.ReadAll(gz)
+		assert.Nil(t, err)
+		assert.Equal(t, large, string(plain))
+	})
+
+	t.Run("success small body is sent as is", func(t *testing.T) {
+		rec := doRequest("gzip", `{"id":1}`, "application/json")
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"id":1}`, rec.Body.String())
+	})
+
+	t.Run("success client without gzip", func(t *testing.T) {
+		for _, accept := range []string{"", "br", "gzip;q=0"} {
+			rec := doRequest(accept, large, "application/json")
+			assert.Empty(t, rec.Header().Get("Content-Encoding"))
+			assert.Equal(t, large, rec.Body.String())
+		}
+	})
+
+	t.Run("success images are not compressed", func(t *testing.T) {
+		rec := doRequest("gzip", large, "image/png")
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, rec.Body.String())
+	})
+}
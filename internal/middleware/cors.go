@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-mygram/internal/config"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORS answers preflight requests and tags responses for allowed origins,
+// requests from any other origin get no CORS headers so browsers block them
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	allowAll := false
+	origins := make(map[string]struct{}, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		origins[strings.ToLower(origin)] = struct{}{}
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+		ctx.Header("Vary", "Origin")
+
+		_, ok := origins[strings.ToLower(origin)]
+		preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+		if !ok && !allowAll {
+			if preflight {
+				pkg.AbortWithError(ctx, http.StatusForbidden, "origin not allowed")
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		// echo the origin rather than "*", browsers reject a wildcard on
+		// credentialed requests
+		ctx.Header("Access-Control-Allow-Origin", origin)
+		if cfg.AllowCredentials {
+			ctx.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			ctx.Header("Access-Control-Expose-Headers", pkg.RequestIDHeader)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("Access-Control-Allow-Methods", methods)
+		ctx.Header("Access-Control-Allow-Headers", headers)
+		ctx.Header("Access-Control-Max-Age", maxAge)
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"go-mygram/internal/config"
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRF implements the double submit cookie pattern. A safe request without
+// the cookie gets a fresh random token in it, the web client reads it and
+// sends it back in cfg.HeaderName with every unsafe request, which is
+// rejected with a 403 when the two don't match.
+//
+// It guards every POST, PUT, PATCH and DELETE of the routes it is mounted
+// on, sign up and sign in included since a forged sign in is a CSRF too.
+// Requests with a bearer token skip the check, a browser never attaches
+// one on its own
+func CSRF(cfg config.CSRFConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if hasBearer(ctx) {
+			ctx.Next()
+			return
+		}
+
+		cookie, _ := ctx.Cookie(cfg.CookieName)
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if cookie == "" {
+				if err := setCSRFCookie(ctx, cfg); err != nil {
+					pkg.AbortWithError(ctx, http.StatusInternalServerError, "failed to issue csrf token")
+					return
+				}
+			}
+			ctx.Next()
+			return
+		}
+
+		header := ctx.GetHeader(cfg.HeaderName)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			pkg.AbortWithError(ctx, http.StatusForbidden, "invalid csrf token", "send the "+cfg.CookieName+" cookie value in the "+cfg.HeaderName+" header")
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func setCSRFCookie(ctx *gin.Context, cfg config.CSRFConfig) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	// not HttpOnly, the client has to read it to echo it in the header
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(cfg.CookieName, hex.EncodeToString(b), 0, "/", "", cfg.CookieSecure, false)
+	return nil
+}
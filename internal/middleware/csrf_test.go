@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCSRFRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CSRF(config.CSRFConfig{Enabled: true, CookieName: "csrf_token", HeaderName: "X-CSRF-Token"}))
+	r.GET("/photos", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.POST("/photos", func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+	return r
+}
+
+func TestCSRF(t *testing.T) {
+	t.Run("success safe request gets a token cookie", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newCSRFRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/photos", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		cookies := rec.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.Equal(t, "csrf_token", cookies[0].Name)
+		assert.Len(t, cookies[0].Value, 64)
+		assert.False(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	})
+
+	t.Run("success safe request keeps an existing token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/photos", nil)
+		req.AddCookie(&http.Cookie{Name: "csrf_token", Value: "abc"})
+		rec := httptest.NewRecorder()
+		newCSRFRouter().ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Result().Cookies())
+	})
+
+	testCases := []struct {
+		desc   string
+		cookie string
+		header string
+		bearer bool
+		status int
+	}{
+		{desc: "success matching header", cookie: "abc", header: "abc", status: http.StatusCreated},
+		{desc: "success bearer token skips the check", bearer: true, status: http.StatusCreated},
+		{desc: "error missing header", cookie: "abc", status: http.StatusForbidden},
+		{desc: "error mismatched header", cookie: "abc", header: "abd", status: http.StatusForbidden},
+		{desc: "error missing cookie", header: "abc", status: http.StatusForbidden},
+		{desc: "error both empty", status: http.StatusForbidden},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/photos", nil)
+			if tC.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "csrf_token", Value: tC.cookie})
+			}
+			if tC.header != "" {
+				req.Header.Set("X-CSRF-Token", tC.header)
+			}
+			if tC.bearer {
+				req.Header.Set("Authorization", "Bearer some.jwt.token")
+			}
+			rec := httptest.NewRecorder()
+			newCSRFRouter().ServeHTTP(rec, req)
+
+			assert.Equal(t, tC.status, rec.Code)
+		})
+	}
+}
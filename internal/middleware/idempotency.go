@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go-mygram/pkg"
+	"go-mygram/pkg/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders are copied from the first response into a replay
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// Idempotency replays the saved response when a request repeats the
+// Idempotency-Key of an earlier one, requests without the header are
+// handled as usual. It must run after CheckAuthBearer, keys are scoped to
+// the user and route so they can't replay someone else's response. A key
+// is held for lockTTL while its request runs and its response is replayed
+// for ttl
+func Idempotency(store idempotency.Store, ttl, lockTTL time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			pkg.AbortWithError(ctx, http.StatusBadRequest, "invalid idempotency key", fmt.Sprintf("must be at most %d characters", maxIdempotencyKeyLength))
+			return
+		}
+
+		var body []byte
+		if ctx.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(ctx.Request.Body)
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				pkg.AbortWithError(ctx, http.StatusRequestEntityTooLarge, "request body is too large", fmt.Sprintf("must be at most %d bytes", maxErr.Limit))
+				return
+			}
+			if err != nil {
+				pkg.AbortWithError(ctx, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		// the key is scoped to the route pattern, the path tells apart the
+		// resources under it so a key reused on another one gets a 422
+		h := sha256.New()
+		h.Write([]byte(ctx.Request.URL.Path + "\n"))
+		h.Write(body)
+		fingerprint := hex.EncodeToString(h.Sum(nil))
+
+		userID, _ := ctx.Value(CLAIM_USER_ID).(float64)
+		storeKey := fmt.Sprintf("%d:%s %s:%s", uint64(userID), ctx.Request.Method, ctx.FullPath(), key)
+
+		saved, err := store.Start(ctx, storeKey, fingerprint, lockTTL)
+		switch {
+		case errors.Is(err, idempotency.ErrInProgress):
+			pkg.AbortWithError(ctx, http.StatusConflict, err.Error())
+			return
+		case errors.Is(err, idempotency.ErrFingerprintMismatch):
+			pkg.AbortWithError(ctx, http.StatusUnprocessableEntity, err.Error())
+			return
+		case err != nil:
+			pkg.AbortWithError(ctx, http.StatusInternalServerError, "failed to check idempotency key")
+			return
+		case saved != nil:
+			for _, name := range replayedHeaders {
+				if v := saved.Header.Get(name); v != "" {
+					ctx.Header(name, v)
+				}
+			}
+			ctx.Header("Idempotent-Replayed", "true")
+			ctx.Data(saved.Status, saved.Header.Get("Content-Type"), saved.Body)
+			ctx.Abort()
+			return
+		}
+
+		// a panic or a server error is worth retrying, the key is freed for
+		// that. The panic goes on to Recovery
+		finished := false
+		defer func() {
+			if finished {
+				return
+			}
+			_ = store.Release(ctx, storeKey)
+			if rec := recover(); rec != nil {
+				panic(rec)
+			}
+		}()
+
+		rec := &bodyRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = rec
+		ctx.Next()
+
+		if rec.Status() >= http.StatusInternalServerError {
+			return
+		}
+		finished = true
+		header := http.Header{}
+		for _, name := range replayedHeaders {
+			if v := rec.Header().Get(name); v != "" {
+				header.Set(name, v)
+			}
+		}
+		if err := store.Finish(ctx, storeKey, idempotency.Response{Status: rec.Status(), Header: header, Body: rec.body.Bytes()}, ttl); err != nil {
+			_ = ctx.Error(err)
+		}
+	}
+}
+
+// bodyRecorder keeps a copy of what the handler writes
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *bodyRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-mygram/pkg/idempotency"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(created *int, status int) *gin.Engine {
+		r := gin.New()
+		r.POST("/photos", func(ctx *gin.Context) {
+			ctx.Set(CLAIM_USER_ID, float64(7))
+		}, Idempotency(idempotency.NewMemoryStore(), time.Hour, time.Minute), func(ctx *gin.Context) {
+			*created++
+			ctx.Header("Location", "/photos/1")
+			ctx.JSON(status, gin.H{"id": *created})
+		})
+		return r
+	}
+	doRequest := func(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/photos", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("success repeat replays the first response", func(t *testing.T) {
+		created := 0
+		r := newRouter(&created, http.StatusCreated)
+
+		first := doRequest(r, "key-1", `{"title":"a"}`)
+		second := doRequest(r, "key-1", `{"title":"a"}`)
+
+		assert.Equal(t, 1, created)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "/photos/1", second.Header().Get("Location"))
+		assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("success without key every request is handled", func(t *testing.T) {
+		created := 0
+		r := newRouter(&created, http.StatusCreated)
+
+		doRequest(r, "", `{"title":"a"}`)
+		doRequest(r, "", `{"title":"a"}`)
+		assert.Equal(t, 2, created)
+	})
+
+	t.Run("error key reused with another body", func(t *testing.T) {
+		created := 0
+		r := newRouter(&created, http.StatusCreated)
+
+		doRequest(r, "key-1", `{"title":"a"}`)
+		rec := doRequest(r, "key-1", `{"title":"b"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, 1, created)
+	})
+
+	t.Run("success server error can be retried", func(t *testing.T) {
+		created := 0
+		r := newRouter(&created, http.StatusInternalServerError)
+
+		doRequest(r, "key-1", `{"title":"a"}`)
+		doRequest(r, "key-1", `{"title":"a"}`)
+		assert.Equal(t, 2, created)
+	})
+}
+
+func TestIdempotencyNestedRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	created := 0
+	r := gin.New()
+	r.POST("/photos/:id/comments", func(ctx *gin.Context) {
+		ctx.Set(CLAIM_USER_ID, float64(7))
+	}, Idempotency(idempotency.NewMemoryStore(), time.Hour, time.Minute), func(ctx *gin.Context) {
+		created++
+		ctx.JSON(http.StatusCreated, gin.H{"photo_id": ctx.Param("id")})
+	})
+	doRequest := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"message":"hi"}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusCreated, doRequest("/photos/1/comments").Code)
+	// same key and body on another photo must not replay the comment on 1
+	rec := doRequest("/photos/2/comments")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, 1, created)
+
+	rec = doRequest("/photos/1/comments")
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, 1, created)
+}
+
+func TestIdempotencyPanicReleasesKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(ctx *gin.Context, _ any) {
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.POST("/photos", Idempotency(idempotency.NewMemoryStore(), time.Hour, time.Minute), func(ctx *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		ctx.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+	doRequest := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/photos", strings.NewReader(`{"title":"a"}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, doRequest().Code)
+	assert.Equal(t, http.StatusCreated, doRequest().Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := idempotency.NewMemoryStore()
+	_, err := store.Start(context.Background(), "k", "f", time.Hour)
+	assert.Nil(t, err)
+
+	_, err = store.Start(context.Background(), "k", "f", time.Hour)
+	assert.ErrorIs(t, err, idempotency.ErrInProgress)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger writes one structured line per request, server errors are
+// logged at error level together with the errors recorded on the context
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("request_id", pkg.RequestID(ctx)),
+			slog.String("client_ip", ctx.ClientIP()),
+		}
+		// set by CheckAuthBearer on authenticated routes
+		if userID, ok := ctx.Value(CLAIM_USER_ID).(float64); ok {
+			attrs = append(attrs, slog.Uint64("user_id", uint64(userID)))
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.Any("errors", ctx.Errors.Errors()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx.Request.Context(), level, "request", attrs...)
+	}
+}
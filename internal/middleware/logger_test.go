@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/pkg"
+	"go-mygram/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	r := gin.New()
+	r.Use(RequestID(), RequestLogger(logger.New(&buf, "info")))
+	r.GET("/photos", func(ctx *gin.Context) {
+		ctx.Set(CLAIM_USER_ID, float64(7))
+		_ = ctx.Error(errors.New("connection refused"))
+		pkg.WriteError(ctx, http.StatusInternalServerError, "failed to get photos")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/photos", nil)
+	req.Header.Set(pkg.RequestIDHeader, "trace-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "ERROR", line["level"])
+	assert.Equal(t, "GET", line["method"])
+	assert.Equal(t, "/photos", line["path"])
+	assert.Equal(t, float64(500), line["status"])
+	assert.Equal(t, "trace-1", line["request_id"])
+	assert.Equal(t, float64(7), line["user_id"])
+	assert.Equal(t, []any{"connection refused", "failed to get photos"}, line["errors"])
+	assert.Contains(t, line, "latency")
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"go-mygram/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request count, latency and in-flight requests. The route
+// label is the registered pattern so ids in the path don't blow up the
+// label cardinality
+func Metrics() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		metrics.RequestsInFlight.Inc()
+		defer metrics.RequestsInFlight.Dec()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(ctx.Writer.Status())
+		metrics.RequestsTotal.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		metrics.RequestDuration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
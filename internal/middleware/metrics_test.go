@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(Metrics())
+	r.GET("/photos/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	before := testutil.ToFloat64(metrics.RequestsTotal.WithLabelValues("GET", "/photos/:id", "200"))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/photos/1", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/photos/2", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	assert.Equal(t, before+2, testutil.ToFloat64(metrics.RequestsTotal.WithLabelValues("GET", "/photos/:id", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.RequestsTotal.WithLabelValues("GET", "unmatched", "404")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.RequestsInFlight))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `http_request_duration_seconds_bucket{method="GET",route="/photos/:id",status="200",le="0.005"}`)
+}
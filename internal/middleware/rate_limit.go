@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-mygram/pkg"
+	"go-mygram/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitSignIn throttles sign in attempts per client ip and per submitted
+// email, a successful sign in clears the counter of that email. A body over
+// maxPeekBytes is refused with a 413, padding it must not skip the email bucket
+func RateLimitSignIn(limiter ratelimit.Limiter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		email, ok := peekEmail(ctx)
+		if !ok {
+			pkg.AbortWithError(ctx, http.StatusRequestEntityTooLarge, "request body is too large", fmt.Sprintf("must be at most %d bytes", maxPeekBytes))
+			return
+		}
+		keys := []string{"signin:ip:" + ctx.ClientIP()}
+		emailKey := ""
+		if email != "" {
+			emailKey = "signin:email:" + email
+			keys = append(keys, emailKey)
+		}
+
+		var retryAfter time.Duration
+		for _, key := range keys {
+			allowed, wait, err := limiter.Allow(ctx, key)
+			if err != nil {
+				pkg.AbortWithError(ctx, http.StatusInternalServerError, "failed to check rate limit")
+				return
+			}
+			if !allowed && wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+		if retryAfter > 0 {
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			pkg.AbortWithError(ctx, http.StatusTooManyRequests, "too many sign in attempts")
+			return
+		}
+
+		ctx.Next()
+
+		if emailKey != "" && ctx.Writer.Status() == http.StatusOK {
+			_ = limiter.Reset(ctx, emailKey)
+		}
+	}
+}
+
+// RateLimitByIP throttles requests per client ip, prefix keeps the buckets
+// of different endpoints apart
+func RateLimitByIP(limiter ratelimit.Limiter, prefix string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		allowKey(ctx, limiter, prefix+":ip:"+ctx.ClientIP())
+	}
+}
+
+// RateLimitByUser throttles requests per signed in user, it must run after
+// CheckAuthBearer. Requests without a user fall back to the client ip
+func RateLimitByUser(limiter ratelimit.Limiter, prefix string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := prefix + ":ip:" + ctx.ClientIP()
+		if userID, ok := ctx.Value(CLAIM_USER_ID).(float64); ok {
+			key = prefix + ":user:" + strconv.FormatUint(uint64(userID), 10)
+		}
+		allowKey(ctx, limiter, key)
+	}
+}
+
+// allowKey lets the request through or aborts it with a Retry-After once
+// the bucket of key is empty
+func allowKey(ctx *gin.Context, limiter ratelimit.Limiter, key string) {
+	allowed, wait, err := limiter.Allow(ctx, key)
+	if err != nil {
+		pkg.AbortWithError(ctx, http.StatusInternalServerError, "failed to check rate limit")
+		return
+	}
+	if !allowed {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		pkg.AbortWithError(ctx, http.StatusTooManyRequests, "too many requests")
+		return
+	}
+	ctx.Next()
+}
+
+// maxPeekBytes bounds what peekEmail reads, a sign in body is far smaller
+const maxPeekBytes = 4 << 10
+
+// peekEmail reads the email from a json body and puts the body back so the
+// handler can still bind it. ok is false for a body over maxPeekBytes
+func peekEmail(ctx *gin.Context) (email string, ok bool) {
+	if ctx.Request.Body == nil {
+		return "", true
+	}
+	if ctx.Request.ContentLength > maxPeekBytes {
+		return "", false
+	}
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxPeekBytes+1))
+	ctx.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), ctx.Request.Body), ctx.Request.Body}
+	if len(body) > maxPeekBytes {
+		return "", false
+	}
+	if err != nil {
+		return "", true
+	}
+
+	var payload struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", true
+	}
+	return strings.ToLower(strings.TrimSpace(payload.Email)), true
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-mygram/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSignInRouter(limiter ratelimit.Limiter, status *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/users/login", RateLimitSignIn(limiter), func(ctx *gin.Context) {
+		ctx.Status(*status)
+	})
+	return r
+}
+
+func doSignIn(r *gin.Engine, ip, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewBufferString(body))
+	req.RemoteAddr = ip + ":1234"
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitSignIn(t *testing.T) {
+	t.Run("error too many attempts per email", func(t *testing.T) {
+		status := http.StatusUnauthorized
+		r := newSignInRouter(ratelimit.NewMemoryLimiter(5, time.Minute), &status)
+
+		for i := 0; i < 5; i++ {
+			// different ips so only the email bucket is exhausted
+			rec := doSignIn(r, fmt.Sprintf("10.0.0.%d", i+1), `{"email":"Foo@Example.com"}`)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+
+		rec := doSignIn(r, "10.0.0.9", `{"email":"foo@example.com "}`)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("error too many attempts per ip", func(t *testing.T) {
+		status := http.StatusUnauthorized
+		r := newSignInRouter(ratelimit.NewMemoryLimiter(2, time.Minute), &status)
+
+		doSignIn(r, "10.0.0.1", `{"email":"a@mail.com"}`)
+		doSignIn(r, "10.0.0.1", `{"email":"b@mail.com"}`)
+		rec := doSignIn(r, "10.0.0.1", `{"email":"c@mail.com"}`)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("error padded body still counted per email", func(t *testing.T) {
+		status := http.StatusUnauthorized
+		r := newSignInRouter(ratelimit.NewMemoryLimiter(2, time.Minute), &status)
+		padded := func(n int) string {
+			return `{"email":"a@mail.com","pad":"` + strings.Repeat(" ", n) + `"}`
+		}
+
+		doSignIn(r, "10.0.0.1", padded(0))
+		doSignIn(r, "10.0.0.2", padded(maxPeekBytes-64))
+		rec := doSignIn(r, "10.0.0.3", padded(maxPeekBytes-64))
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+		// a body too big to read the email from never reaches the handler
+		rec = doSignIn(r, "10.0.0.4", padded(maxPeekBytes))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("success sign in resets email counter", func(t *testing.T) {
+		status := http.StatusUnauthorized
+		r := newSignInRouter(ratelimit.NewMemoryLimiter(2, time.Minute), &status)
+
+		doSignIn(r, "10.0.0.1", `{"email":"a@mail.com"}`)
+		status = http.StatusOK
+		doSignIn(r, "10.0.0.2", `{"email":"a@mail.com"}`)
+
+		status = http.StatusUnauthorized
+		rec := doSignIn(r, "10.0.0.3", `{"email":"a@mail.com"}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = doSignIn(r, "10.0.0.4", `{"email":"a@mail.com"}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/users/username-available", RateLimitByIP(ratelimit.NewMemoryLimiter(2, time.Minute), "username-available"), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	doCheck := func(ip string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users/username-available?username=foo", nil)
+		req.RemoteAddr = ip + ":1234"
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, doCheck("10.0.0.1"))
+	assert.Equal(t, http.StatusOK, doCheck("10.0.0.1"))
+	assert.Equal(t, http.StatusTooManyRequests, doCheck("10.0.0.1"))
+	// other clients have their own bucket
+	assert.Equal(t, http.StatusOK, doCheck("10.0.0.2"))
+}
+
+func TestRateLimitByUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// stands in for CheckAuthBearer
+	signedIn := func(ctx *gin.Context) {
+		userID, _ := strconv.ParseFloat(ctx.Query("user"), 64)
+		ctx.Set(CLAIM_USER_ID, userID)
+	}
+	r.GET("/users/search", signedIn, RateLimitByUser(ratelimit.NewMemoryLimiter(2, time.Minute), "user-search"), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	doSearch := func(user string) int {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users/search?prefix=jo&user="+user, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, doSearch("7"))
+	assert.Equal(t, http.StatusOK, doSearch("7"))
+	assert.Equal(t, http.StatusTooManyRequests, doSearch("7"))
+	// other users have their own bucket even behind the same ip
+	assert.Equal(t, http.StatusOK, doSearch("8"))
+}
+
+func TestPeekEmailLargeBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := `{"email":"foo@example.com","pad":"` + strings.Repeat("a", maxPeekBytes) + `"}`
+	rec := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(rec)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/users/login", io.NopCloser(strings.NewReader(body)))
+
+	email, ok := peekEmail(ctx)
+	assert.Equal(t, "", email)
+	assert.False(t, ok)
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panicking handler into a json 500 and logs the stack
+// with the request id. The panic value is only sent to the client when
+// pkg.VerboseErrors is set
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// net/http uses this panic to drop the connection on purpose
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			logger.ErrorContext(ctx.Request.Context(), "panic recovered",
+				slog.String("request_id", pkg.RequestID(ctx)),
+				slog.Any("panic", rec),
+				slog.String("stack", string(debug.Stack())),
+			)
+			// too late for a clean response once the handler started writing
+			if ctx.Writer.Written() {
+				ctx.Abort()
+				return
+			}
+			_ = ctx.Error(fmt.Errorf("panic: %v", rec))
+			resp := pkg.ErrorResponse{Code: pkg.CodeInternal, Message: pkg.ServerErrorMessage, RequestID: pkg.RequestID(ctx), Debug: pkg.NewErrorDebug(fmt.Sprint(rec))}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, resp)
+		}()
+		ctx.Next()
+	}
+}
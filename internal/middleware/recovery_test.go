@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/pkg"
+	"go-mygram/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		verbose bool
+	}{
+		{desc: "success details hidden in production", verbose: false},
+		{desc: "success details shown in development", verbose: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			pkg.VerboseErrors = tC.verbose
+			defer func() { pkg.VerboseErrors = false }()
+
+			var buf bytes.Buffer
+			r := gin.New()
+			r.Use(RequestID(), Recovery(logger.New(&buf, "info")))
+			r.GET("/panic", func(ctx *gin.Context) {
+				panic("nil map write")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+			req.Header.Set(pkg.RequestIDHeader, "trace-1")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+			var resp pkg.ErrorResponse
+			assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, "internal server error", resp.Message)
+			assert.Equal(t, "trace-1", resp.RequestID)
+			if tC.verbose {
+				assert.Equal(t, "nil map write", resp.Debug.Error)
+				assert.NotEmpty(t, resp.Debug.Stack)
+			} else {
+				assert.Nil(t, resp.Debug)
+			}
+
+			var line map[string]any
+			assert.Nil(t, json.Unmarshal(buf.Bytes(), &line))
+			assert.Equal(t, "ERROR", line["level"])
+			assert.Equal(t, "trace-1", line["request_id"])
+			assert.Contains(t, line["stack"], "recovery_test.go")
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"go-mygram/pkg"
+	"go-mygram/pkg/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRequestIDLength bounds ids supplied by clients so they can't flood logs
+const maxRequestIDLength = 128
+
+// RequestID tags every request with an id, reusing a sane incoming
+// X-Request-ID so calls can be traced across services
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(pkg.RequestIDHeader)
+		if !validRequestID(id) {
+			id, _ = helper.GenerateUUID()
+		}
+
+		ctx.Set(pkg.RequestIDKey, id)
+		ctx.Request = ctx.Request.WithContext(pkg.WithRequestID(ctx.Request.Context(), id))
+		ctx.Header(pkg.RequestIDHeader, id)
+		ctx.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		// visible ascii only, anything else could break log lines
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
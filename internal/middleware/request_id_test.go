@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doRequest := func(incoming string) (*httptest.ResponseRecorder, string) {
+		var fromCtx string
+		rec := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(rec)
+		r.GET("/fail", RequestID(), func(ctx *gin.Context) {
+			// services only see the request context
+			fromCtx = pkg.RequestID(ctx.Request.Context())
+			pkg.WriteError(ctx, http.StatusBadRequest, "bad request")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		if incoming != "" {
+			req.Header.Set(pkg.RequestIDHeader, incoming)
+		}
+		r.ServeHTTP(rec, req)
+		return rec, fromCtx
+	}
+
+	t.Run("success generate id", func(t *testing.T) {
+		rec, fromCtx := doRequest("")
+
+		id := rec.Header().Get(pkg.RequestIDHeader)
+		assert.Len(t, id, 36)
+		assert.Equal(t, id, fromCtx)
+
+		var body pkg.ErrorResponse
+		assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, id, body.RequestID)
+	})
+
+	t.Run("success honor incoming id", func(t *testing.T) {
+		rec, fromCtx := doRequest("trace-123")
+
+		assert.Equal(t, "trace-123", rec.Header().Get(pkg.RequestIDHeader))
+		assert.Equal(t, "trace-123", fromCtx)
+	})
+
+	t.Run("success replace malformed incoming id", func(t *testing.T) {
+		rec, _ := doRequest("bad id\twith spaces")
+
+		assert.NotEqual(t, "bad id\twith spaces", rec.Header().Get(pkg.RequestIDHeader))
+		assert.Len(t, rec.Header().Get(pkg.RequestIDHeader), 36)
+	})
+}
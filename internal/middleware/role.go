@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets through users with the given role, it must run
+// after CheckAuthBearer which loads the role from the user
+func RequireRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString(CLAIM_ROLE) != role {
+			pkg.AbortWithError(ctx, http.StatusForbidden, "forbidden", role+" access required")
+			return
+		}
+		ctx.Next()
+	}
+}
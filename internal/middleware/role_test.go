@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-mygram/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doRequest := func(role string) int {
+		rec := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(rec)
+		r.GET("/admin", func(ctx *gin.Context) {
+			ctx.Set(CLAIM_ROLE, role)
+		}, RequireRole(model.RoleAdmin), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, doRequest(model.RoleAdmin))
+	assert.Equal(t, http.StatusForbidden, doRequest(model.RoleUser))
+	assert.Equal(t, http.StatusForbidden, doRequest(""))
+}
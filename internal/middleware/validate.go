@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidateJSON registers T as the schema of the route's json body, a body
+// that doesn't bind into T or breaks its binding tags is rejected with a
+// 400 before the handler runs. The handler binds the body again with
+// pkg.BindJSON or pkg.BindAndValidate
+func ValidateJSON[T any]() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body T
+		if !pkg.BindJSON(ctx, &body) {
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type validateBody struct {
+	Name string `json:"name" binding:"required,max=5"`
+	Age  int    `json:"age" binding:"gte=13"`
+}
+
+func TestValidateJSON(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		body   string
+		status int
+		errs   []pkg.FieldError
+	}{
+		{desc: "success body passes on to the handler", body: `{"name":"bob","age":20}`, status: http.StatusOK},
+		{desc: "error broken tags", body: `{"name":"robert","age":12}`, status: http.StatusBadRequest, errs: []pkg.FieldError{
+			{Field: "name", Message: "name must be at most 5 characters"},
+			{Field: "age", Message: "age must be at least 13"},
+		}},
+		{desc: "error missing field", body: `{"age":20}`, status: http.StatusBadRequest, errs: []pkg.FieldError{
+			{Field: "name", Message: "name is required"},
+		}},
+		{desc: "error malformed json", body: `{"name":`, status: http.StatusBadRequest},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			handled := false
+			r := gin.New()
+			r.POST("/", ValidateJSON[validateBody](), func(ctx *gin.Context) {
+				// the body is bound a second time from the copy kept by the middleware
+				var body validateBody
+				if !pkg.BindJSON(ctx, &body) {
+					return
+				}
+				handled = true
+				ctx.JSON(http.StatusOK, body)
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tC.body)))
+
+			assert.Equal(t, tC.status, rec.Code)
+			assert.Equal(t, tC.status == http.StatusOK, handled)
+			if tC.status == http.StatusOK {
+				assert.JSONEq(t, tC.body, rec.Body.String())
+			}
+			if tC.errs != nil {
+				var resp pkg.ValidationErrorResponse
+				assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tC.errs, resp.Errors)
+			}
+		})
+	}
+}
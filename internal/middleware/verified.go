@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go-mygram/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerified only lets through users that verified their email, it
+// must run after CheckAuthBearer which loads the flag from the user
+func RequireVerified() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !ctx.GetBool(CLAIM_EMAIL_VERIFIED) {
+			pkg.AbortWithErrorCode(ctx, http.StatusForbidden, pkg.CodeEmailNotVerified, "forbidden", "verify your email before doing this")
+			return
+		}
+		ctx.Next()
+	}
+}
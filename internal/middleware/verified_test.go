@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireVerified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doRequest := func(setFlag func(ctx *gin.Context)) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(rec)
+		r.POST("/photos", setFlag, RequireVerified(), func(ctx *gin.Context) {
+			ctx.Status(http.StatusCreated)
+		})
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/photos", nil))
+		return rec
+	}
+
+	rec := doRequest(func(ctx *gin.Context) { ctx.Set(CLAIM_EMAIL_VERIFIED, true) })
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = doRequest(func(ctx *gin.Context) { ctx.Set(CLAIM_EMAIL_VERIFIED, false) })
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "verify your email")
+
+	// without the auth middleware the flag is missing, which is not verified
+	rec = doRequest(func(ctx *gin.Context) {})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,136 @@
+package migration
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// Migration is one versioned sql file, e.g. 000001_create_users.sql
+type Migration struct {
+	Version int64
+	Name    string
+	SQL     string
+}
+
+type Migrator interface {
+	// Up applies every pending migration in version order and returns the
+	// versions it applied, running it again is a no-op
+	Up(ctx context.Context) ([]int64, error)
+}
+
+type migratorImpl struct {
+	db   *gorm.DB
+	fsys fs.FS
+}
+
+// NewMigrator runs the migrations embedded in the binary
+func NewMigrator(db *gorm.DB) Migrator {
+	return NewMigratorFS(db, embeddedSQL())
+}
+
+func embeddedSQL() fs.FS {
+	// the directory is embedded above, so Sub can't fail
+	sub, _ := fs.Sub(embedded, "sql")
+	return sub
+}
+
+// NewMigratorFS runs the .sql files at the root of fsys
+func NewMigratorFS(db *gorm.DB, fsys fs.FS) Migrator {
+	return &migratorImpl{db: db, fsys: fsys}
+}
+
+func (m *migratorImpl) Up(ctx context.Context) ([]int64, error) {
+	migrations, err := load(m.fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	db := m.db.WithContext(ctx)
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`).Error; err != nil {
+		return nil, err
+	}
+
+	var versions []int64
+	if err := db.Raw("SELECT version FROM schema_migrations").Scan(&versions).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int64]bool, len(versions))
+	for _, v := range versions {
+		done[v] = true
+	}
+
+	applied := []int64{}
+	for _, mig := range migrations {
+		if done[mig.Version] {
+			continue
+		}
+		// the file and its bookkeeping row commit together, so a failed
+		// migration is retried on the next run
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(mig.SQL).Error; err != nil {
+				return err
+			}
+			return tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", mig.Version, mig.Name).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %s: %w", mig.Name, err)
+		}
+		log.Printf("applied migration %s", mig.Name)
+		applied = append(applied, mig.Version)
+	}
+	return applied, nil
+}
+
+// load reads and sorts the migrations, rejecting files without a numeric
+// version prefix or with a version used twice
+func load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(names))
+	seen := map[int64]string{}
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: missing version prefix", name)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migration %s: version already used by %s", name, other)
+		}
+		seen[version] = name
+
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    strings.TrimSuffix(path.Base(name), ".sql"),
+			SQL:     string(body),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
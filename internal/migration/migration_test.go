@@ -0,0 +1,174 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newMockGorm() (*gorm.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening a stub database connection", err)
+	}
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening gorm database", err)
+	}
+	return gormDB, mock
+}
+
+var testFS = fstest.MapFS{
+	"000002_second.sql": {Data: []byte("CREATE TABLE second (id INT)")},
+	"000001_first.sql":  {Data: []byte("CREATE TABLE first (id INT)")},
+}
+
+func TestUp(t *testing.T) {
+	t.Run("success apply pending in version order", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM schema_migrations`)).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}))
+		for _, mig := range []struct {
+			sql  string
+			name string
+		}{{"CREATE TABLE first", "000001_first"}, {"CREATE TABLE second", "000002_second"}} {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(mig.sql)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`)).
+				WithArgs(sqlmock.AnyArg(), mig.name).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+
+		applied, err := NewMigratorFS(db, testFS).Up(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{1, 2}, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success skip applied versions", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM schema_migrations`)).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1).AddRow(2))
+
+		applied, err := NewMigratorFS(db, testFS).Up(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{}, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error rollback failed migration", func(t *testing.T) {
+		db, mock := newMockGorm()
+
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM schema_migrations`)).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE second")).WillReturnError(errors.New("some error"))
+		mock.ExpectRollback()
+
+		applied, err := NewMigratorFS(db, testFS).Up(context.Background())
+		assert.NotNil(t, err)
+		assert.Equal(t, []int64{}, applied)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	migrations, err := load(embeddedSQL())
+	assert.Nil(t, err)
+
+	names := []string{}
+	for _, mig := range migrations {
+		names = append(names, mig.Name)
+	}
+	assert.Equal(t, []string{
+		"000001_create_users",
+		"000002_create_photos",
+		"000003_create_comments",
+		"000004_create_social_media",
+		"000005_create_refresh_tokens",
+		"000006_create_photo_mentions",
+		"000007_create_likes",
+		"000008_add_users_avatar_url",
+		"000009_add_email_verification",
+		"000010_create_password_reset_tokens",
+		"000011_add_users_role",
+		"000012_add_users_version",
+		"000013_add_users_lower_unique",
+		"000014_add_users_lockout",
+		"000015_add_users_last_login_at",
+		"000016_add_photos_visibility",
+		"000017_create_follows",
+		"000018_add_photos_user_id_created_at_index",
+		"000019_add_photos_created_at_index",
+		"000020_create_tags",
+		"000021_create_reports",
+		"000022_add_users_username_prefix_index",
+		"000023_add_users_profile",
+		"000024_add_raw_text",
+	}, names)
+}
+
+// HardDeleteUser deletes some owned rows itself and leaves the rest to the
+// foreign keys, so every key on users and photos must cascade
+func TestForeignKeysCascade(t *testing.T) {
+	migrations, err := load(embeddedSQL())
+	assert.Nil(t, err)
+
+	table := regexp.MustCompile(`CREATE TABLE IF NOT EXISTS (\w+)`)
+	column := regexp.MustCompile(`^\s*(\w+)\s.*REFERENCES (users|photos) \(id\)(.*)$`)
+	keys := []string{}
+	for _, mig := range migrations {
+		current := ""
+		for _, line := range strings.Split(mig.SQL, "\n") {
+			if m := table.FindStringSubmatch(line); m != nil {
+				current = m[1]
+			}
+			m := column.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			assert.Contains(t, m[3], "ON DELETE CASCADE", "%s.%s", current, m[1])
+			keys = append(keys, current+"."+m[1])
+		}
+	}
+	// the rows HardDeleteUser has no DELETE of its own for
+	for _, key := range []string{
+		"email_verification_tokens.user_id",
+		"password_reset_tokens.user_id",
+		"photo_tags.photo_id",
+		"reports.reporter_id",
+	} {
+		assert.Contains(t, keys, key)
+	}
+}
+
+func TestLoadRejectsBadNames(t *testing.T) {
+	_, err := load(fstest.MapFS{"create_users.sql": {Data: []byte("")}})
+	assert.NotNil(t, err)
+
+	_, err = load(fstest.MapFS{
+		"000001_a.sql": {Data: []byte("")},
+		"000001_b.sql": {Data: []byte("")},
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS photos (
+    id         BIGSERIAL PRIMARY KEY,
+    title      VARCHAR(255) NOT NULL,
+    caption    TEXT         NOT NULL DEFAULT '',
+    photo_url  TEXT         NOT NULL,
+    user_id    BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_photos_user_id ON photos (user_id);
+CREATE INDEX IF NOT EXISTS idx_photos_deleted_at ON photos (deleted_at);
@@ -0,0 +1,13 @@
+-- gorm maps model.SocialMedia to the "social_media" table
+CREATE TABLE IF NOT EXISTS social_media (
+    id               BIGSERIAL PRIMARY KEY,
+    name             VARCHAR(255) NOT NULL,
+    social_media_url TEXT         NOT NULL,
+    user_id          BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    deleted_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_social_media_user_id ON social_media (user_id);
+CREATE INDEX IF NOT EXISTS idx_social_media_deleted_at ON social_media (deleted_at);
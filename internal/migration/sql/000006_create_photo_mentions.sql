@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS photo_mentions (
+    photo_id BIGINT NOT NULL REFERENCES photos (id) ON DELETE CASCADE,
+    user_id  BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (photo_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_photo_mentions_user_id ON photo_mentions (user_id);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS likes (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    photo_id   BIGINT      NOT NULL REFERENCES photos (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT likes_user_id_photo_id_key UNIQUE (user_id, photo_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_likes_photo_id ON likes (photo_id);
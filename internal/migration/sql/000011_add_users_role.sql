@@ -0,0 +1,2 @@
+-- admins are promoted by hand, e.g. UPDATE users SET role = 'admin' WHERE id = 1
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
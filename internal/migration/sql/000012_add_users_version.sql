@@ -0,0 +1,2 @@
+-- bumped on every profile update so concurrent writers can detect each other
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
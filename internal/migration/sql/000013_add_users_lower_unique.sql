@@ -0,0 +1,27 @@
+-- sign up relies on these instead of looking the email or username up first,
+-- so two concurrent sign ups can't both get through. They also catch
+-- accounts that only differ by case, the index names are matched by
+-- repository.translateUserError
+--
+-- existing rows that only differ by case would make the index build fail
+-- with a bare unique violation, so name them first. Rename or merge those
+-- accounts by hand and run the migrations again
+DO $$
+DECLARE
+	dupes TEXT;
+BEGIN
+	SELECT string_agg(value, ', ') INTO dupes
+	FROM (SELECT LOWER(email) AS value FROM users GROUP BY LOWER(email) HAVING COUNT(*) > 1) emails;
+	IF dupes IS NOT NULL THEN
+		RAISE EXCEPTION 'users with emails that only differ by case: %', dupes;
+	END IF;
+
+	SELECT string_agg(value, ', ') INTO dupes
+	FROM (SELECT LOWER(username) AS value FROM users GROUP BY LOWER(username) HAVING COUNT(*) > 1) usernames;
+	IF dupes IS NOT NULL THEN
+		RAISE EXCEPTION 'users with usernames that only differ by case: %', dupes;
+	END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (LOWER(email));
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_lower_key ON users (LOWER(username));
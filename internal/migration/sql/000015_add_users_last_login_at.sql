@@ -0,0 +1,2 @@
+-- NULL until the first sign in after this migration
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- who may see a photo, existing photos stay public
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS visibility VARCHAR(20) NOT NULL DEFAULT 'public';
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    followee_id BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CONSTRAINT follows_not_self CHECK (follower_id <> followee_id)
+);
+
+-- the primary key covers lookups by follower, this one the followers of a user
+CREATE INDEX IF NOT EXISTS idx_follows_followee_id ON follows (followee_id, created_at);
@@ -0,0 +1,6 @@
+-- serves the following feed, which walks the photos of each followed user
+-- newest first
+CREATE INDEX IF NOT EXISTS idx_photos_user_id_created_at ON photos (user_id, created_at DESC, id DESC);
+
+-- the new index leads with user_id, it covers every lookup the old one did
+DROP INDEX IF EXISTS idx_photos_user_id;
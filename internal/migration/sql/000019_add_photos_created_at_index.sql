@@ -0,0 +1,2 @@
+-- serves the newest first list and the time window of the popular one
+CREATE INDEX IF NOT EXISTS idx_photos_created_at ON photos (created_at DESC, id DESC);
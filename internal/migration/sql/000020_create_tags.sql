@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(30) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tags_name_key UNIQUE (name)
+);
+
+CREATE TABLE IF NOT EXISTS photo_tags (
+    photo_id BIGINT NOT NULL REFERENCES photos (id) ON DELETE CASCADE,
+    tag_id   BIGINT NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (photo_id, tag_id)
+);
+
+-- serves GET /photos?tag= and the popular tags count
+CREATE INDEX IF NOT EXISTS idx_photo_tags_tag_id ON photo_tags (tag_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reports (
+    id          BIGSERIAL PRIMARY KEY,
+    reporter_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    target_type VARCHAR(16) NOT NULL CHECK (target_type IN ('photo', 'comment')),
+    target_id   BIGINT NOT NULL,
+    reason      TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT reports_reporter_target_key UNIQUE (reporter_id, target_type, target_id)
+);
+
+-- serves the grouping of GET /reports
+CREATE INDEX IF NOT EXISTS idx_reports_target ON reports (target_type, target_id);
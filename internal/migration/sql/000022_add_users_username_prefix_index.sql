@@ -0,0 +1,3 @@
+-- the unique LOWER(username) index can't serve LIKE 'prefix%' outside the C
+-- collation, this one backs GET /users/search
+CREATE INDEX IF NOT EXISTS idx_users_username_lower_pattern ON users (LOWER(username) text_pattern_ops);
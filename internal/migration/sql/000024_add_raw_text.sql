@@ -0,0 +1,4 @@
+-- the caption and message as they were posted, before the markup was
+-- stripped. Only their author reads them back, and only when asked for
+ALTER TABLE photos ADD COLUMN IF NOT EXISTS raw_caption TEXT NOT NULL DEFAULT '';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS raw_message TEXT NOT NULL DEFAULT '';
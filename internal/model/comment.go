@@ -0,0 +1,83 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-mygram/pkg"
+
+	"gorm.io/gorm"
+)
+
+const MaxCommentLength = 500
+
+type Comment struct {
+	ID         uint64         `json:"id"`
+	UserID     uint64         `json:"user_id"`
+	PhotoID    uint64         `json:"photo_id"`
+	Message    string         `json:"message"`
+	RawMessage string         `json:"-"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
+
+	User  *PublicUser `json:"user,omitempty"`
+	Photo *Photo      `json:"photo,omitempty"`
+}
+
+// CommentRawResponse is a comment along with its message as it was posted,
+// before the markup was stripped
+type CommentRawResponse struct {
+	Comment
+	RawMessage string `json:"raw_message"`
+}
+
+// ToRawResponse adds the message as posted, comments saved before it was
+// kept fall back to the stored message
+func (c Comment) ToRawResponse() CommentRawResponse {
+	raw := c.RawMessage
+	if raw == "" {
+		raw = c.Message
+	}
+	return CommentRawResponse{Comment: c, RawMessage: raw}
+}
+
+type CommentPost struct {
+	PhotoID uint64 `json:"photo_id" binding:"required"`
+	Message string `json:"message" binding:"required"`
+}
+
+type CommentUpdate struct {
+	Message string `json:"message" binding:"required"`
+}
+
+func (c CommentPost) Validate() error {
+	var verrs pkg.ValidationErrors
+	if c.PhotoID == 0 {
+		verrs.Add("photo_id", "photo id is required")
+	}
+	validateCommentMessage(&verrs, c.Message)
+	return verrs.Err()
+}
+
+func (c CommentUpdate) Validate() error {
+	var verrs pkg.ValidationErrors
+	validateCommentMessage(&verrs, c.Message)
+	return verrs.Err()
+}
+
+func validateCommentMessage(verrs *pkg.ValidationErrors, message string) {
+	// the service strips the markup, a message made only of tags is empty
+	if strings.TrimSpace(pkg.StripHTML(message)) == "" {
+		verrs.Add("message", "message is required")
+		return
+	}
+	if utf8.RuneCountInString(message) > MaxCommentLength {
+		verrs.Add("message", "message is too long")
+	}
+}
+
+func (c Comment) Cursor() pkg.Cursor {
+	return pkg.Cursor{CreatedAt: c.CreatedAt, ID: c.ID}
+}
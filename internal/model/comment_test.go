@@ -0,0 +1,24 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentValidate(t *testing.T) {
+	t.Run("error message too long", func(t *testing.T) {
+		err := CommentPost{PhotoID: 1, Message: strings.Repeat("a", MaxCommentLength+1)}.Validate()
+		assert.Equal(t, []string{"message"}, fieldsOf(t, err))
+	})
+
+	t.Run("error message of only markup", func(t *testing.T) {
+		err := CommentPost{PhotoID: 1, Message: "<script>alert(1)</script>"}.Validate()
+		assert.Equal(t, []string{"message"}, fieldsOf(t, err))
+	})
+
+	t.Run("success message at max length", func(t *testing.T) {
+		assert.Nil(t, CommentUpdate{Message: strings.Repeat("a", MaxCommentLength)}.Validate())
+	})
+}
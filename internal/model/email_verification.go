@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// EmailVerificationToken proves ownership of the email of UserID, only the
+// hash of the token mailed to the user is stored
+type EmailVerificationToken struct {
+	ID        uint64    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	TokenHash string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// FeedTopComments is how many of the latest comments come with a feed item
+const FeedTopComments = 3
+
+type FeedItem struct {
+	Photo
+	User        *PublicUser `json:"user"`
+	TopComments []Comment   `json:"top_comments"`
+}
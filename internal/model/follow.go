@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"go-mygram/pkg"
+)
+
+// Follow is FollowerID following FolloweeID, a user follows another at most
+// once and never themselves
+type Follow struct {
+	FollowerID uint64    `json:"follower_id"`
+	FolloweeID uint64    `json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FollowCounts are the follow fields of a user
+type FollowCounts struct {
+	UserID         uint64
+	FollowerCount  int64
+	FollowingCount int64
+}
+
+// FollowUser is an entry of a followers or following list, anyone may read
+// those so it only has the public fields of the user
+type FollowUser struct {
+	PublicUser
+	FollowerCount  int64     `json:"follower_count"`
+	FollowingCount int64     `json:"following_count"`
+	FollowedAt     time.Time `json:"followed_at"`
+}
+
+// Cursor pages the list by when the follow happened, not when the user
+// signed up
+func (f FollowUser) Cursor() pkg.Cursor {
+	return pkg.Cursor{CreatedAt: f.FollowedAt, ID: f.ID}
+}
@@ -22,4 +22,5 @@ type AccessClaim struct {
 	StandardClaim
 	UserID   uint64 `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 }
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Like is a user liking a photo, a user likes a photo at most once. The
+// like count is never stored, it is counted from the likes on read so
+// concurrent likes cannot lose an update
+type Like struct {
+	ID        uint64    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	PhotoID   uint64    `json:"photo_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LikeStats are the like fields of a photo as seen by one user
+type LikeStats struct {
+	PhotoID   uint64
+	LikeCount int64
+	LikedByMe bool
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PhotoMention links a photo to a user mentioned in its caption
+type PhotoMention struct {
+	PhotoID uint64
+	UserID  uint64
+}
+
+// MentionedUser is the public part of a mentioned user, it maps onto the
+// users table so it can be preloaded without exposing the email
+type MentionedUser struct {
+	ID        uint64         `json:"id"`
+	Username  string         `json:"username"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
+}
+
+func (MentionedUser) TableName() string {
+	return "users"
+}
+
+// a mention starts at the beginning of the text or after a character that
+// can't be part of a word, so emails like a@b.com are not mentions. Dots
+// and dashes are only kept between name characters, which drops trailing
+// punctuation such as in "thanks @alice."
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_]+(?:[.-][\p{L}\p{N}_]+)*)`)
+
+// ParseMentions returns the lowercased usernames mentioned in text, in order
+// of first appearance and without duplicates
+func ParseMentions(text string) []string {
+	var usernames []string
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.ToLower(m[1])
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "no mention", text: "sunset at the beach", want: nil},
+		{name: "start of text", text: "@alice look", want: []string{"alice"}},
+		{name: "trailing punctuation", text: "thanks @alice. and @bob, (@carol)!", want: []string{"alice", "bob", "carol"}},
+		{name: "possessive", text: "@alice's camera", want: []string{"alice"}},
+		{name: "dots and dashes inside name", text: "with @jane.doe and @john-smith.", want: []string{"jane.doe", "john-smith"}},
+		{name: "adjacent mentions", text: "@alice,@bob", want: []string{"alice", "bob"}},
+		{name: "email is not a mention", text: "mail me at me@example.com", want: nil},
+		{name: "double at is not a mention", text: "@@alice", want: nil},
+		{name: "lone at", text: "meet @ noon", want: nil},
+		{name: "case insensitive duplicates", text: "@Alice and @alice", want: []string{"alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseMentions(tt.text))
+		})
+	}
+}
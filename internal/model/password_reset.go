@@ -0,0 +1,44 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go-mygram/pkg"
+)
+
+// PasswordResetToken lets UserID choose a new password once, only the hash
+// of the mailed token is stored
+type PasswordResetToken struct {
+	ID        uint64    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	TokenHash string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+func (f ForgotPasswordRequest) Validate() error {
+	var verrs pkg.ValidationErrors
+	validateEmail(&verrs, f.Email)
+	return verrs.Err()
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// Validate only checks the rules that don't depend on the account, the
+// service checks the password against the username and email
+func (r ResetPasswordRequest) Validate() error {
+	var verrs pkg.ValidationErrors
+	if strings.TrimSpace(r.Token) == "" {
+		verrs.Add("token", "token is required")
+	}
+	validatePassword(&verrs, r.NewPassword, "", "")
+	return verrs.Err()
+}
@@ -1,24 +1,107 @@
 package model
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
+	"go-mygram/pkg"
+
 	"gorm.io/gorm"
 )
 
+// who can see a photo besides its owner, photos are VisibilityPublic unless
+// the owner picks otherwise
+const (
+	VisibilityPublic    = "public"
+	VisibilityPrivate   = "private"
+	VisibilityFollowers = "followers"
+)
+
 type Photo struct {
-	ID        uint64         `json:"id"`
-	Title     string         `json:"title"`
-	Caption   string         `json:"caption"`
-	PhotoURL  string         `json:"photo_url"`
-	UserID    uint64         `json:"user_id"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
+	ID         uint64          `json:"id"`
+	Title      string          `json:"title"`
+	Caption    string          `json:"caption"`
+	RawCaption string          `json:"-"`
+	PhotoURL   string          `json:"photo_url"`
+	UserID     uint64          `json:"user_id"`
+	Visibility string          `json:"visibility" gorm:"default:public"`
+	Mentions   []MentionedUser `json:"mentions,omitempty" gorm:"many2many:photo_mentions;joinForeignKey:PhotoID;joinReferences:UserID"`
+	Tags       []Tag           `json:"tags,omitempty" gorm:"many2many:photo_tags;joinForeignKey:PhotoID;joinReferences:TagID"`
+	LikeCount  int64           `json:"like_count" gorm:"-"`
+	LikedByMe  bool            `json:"liked_by_me" gorm:"-"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt  `json:"-" gorm:"column:deleted_at"`
+}
+
+// PhotoRawResponse is a photo along with its caption as it was posted,
+// before the markup was stripped
+type PhotoRawResponse struct {
+	Photo
+	RawCaption string `json:"raw_caption"`
+}
+
+// ToRawResponse adds the caption as posted, photos saved before it was kept
+// fall back to the stored caption
+func (p Photo) ToRawResponse() PhotoRawResponse {
+	raw := p.RawCaption
+	if raw == "" {
+		raw = p.Caption
+	}
+	return PhotoRawResponse{Photo: p, RawCaption: raw}
 }
 
 type PhotoPost struct {
 	Title    string `json:"title" binding:"required"`
 	Caption  string `json:"caption" binding:"required"`
 	PhotoURL string `json:"photo_url" binding:"required"`
+	// Visibility defaults to public on create and is kept on update when empty
+	Visibility string `json:"visibility" enums:"public,private,followers"`
+	// normalized with NormalizeTags, they replace the tags of the photo on
+	// update
+	Tags []string `json:"tags"`
+}
+
+func (p PhotoPost) Validate() error {
+	var verrs pkg.ValidationErrors
+	// the service strips the markup, a title or caption made only of tags
+	// would be saved empty
+	if strings.TrimSpace(pkg.StripHTML(p.Title)) == "" {
+		verrs.Add("title", "title is required")
+	}
+	if p.Caption != "" && strings.TrimSpace(pkg.StripHTML(p.Caption)) == "" {
+		verrs.Add("caption", "caption has no text")
+	}
+	if strings.TrimSpace(p.PhotoURL) == "" {
+		verrs.Add("photo_url", "photo url is required")
+	} else if !isHTTPURL(p.PhotoURL) {
+		verrs.Add("photo_url", "invalid photo url")
+	}
+	switch p.Visibility {
+	case "", VisibilityPublic, VisibilityPrivate, VisibilityFollowers:
+	default:
+		verrs.Add("visibility", "visibility must be one of public, private, followers")
+	}
+	validateTags(&verrs, NormalizeTags(p.Tags))
+	return verrs.Err()
+}
+
+// isHTTPURL reports whether raw is an absolute http(s) url with a host
+func isHTTPURL(raw string) bool {
+	u, err := url.ParseRequestURI(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (p Photo) Cursor() pkg.Cursor {
+	return pkg.Cursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
+
+// PopularCursor is the cursor of lists ranked by LikeCount, it must be
+// taken once the like count is filled
+func (p Photo) PopularCursor() pkg.Cursor {
+	return pkg.Cursor{Score: p.LikeCount, CreatedAt: p.CreatedAt, ID: p.ID}
 }
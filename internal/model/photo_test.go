@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhotoPostValidate(t *testing.T) {
+	t.Run("error invalid photo url", func(t *testing.T) {
+		for _, url := range []string{"not a url", "ftp://example.com/a.jpg", "/a.jpg"} {
+			err := PhotoPost{Title: "title", PhotoURL: url}.Validate()
+			assert.Equal(t, []string{"photo_url"}, fieldsOf(t, err))
+		}
+	})
+
+	t.Run("success valid photo url", func(t *testing.T) {
+		assert.Nil(t, PhotoPost{Title: "title", PhotoURL: "https://example.com/a.jpg"}.Validate())
+	})
+
+	t.Run("error only markup", func(t *testing.T) {
+		err := PhotoPost{Title: "<script>alert(1)</script>", Caption: "<img src=x onerror=alert(1)>", PhotoURL: "https://example.com/a.jpg"}.Validate()
+		assert.Equal(t, []string{"title", "caption"}, fieldsOf(t, err))
+	})
+
+	t.Run("success markup around text", func(t *testing.T) {
+		assert.Nil(t, PhotoPost{Title: "<b>title</b>", Caption: "a<b c", PhotoURL: "https://example.com/a.jpg"}.Validate())
+	})
+
+	t.Run("error unknown visibility", func(t *testing.T) {
+		err := PhotoPost{Title: "title", PhotoURL: "https://example.com/a.jpg", Visibility: "friends"}.Validate()
+		assert.Equal(t, []string{"visibility"}, fieldsOf(t, err))
+	})
+
+	t.Run("success known visibility", func(t *testing.T) {
+		for _, visibility := range []string{"", VisibilityPublic, VisibilityPrivate, VisibilityFollowers} {
+			assert.Nil(t, PhotoPost{Title: "title", PhotoURL: "https://example.com/a.jpg", Visibility: visibility}.Validate())
+		}
+	})
+}
+
+func TestPhotoToRawResponse(t *testing.T) {
+	raw := Photo{Caption: "sunset", RawCaption: "<b>sunset</b>"}.ToRawResponse()
+	assert.Equal(t, "<b>sunset</b>", raw.RawCaption)
+
+	// photos saved before the raw caption was kept
+	raw = Photo{Caption: "sunset"}.ToRawResponse()
+	assert.Equal(t, "sunset", raw.RawCaption)
+}
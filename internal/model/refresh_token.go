@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type RefreshToken struct {
+	ID        uint64     `json:"id"`
+	UserID    uint64     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// AccessToken carries the expiry of the token next to it so clients can
+// schedule a refresh, both fields come from the exp claim
+type AccessToken struct {
+	Token string `json:"access_token"`
+	// ExpiresIn is the lifetime in seconds
+	ExpiresIn int64     `json:"expires_in"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type TokenPair struct {
+	AccessToken
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-mygram/pkg"
+)
+
+// kinds of content a user can report
+const (
+	ReportTargetPhoto   = "photo"
+	ReportTargetComment = "comment"
+)
+
+const MaxReportReasonLength = 500
+
+// Report is a user flagging a photo or a comment, a user reports an item
+// at most once
+type Report struct {
+	ID         uint64    `json:"id"`
+	ReporterID uint64    `json:"reporter_id"`
+	TargetType string    `json:"target_type"`
+	TargetID   uint64    `json:"target_id"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ReportPost struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (r ReportPost) Validate() error {
+	var verrs pkg.ValidationErrors
+	reason := strings.TrimSpace(r.Reason)
+	switch {
+	case reason == "":
+		verrs.Add("reason", "reason is required")
+	case utf8.RuneCountInString(reason) > MaxReportReasonLength:
+		verrs.Add("reason", fmt.Sprintf("reason must be at most %d characters", MaxReportReasonLength))
+	}
+	return verrs.Err()
+}
+
+// ReportedContent is an item with the reports filed against it
+type ReportedContent struct {
+	TargetType     string    `json:"target_type"`
+	TargetID       uint64    `json:"target_id"`
+	ReportCount    int64     `json:"report_count"`
+	LatestReason   string    `json:"latest_reason"`
+	LastReportedAt time.Time `json:"last_reported_at"`
+}
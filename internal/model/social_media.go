@@ -1,8 +1,13 @@
 package model
 
 import (
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
+	"go-mygram/pkg"
+
 	"gorm.io/gorm"
 )
 
@@ -14,9 +19,60 @@ type SocialMedia struct {
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
+
+	User *PublicUser `json:"user,omitempty"`
 }
 
 type SocialMediaPost struct {
-	Name           string `json:"name"`
-	SocialMediaURL string `json:"social_media_url"`
+	Name           string `json:"name" binding:"required"`
+	SocialMediaURL string `json:"social_media_url" binding:"required"`
+}
+
+// Validate checks the post and, when it is valid, replaces SocialMediaURL
+// with its normalized form
+func (s *SocialMediaPost) Validate() error {
+	var verrs pkg.ValidationErrors
+	if strings.TrimSpace(s.Name) == "" {
+		verrs.Add("name", "name is required")
+	}
+	var normalized string
+	if strings.TrimSpace(s.SocialMediaURL) == "" {
+		verrs.Add("social_media_url", "social media url is required")
+	} else if u, ok := NormalizeSocialMediaURL(s.SocialMediaURL); !ok {
+		verrs.Add("social_media_url", "social media url must be an http or https url")
+	} else {
+		normalized = u
+	}
+	if err := verrs.Err(); err != nil {
+		return err
+	}
+	s.SocialMediaURL = normalized
+	return nil
+}
+
+// schemePrefix matches a leading "scheme:", such as "javascript:" or
+// "mailto:", but not a "host:port"
+var schemePrefix = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:[^0-9]`)
+
+// NormalizeSocialMediaURL turns raw into an absolute http(s) url with a
+// lowercase host, "https://" is assumed when the scheme is missing. Any
+// other scheme, credentials in the url or a missing host are rejected
+func NormalizeSocialMediaURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") && !schemePrefix.MatchString(raw) {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", false
+	}
+	// "https://twitter.com@evil.example" would otherwise pass for twitter
+	if u.User != nil || u.Hostname() == "" {
+		return "", false
+	}
+	u.Host = strings.ToLower(u.Host)
+	return u.String(), true
 }
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSocialMediaURL(t *testing.T) {
+	testCases := []struct {
+		desc string
+		raw  string
+		want string
+		ok   bool
+	}{
+		{desc: "success https url", raw: "https://twitter.com/foo", want: "https://twitter.com/foo", ok: true},
+		{desc: "success http url", raw: "http://example.com", want: "http://example.com", ok: true},
+		{desc: "success schemeless url", raw: "twitter.com/foo", want: "https://twitter.com/foo", ok: true},
+		{desc: "success schemeless url with port", raw: "example.com:8080/foo", want: "https://example.com:8080/foo", ok: true},
+		{desc: "success lowercase host keeps path case", raw: "HTTPS://Twitter.COM/FooBar", want: "https://twitter.com/FooBar", ok: true},
+		{desc: "success padded url", raw: "  instagram.com/foo ", want: "https://instagram.com/foo", ok: true},
+		{desc: "error javascript scheme", raw: "javascript:alert(1)", ok: false},
+		{desc: "error javascript scheme mixed case", raw: "JavaScript:alert(1)", ok: false},
+		{desc: "error javascript scheme with slashes", raw: "javascript://%0aalert(1)", ok: false},
+		{desc: "error data scheme", raw: "data:text/html;base64,PHNjcmlwdD4=", ok: false},
+		{desc: "error ftp scheme", raw: "ftp://example.com/file", ok: false},
+		{desc: "error mailto scheme", raw: "mailto:foo@example.com", ok: false},
+		{desc: "error credentials in url", raw: "https://twitter.com@evil.example/", ok: false},
+		{desc: "error missing host", raw: "https:///foo", ok: false},
+		{desc: "error space in host", raw: "exa mple.com", ok: false},
+		{desc: "error control character", raw: "https://example.com/\x7f", ok: false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got, ok := NormalizeSocialMediaURL(tC.raw)
+			assert.Equal(t, tC.ok, ok)
+			assert.Equal(t, tC.want, got)
+		})
+	}
+}
+
+func TestSocialMediaPostValidate(t *testing.T) {
+	t.Run("success normalize url in place", func(t *testing.T) {
+		post := SocialMediaPost{Name: "twitter", SocialMediaURL: "Twitter.com/foo"}
+		assert.Nil(t, post.Validate())
+		assert.Equal(t, "https://twitter.com/foo", post.SocialMediaURL)
+	})
+
+	t.Run("error dangerous url is left untouched", func(t *testing.T) {
+		post := SocialMediaPost{Name: "twitter", SocialMediaURL: "javascript:alert(1)"}
+		assert.Equal(t, []string{"social_media_url"}, fieldsOf(t, post.Validate()))
+		assert.Equal(t, "javascript:alert(1)", post.SocialMediaURL)
+	})
+
+	t.Run("error missing fields", func(t *testing.T) {
+		post := SocialMediaPost{}
+		assert.Equal(t, []string{"name", "social_media_url"}, fieldsOf(t, post.Validate()))
+	})
+}
+
+func TestSocialMediaEmbeddedUser(t *testing.T) {
+	user := User{ID: 4, Username: "alice", Email: "alice@mail.com", Role: RoleAdmin}.ToPublic()
+	socialMedia := SocialMedia{ID: 1, UserID: 4, User: &user}
+
+	b, err := json.Marshal(socialMedia)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"username":"alice"`)
+	assert.NotContains(t, string(b), "alice@mail.com")
+	assert.NotContains(t, string(b), "role")
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-mygram/pkg"
+)
+
+// limits of the tags of a photo
+const (
+	MaxTagLength    = 30
+	MaxTagsPerPhoto = 10
+)
+
+// a tag is a single word of letters, digits and underscores
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}_]+$`)
+
+type Tag struct {
+	ID        uint64    `json:"-"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"-"`
+}
+
+// PhotoTag links a photo to one of its tags
+type PhotoTag struct {
+	PhotoID uint64
+	TagID   uint64
+}
+
+// TagCount is a tag with the number of public photos carrying it
+type TagCount struct {
+	Name       string `json:"name"`
+	PhotoCount int64  `json:"photo_count"`
+}
+
+// NormalizeTag trims and lowercases tag and drops a leading #
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// NormalizeTags normalizes every tag with NormalizeTag and drops the
+// duplicates, keeping the order of first appearance
+func NormalizeTags(tags []string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		name := NormalizeTag(tag)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// ValidTag reports whether a normalized tag could be stored
+func ValidTag(tag string) bool {
+	return utf8.RuneCountInString(tag) <= MaxTagLength && tagPattern.MatchString(tag)
+}
+
+// validateTags checks tags as returned by NormalizeTags
+func validateTags(verrs *pkg.ValidationErrors, tags []string) {
+	if len(tags) > MaxTagsPerPhoto {
+		verrs.Add("tags", fmt.Sprintf("a photo can have at most %d tags", MaxTagsPerPhoto))
+	}
+	for _, tag := range tags {
+		switch {
+		case tag == "":
+			verrs.Add("tags", "tags must not be empty")
+		case utf8.RuneCountInString(tag) > MaxTagLength:
+			verrs.Add("tags", fmt.Sprintf("tag %q is longer than %d characters", tag, MaxTagLength))
+		case !tagPattern.MatchString(tag):
+			verrs.Add("tags", fmt.Sprintf("tag %q may only contain letters, digits and underscores", tag))
+		}
+	}
+}
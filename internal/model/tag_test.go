@@ -0,0 +1,57 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go-mygram/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "no tags", tags: nil, want: nil},
+		{name: "lowercase and trim", tags: []string{" Sunset ", "BEACH"}, want: []string{"sunset", "beach"}},
+		{name: "leading hash", tags: []string{"#sunset"}, want: []string{"sunset"}},
+		{name: "duplicates keep first position", tags: []string{"beach", "Sunset", "#beach", "sunset"}, want: []string{"beach", "sunset"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeTags(tt.tags))
+		})
+	}
+}
+
+func TestPhotoPostValidateTags(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		tags    []string
+		message string
+	}{
+		{desc: "success letters digits and underscores", tags: []string{"sunset", "golden_hour", "2024", "café"}},
+		{desc: "error empty tag", tags: []string{"sunset", " # "}, message: "tags must not be empty"},
+		{desc: "error too long", tags: []string{strings.Repeat("a", MaxTagLength+1)}, message: `tag "` + strings.Repeat("a", MaxTagLength+1) + `" is longer than 30 characters`},
+		{desc: "error space inside", tags: []string{"golden hour"}, message: `tag "golden hour" may only contain letters, digits and underscores`},
+		{desc: "error punctuation", tags: []string{"sun-set"}, message: `tag "sun-set" may only contain letters, digits and underscores`},
+		{desc: "error too many", tags: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ","), message: "a photo can have at most 10 tags"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := PhotoPost{Title: "title", PhotoURL: "https://example.com/a.jpg", Tags: tC.tags}.Validate()
+			if tC.message == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			var verrs pkg.ValidationErrors
+			assert.True(t, errors.As(err, &verrs))
+			assert.Equal(t, pkg.ValidationErrors{{Field: "tags", Message: tC.message}}, verrs)
+		})
+	}
+}
@@ -1,21 +1,203 @@
 package model
 
 import (
-	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go-mygram/pkg"
 
 	"gorm.io/gorm"
 )
 
+// MinPasswordLength is the shortest password accepted on sign up,
+// deployments may raise it at startup
+var MinPasswordLength = 8
+
+// AllowedEmailDomains restricts sign up and email changes to emails of
+// these domains, matched case-insensitively. Empty allows every domain
+var AllowedEmailDomains []string
+
+// MinAge is the youngest age allowed to sign up by the terms of service
+const MinAge = 13
+
+// limits of the profile fields a user writes about themselves
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 160
+)
+
+// roles a user can have, every account starts as RoleUser
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID        uint64         `json:"id"`
-	Username  string         `json:"username"`
-	Email     string         `json:"email"`
-	Password  string         `json:"-"`
-	Age       int64          `json:"age"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
+	ID            uint64         `json:"id"`
+	Username      string         `json:"username"`
+	Email         string         `json:"email"`
+	Password      string         `json:"-"`
+	Age           int64          `json:"age"`
+	DisplayName   string         `json:"display_name"`
+	Bio           string         `json:"bio"`
+	AvatarURL     string         `json:"avatar_url"`
+	EmailVerified bool           `json:"email_verified"`
+	Role          string         `json:"role"`
+	Version       int64          `json:"version" gorm:"default:1"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
+
+	// sign in bookkeeping, reaching the lockout threshold of failed attempts
+	// sets LockedUntil. LastLoginAt is only shown through AdminUserResponse
+	FailedLoginAttempts int        `json:"-"`
+	LockedUntil         *time.Time `json:"-"`
+	LastLoginAt         *time.Time `json:"-"`
+
+	// filled by the service, see repository.FollowRepository.GetFollowCounts
+	FollowerCount  int64 `json:"follower_count" gorm:"-"`
+	FollowingCount int64 `json:"following_count" gorm:"-"`
+}
+
+// UserResponse is the public shape of a user, it never carries the
+// password hash or other sensitive columns
+type UserResponse struct {
+	ID            uint64    `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Age           int64     `json:"age"`
+	DisplayName   string    `json:"display_name"`
+	Bio           string    `json:"bio"`
+	AvatarURL     string    `json:"avatar_url"`
+	EmailVerified bool      `json:"email_verified"`
+	Role          string    `json:"role"`
+	Version       int64     `json:"version"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// PublicUser is what any user may see of another one, such as the author
+// of a comment. It reads the users table so it can be preloaded
+type PublicUser struct {
+	ID          uint64         `json:"id"`
+	Username    string         `json:"username"`
+	DisplayName string         `json:"display_name"`
+	AvatarURL   string         `json:"avatar_url"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"column:deleted_at"`
+}
+
+func (PublicUser) TableName() string {
+	return "users"
+}
+
+func (u User) ToPublic() PublicUser {
+	return PublicUser{
+		ID:          u.ID,
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		AvatarURL:   u.AvatarURL,
+	}
+}
+
+func ToPublicUsers(users []User) []PublicUser {
+	res := make([]PublicUser, 0, len(users))
+	for _, u := range users {
+		res = append(res, u.ToPublic())
+	}
+	return res
+}
+
+// UserWebhook is what webhook subscribers learn about a user, it leaves out
+// the role and any credential
+type UserWebhook struct {
+	ID        uint64    `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Age       int64     `json:"age"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (u User) ToWebhook() UserWebhook {
+	return UserWebhook{
+		ID:        u.ID,
+		Username:  u.Username,
+		Email:     u.Email,
+		Age:       u.Age,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+func (u User) ToResponse() UserResponse {
+	return UserResponse{
+		ID:            u.ID,
+		Username:      u.Username,
+		Email:         u.Email,
+		Age:           u.Age,
+		DisplayName:   u.DisplayName,
+		Bio:           u.Bio,
+		AvatarURL:     u.AvatarURL,
+		EmailVerified: u.EmailVerified,
+		Role:          u.Role,
+		Version:       u.Version,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+
+		FollowerCount:  u.FollowerCount,
+		FollowingCount: u.FollowingCount,
+	}
+}
+
+// UserStats are the aggregate counts of a profile header
+type UserStats struct {
+	// photos the viewer may see, private ones only count for their owner.
+	// Comments only count on photos the viewer may see as well
+	PhotoCount     int64 `json:"photo_count"`
+	CommentCount   int64 `json:"comment_count"`
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+}
+
+// UserProfileResponse is a user with the counts asked for by
+// ?include=stats
+type UserProfileResponse struct {
+	UserResponse
+	Stats *UserStats `json:"stats,omitempty"`
+}
+
+// SignUpResponse is the new account together with the tokens of its first
+// session
+type SignUpResponse struct {
+	UserResponse
+	TokenPair
+}
+
+func ToUserResponses(users []User) []UserResponse {
+	res := make([]UserResponse, 0, len(users))
+	for _, u := range users {
+		res = append(res, u.ToResponse())
+	}
+	return res
+}
+
+// AdminUserResponse adds the activity columns only admins get to see
+type AdminUserResponse struct {
+	UserResponse
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+func ToAdminUserResponses(users []User) []AdminUserResponse {
+	res := make([]AdminUserResponse, 0, len(users))
+	for _, u := range users {
+		res = append(res, AdminUserResponse{UserResponse: u.ToResponse(), LastLoginAt: u.LastLoginAt})
+	}
+	return res
 }
 
 type DefaultColumn struct {
@@ -34,10 +216,12 @@ type UserMediaSocial struct {
 	DeletedAt time.Time `json:"deleted_at,omitempty"`
 }
 
+// UserSignUp carries the rules of single fields in its binding tags,
+// Validate adds the ones that need more than the field itself
 type UserSignUp struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
-	Email    string `json:"email" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
 	Age      int64  `json:"age" binding:"required"`
 }
 
@@ -46,18 +230,382 @@ type UserSignIn struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// limits of the username autocomplete
+const (
+	DefaultUserSearchLimit = 5
+	MaxUserSearchLimit     = 20
+)
+
+type UsernameAvailability struct {
+	Available bool `json:"available"`
+}
+
+type UserCount struct {
+	Count int64 `json:"count"`
+}
+
+// UserUpdate is a partial update, nil fields are left untouched. Version is
+// the version the client last fetched, the update is rejected when the user
+// changed since then
 type UserUpdate struct {
-	Email    string `json:"email" binding:"required"`
-	Username string `json:"username" binding:"required"`
+	Email       *string `json:"email"`
+	Username    *string `json:"username"`
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	Version     *int64  `json:"version"`
+}
+
+// UserReplace is a full update, email and username are required and the
+// profile fields left out are cleared. Version works like
+// UserUpdate.Version
+type UserReplace struct {
+	Email       string `json:"email" binding:"required,email"`
+	Username    string `json:"username" binding:"required"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	Version     *int64 `json:"version"`
 }
 
+// MaxUserBatchSize caps the ids of a single UserBatchRequest
+const MaxUserBatchSize = 100
+
+// UserBatchRequest asks for several users at once, such as the authors of
+// a list of comments
+type UserBatchRequest struct {
+	IDs []uint64 `json:"ids" binding:"required"`
+}
+
+func (u UserBatchRequest) Validate() error {
+	var verrs pkg.ValidationErrors
+	switch {
+	case len(u.IDs) == 0:
+		verrs.Add("ids", "ids is required")
+	case len(u.IDs) > MaxUserBatchSize:
+		verrs.Add("ids", fmt.Sprintf("at most %d ids can be requested at once", MaxUserBatchSize))
+	}
+	for _, id := range u.IDs {
+		if id == 0 {
+			verrs.Add("ids", "ids must be positive")
+			break
+		}
+	}
+	return verrs.Err()
+}
+
+// Validate checks the binding tags too, for sign ups that don't come from
+// a request such as the seed
 func (u UserSignUp) Validate() error {
-	// check username
-	if u.Username == "" {
-		return errors.New("invalid username")
+	verrs := pkg.StructErrors(u)
+	if u.Username != "" {
+		validateUsername(&verrs, u.Username)
+	}
+	if u.Password != "" {
+		validatePassword(&verrs, u.Password, u.Username, u.Email)
+	}
+	if u.Age != 0 && u.Age < MinAge {
+		verrs.Add("age", fmt.Sprintf("you must be at least %d years old to sign up", MinAge))
+	}
+	validateEmailDomain(&verrs, u.Email)
+	return verrs.Err()
+}
+
+// validateEmailDomain applies AllowedEmailDomains, an email that could not
+// sign up can't be switched to on an update either
+func validateEmailDomain(verrs *pkg.ValidationErrors, email string) {
+	if _, domain, ok := strings.Cut(strings.TrimSpace(email), "@"); ok && !emailDomainAllowed(domain) {
+		verrs.Add("email", fmt.Sprintf("emails of %s are not allowed to sign up", strings.ToLower(domain)))
+	}
+}
+
+func emailDomainAllowed(domain string) bool {
+	if len(AllowedEmailDomains) == 0 {
+		return true
+	}
+	for _, allowed := range AllowedEmailDomains {
+		if strings.EqualFold(domain, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecommendedPasswordLength is only advised, shorter passwords that pass
+// MinPasswordLength are accepted with a warning
+const RecommendedPasswordLength = 12
+
+// commonEmailDomains are used to spot likely typos such as "gmial.com",
+// a domain in the list is never reported
+var commonEmailDomains = []string{
+	"gmail.com",
+	"googlemail.com",
+	"yahoo.com",
+	"ymail.com",
+	"hotmail.com",
+	"outlook.com",
+	"live.com",
+	"icloud.com",
+	"aol.com",
+	"mail.com",
+	"proton.me",
+}
+
+// Warnings lists what doesn't block the sign up but is worth telling the
+// user, it is meant for input that already passed Validate
+func (u UserSignUp) Warnings() []pkg.FieldError {
+	var warns pkg.ValidationErrors
+	if utf8.RuneCountInString(u.Password) < RecommendedPasswordLength {
+		warns.Add("password", fmt.Sprintf("passwords of at least %d characters are harder to guess", RecommendedPasswordLength))
+	}
+	if !strings.ContainsFunc(u.Password, unicode.IsUpper) || !strings.ContainsFunc(u.Password, isSymbol) {
+		warns.Add("password", "mixing in uppercase letters and symbols makes the password stronger")
+	}
+	_, domain, _ := strings.Cut(strings.ToLower(strings.TrimSpace(u.Email)), "@")
+	if suggestion := suggestEmailDomain(domain); suggestion != "" {
+		warns.Add("email", fmt.Sprintf("%s is an uncommon email domain, did you mean %s?", domain, suggestion))
+	}
+	return warns
+}
+
+func isSymbol(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// suggestEmailDomain returns the common domain that domain is one edit
+// away from, or "" when it is common itself or close to none
+func suggestEmailDomain(domain string) string {
+	if domain == "" {
+		return ""
+	}
+	for _, common := range commonEmailDomains {
+		if domain == common {
+			return ""
+		}
+	}
+	for _, common := range commonEmailDomains {
+		if editDistance(domain, common) == 1 {
+			return common
+		}
+	}
+	return ""
+}
+
+// editDistance is the levenshtein distance between a and b where swapping
+// two neighbouring characters counts as one edit
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func (u UserSignIn) Validate() error {
+	var verrs pkg.ValidationErrors
+	validateEmail(&verrs, u.Email)
+	if u.Password == "" {
+		verrs.Add("password", "password is required")
+	}
+	return verrs.Err()
+}
+
+func (u UserUpdate) Validate() error {
+	var verrs pkg.ValidationErrors
+	if u.Username != nil {
+		validateUsername(&verrs, *u.Username)
+	}
+	if u.Email != nil {
+		validateEmail(&verrs, *u.Email)
+		validateEmailDomain(&verrs, *u.Email)
+	}
+	if u.DisplayName != nil {
+		validateDisplayName(&verrs, *u.DisplayName)
+	}
+	if u.Bio != nil {
+		validateBio(&verrs, *u.Bio)
+	}
+	if u.Version != nil && *u.Version < 1 {
+		verrs.Add("version", "version must be positive")
+	}
+	return verrs.Err()
+}
+
+func (u UserReplace) Validate() error {
+	verrs := pkg.StructErrors(u)
+	if u.Username != "" {
+		validateUsername(&verrs, u.Username)
+	}
+	validateEmailDomain(&verrs, u.Email)
+	validateDisplayName(&verrs, u.DisplayName)
+	validateBio(&verrs, u.Bio)
+	if u.Version != nil && *u.Version < 1 {
+		verrs.Add("version", "version must be positive")
+	}
+	return verrs.Err()
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+	// the refresh token of this session, it stays valid while every other
+	// session of the user is signed out
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Validate leaves the checks that need the account to the service
+func (c ChangePasswordRequest) Validate() error {
+	var verrs pkg.ValidationErrors
+	if c.CurrentPassword == "" {
+		verrs.Add("current_password", "current password is required")
+	}
+	validatePassword(&verrs, c.NewPassword, "", "")
+	return verrs.Err()
+}
+
+// reservedUsernames are static routes under /users, they would shadow
+// /users/:username
+var reservedUsernames = []string{"batch", "count", "me", "search"}
+
+// IsReservedUsername reports whether username can't be taken, regardless of
+// case
+func IsReservedUsername(username string) bool {
+	username = strings.TrimSpace(username)
+	for _, reserved := range reservedUsernames {
+		if strings.EqualFold(username, reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateUsername(verrs *pkg.ValidationErrors, username string) {
+	switch {
+	case strings.TrimSpace(username) == "":
+		verrs.Add("username", "invalid username")
+	case IsReservedUsername(username):
+		verrs.Add("username", "username is reserved")
+	}
+}
+
+// validateDisplayName and validateBio count what is stored, which is the
+// stripped text. Stripping can make it longer, a trailing "<b" becomes "&lt;b"
+func validateDisplayName(verrs *pkg.ValidationErrors, displayName string) {
+	if utf8.RuneCountInString(strings.TrimSpace(pkg.StripHTML(displayName))) > MaxDisplayNameLength {
+		verrs.Add("display_name", fmt.Sprintf("display name must be at most %d characters", MaxDisplayNameLength))
+	}
+}
+
+func validateBio(verrs *pkg.ValidationErrors, bio string) {
+	if utf8.RuneCountInString(strings.TrimSpace(pkg.StripHTML(bio))) > MaxBioLength {
+		verrs.Add("bio", fmt.Sprintf("bio must be at most %d characters", MaxBioLength))
 	}
-	if len(u.Password) < 6 {
-		return errors.New("invalid password")
+}
+
+// validatePassword adds one error per failed strength rule
+func validatePassword(verrs *pkg.ValidationErrors, password, username, email string) {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		verrs.Add("password", fmt.Sprintf("password must be at least %d characters", MinPasswordLength))
+	}
+	if !strings.ContainsFunc(password, unicode.IsDigit) {
+		verrs.Add("password", "password must contain at least one digit")
+	}
+	if !strings.ContainsFunc(password, unicode.IsLetter) {
+		verrs.Add("password", "password must contain at least one letter")
+	}
+	if username != "" && strings.EqualFold(password, username) {
+		verrs.Add("password", "password must not be the same as the username")
+	}
+	localPart, _, _ := strings.Cut(strings.TrimSpace(email), "@")
+	if localPart != "" && strings.EqualFold(password, localPart) {
+		verrs.Add("password", "password must not be the same as the email")
+	}
+}
+
+// ValidatePassword checks password against the strength rules for the
+// account with username and email
+func ValidatePassword(password, username, email string) error {
+	var verrs pkg.ValidationErrors
+	validatePassword(&verrs, password, username, email)
+	return verrs.Err()
+}
+
+func validateEmail(verrs *pkg.ValidationErrors, email string) {
+	if strings.TrimSpace(email) == "" {
+		verrs.Add("email", "email is required")
+		return
+	}
+	if _, err := mail.ParseAddress(strings.TrimSpace(email)); err != nil {
+		verrs.Add("email", "invalid email")
+	}
+}
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+
+	DefaultUserSortBy = "created_at"
+)
+
+// UserSortFields whitelists the columns users can be ordered by, sort_by
+// ends up in the ORDER BY clause so nothing else may pass
+var UserSortFields = map[string]bool{
+	"created_at": true,
+	"username":   true,
+	"id":         true,
+}
+
+type UserListParams struct {
+	pkg.Pagination
+	SortBy string
+	Order  string
+	// Search matches username or email case-insensitively, empty matches all
+	Search string
+	// registration date bounds, both exclusive, nil leaves that side open
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+func (p UserListParams) Validate() error {
+	var verrs pkg.ValidationErrors
+	if !UserSortFields[p.SortBy] {
+		verrs.Add("sort_by", "sort_by must be one of created_at, username, id")
+	}
+	if p.Order != SortAsc && p.Order != SortDesc {
+		verrs.Add("order", "order must be asc or desc")
+	}
+	if p.CreatedAfter != nil && p.CreatedBefore != nil && !p.CreatedAfter.Before(*p.CreatedBefore) {
+		verrs.Add("created_before", "created_before must be after created_after")
+	}
+	return verrs.Err()
+}
+
+// UserCountParams takes the same filters as UserListParams without the
+// paging and ordering
+type UserCountParams struct {
+	Search        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+func (p UserCountParams) Validate() error {
+	var verrs pkg.ValidationErrors
+	if p.CreatedAfter != nil && p.CreatedBefore != nil && !p.CreatedAfter.Before(*p.CreatedBefore) {
+		verrs.Add("created_before", "created_before must be after created_after")
 	}
-	return nil
+	return verrs.Err()
 }
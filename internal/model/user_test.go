@@ -1,11 +1,26 @@
 package model
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
+	"go-mygram/pkg"
+
 	"github.com/stretchr/testify/assert"
 )
 
+func fieldsOf(t *testing.T, err error) []string {
+	var verrs pkg.ValidationErrors
+	assert.True(t, errors.As(err, &verrs))
+	fields := []string{}
+	for _, fe := range verrs {
+		fields = append(fields, fe.Field)
+	}
+	return fields
+}
+
 func TestUserValidate(t *testing.T) {
 	t.Run("error username", func(t *testing.T) {
 		user := UserSignUp{Username: ""}
@@ -13,4 +28,304 @@ func TestUserValidate(t *testing.T) {
 
 		assert.NotNil(t, err)
 	})
+
+	t.Run("error multiple fields", func(t *testing.T) {
+		user := UserSignUp{Username: "", Password: "abc", Email: "not-an-email", Age: 20}
+		err := user.Validate()
+
+		assert.Equal(t, []string{"username", "email", "password", "password"}, fieldsOf(t, err))
+	})
+
+	t.Run("error tag messages", func(t *testing.T) {
+		err := UserSignUp{Email: "user1@mail", Age: 20}.Validate()
+
+		var verrs pkg.ValidationErrors
+		assert.True(t, errors.As(err, &verrs))
+		assert.Equal(t, pkg.ValidationErrors{
+			{Field: "username", Message: "username is required"},
+			{Field: "password", Message: "password is required"},
+			{Field: "email", Message: "invalid email"},
+		}, verrs)
+	})
+
+	t.Run("error reserved username", func(t *testing.T) {
+		for _, username := range []string{"me", "Search", "COUNT", " batch"} {
+			err := UserSignUp{Username: username, Password: "abc12345", Email: "user1@mail.com", Age: 20}.Validate()
+			assert.Equal(t, []string{"username"}, fieldsOf(t, err), username)
+
+			err = UserUpdate{Username: &username}.Validate()
+			assert.Equal(t, []string{"username"}, fieldsOf(t, err), username)
+
+			err = UserReplace{Username: username, Email: "user1@mail.com"}.Validate()
+			assert.Equal(t, []string{"username"}, fieldsOf(t, err), username)
+		}
+		assert.False(t, IsReservedUsername("meme"))
+	})
+
+	t.Run("success sign up", func(t *testing.T) {
+		user := UserSignUp{Username: "user1", Password: "abc12345", Email: "user1@mail.com", Age: 20}
+		assert.Nil(t, user.Validate())
+	})
+}
+
+func TestUserSignUpWarnings(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		password string
+		email    string
+		fields   []string
+	}{
+		{desc: "success no warnings", password: "Str0ng&Longer", email: "user1@mail.com", fields: []string{}},
+		{desc: "success short password", password: "Ab1!defg", email: "user1@gmail.com", fields: []string{"password"}},
+		{desc: "success plain password", password: "abcdefgh1234", email: "user1@gmail.com", fields: []string{"password"}},
+		{desc: "success email domain typo", password: "Str0ng&Longer", email: "user1@gmial.com", fields: []string{"email"}},
+		{desc: "success everything", password: "abc12345", email: "user1@hotmial.com", fields: []string{"password", "password", "email"}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			user := UserSignUp{Username: "user1", Password: tC.password, Email: tC.email, Age: 20}
+			assert.Nil(t, user.Validate())
+
+			fields := []string{}
+			for _, w := range user.Warnings() {
+				fields = append(fields, w.Field)
+			}
+			assert.Equal(t, tC.fields, fields)
+		})
+	}
+
+	t.Run("success domain suggestion", func(t *testing.T) {
+		warns := UserSignUp{Password: "Str0ng&Longer", Email: "a@gmial.com"}.Warnings()
+		assert.Equal(t, "gmial.com is an uncommon email domain, did you mean gmail.com?", warns[0].Message)
+	})
+}
+
+func TestUserValidateAge(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		age     int64
+		message string
+	}{
+		{desc: "error missing age", age: 0, message: "age is required"},
+		{desc: "error negative age", age: -1, message: "you must be at least 13 years old to sign up"},
+		{desc: "error one below minimum", age: MinAge - 1, message: "you must be at least 13 years old to sign up"},
+		{desc: "success exactly minimum", age: MinAge},
+		{desc: "success above minimum", age: MinAge + 1},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			user := UserSignUp{Username: "user1", Password: "abc12345", Email: "user1@mail.com", Age: tC.age}
+			err := user.Validate()
+			if tC.message == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			var verrs pkg.ValidationErrors
+			assert.True(t, errors.As(err, &verrs))
+			assert.Equal(t, pkg.ValidationErrors{{Field: "age", Message: tC.message}}, verrs)
+		})
+	}
+}
+
+func TestUserValidateEmailDomain(t *testing.T) {
+	defer func(old []string) { AllowedEmailDomains = old }(AllowedEmailDomains)
+
+	testCases := []struct {
+		desc    string
+		allowed []string
+		email   string
+		message string
+	}{
+		{desc: "success any domain without a list", email: "user1@mail.com"},
+		{desc: "success allowed domain", allowed: []string{"corp.com", "corp.io"}, email: "user1@corp.io"},
+		{desc: "success domain matched case-insensitively", allowed: []string{"Corp.com"}, email: "user1@CORP.COM"},
+		{desc: "error domain outside the list", allowed: []string{"corp.com"}, email: "user1@Mail.com", message: "emails of mail.com are not allowed to sign up"},
+		{desc: "error subdomain of an allowed domain", allowed: []string{"corp.com"}, email: "user1@dev.corp.com", message: "emails of dev.corp.com are not allowed to sign up"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			AllowedEmailDomains = tC.allowed
+
+			user := UserSignUp{Username: "user1", Password: "abc12345", Email: tC.email, Age: 20}
+			err := user.Validate()
+			if tC.message == "" {
+				assert.Nil(t, err)
+				return
+			}
+
+			var verrs pkg.ValidationErrors
+			assert.True(t, errors.As(err, &verrs))
+			assert.Equal(t, pkg.ValidationErrors{{Field: "email", Message: tC.message}}, verrs)
+		})
+	}
+}
+
+func TestUserValidatePassword(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		password string
+		message  string
+	}{
+		{desc: "error too short", password: "abc123", message: "password must be at least 8 characters"},
+		{desc: "error no digit", password: "abcdefgh", message: "password must contain at least one digit"},
+		{desc: "error no letter", password: "12345678", message: "password must contain at least one letter"},
+		{desc: "error same as username", password: "JohnDoe99", message: "password must not be the same as the username"},
+		{desc: "error same as email local part", password: "john.doe1", message: "password must not be the same as the email"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			user := UserSignUp{Username: "johndoe99", Password: tC.password, Email: "john.doe1@mail.com", Age: 20}
+			err := user.Validate()
+
+			var verrs pkg.ValidationErrors
+			assert.True(t, errors.As(err, &verrs))
+			assert.Equal(t, []pkg.FieldError{{Field: "password", Message: tC.message}}, []pkg.FieldError(verrs))
+		})
+	}
+
+	t.Run("success configured min length", func(t *testing.T) {
+		defer func(old int) { MinPasswordLength = old }(MinPasswordLength)
+		MinPasswordLength = 4
+
+		user := UserSignUp{Username: "johndoe99", Password: "ab12", Email: "john.doe1@mail.com", Age: 20}
+		assert.Nil(t, user.Validate())
+	})
+}
+
+func TestUserSignInValidate(t *testing.T) {
+	t.Run("error missing fields", func(t *testing.T) {
+		err := UserSignIn{}.Validate()
+
+		assert.Equal(t, []string{"email", "password"}, fieldsOf(t, err))
+	})
+
+	t.Run("success sign in", func(t *testing.T) {
+		assert.Nil(t, UserSignIn{Email: "user1@mail.com", Password: "abc12345"}.Validate())
+	})
+}
+
+func TestUserUpdateValidate(t *testing.T) {
+	username, email, empty := "user1", "user1", ""
+
+	t.Run("error invalid email", func(t *testing.T) {
+		err := UserUpdate{Username: &username, Email: &email}.Validate()
+
+		assert.Equal(t, []string{"email"}, fieldsOf(t, err))
+	})
+
+	t.Run("error provided but empty username", func(t *testing.T) {
+		err := UserUpdate{Username: &empty}.Validate()
+
+		assert.Equal(t, []string{"username"}, fieldsOf(t, err))
+	})
+
+	t.Run("error bio and display name too long", func(t *testing.T) {
+		bio, displayName := strings.Repeat("é", MaxBioLength+1), strings.Repeat("a", MaxDisplayNameLength+1)
+		err := UserUpdate{Bio: &bio, DisplayName: &displayName}.Validate()
+
+		assert.Equal(t, []string{"display_name", "bio"}, fieldsOf(t, err))
+	})
+
+	t.Run("success bio at the limit", func(t *testing.T) {
+		bio := strings.Repeat("é", MaxBioLength)
+		assert.Nil(t, UserUpdate{Bio: &bio}.Validate())
+	})
+
+	t.Run("error bio over the limit once stripped", func(t *testing.T) {
+		// 159 runes, the open tag is stored as "&lt;b" which makes 162
+		bio := strings.Repeat("a", MaxBioLength-3) + "<b"
+		err := UserUpdate{Bio: &bio}.Validate()
+
+		assert.Equal(t, []string{"bio"}, fieldsOf(t, err))
+	})
+
+	t.Run("success markup not counted", func(t *testing.T) {
+		displayName := "<b>" + strings.Repeat("a", MaxDisplayNameLength) + "</b>"
+		assert.Nil(t, UserUpdate{DisplayName: &displayName}.Validate())
+	})
+
+	t.Run("success only validate provided fields", func(t *testing.T) {
+		assert.Nil(t, UserUpdate{Username: &username}.Validate())
+		assert.Nil(t, UserUpdate{}.Validate())
+	})
+
+	t.Run("error email domain outside the list", func(t *testing.T) {
+		defer func(old []string) { AllowedEmailDomains = old }(AllowedEmailDomains)
+		AllowedEmailDomains = []string{"corp.com"}
+
+		other, allowed := "user1@mail.com", "user1@corp.com"
+		assert.Equal(t, []string{"email"}, fieldsOf(t, UserUpdate{Email: &other}.Validate()))
+		assert.Nil(t, UserUpdate{Email: &allowed}.Validate())
+	})
+}
+
+func TestUserBatchRequestValidate(t *testing.T) {
+	tooMany := make([]uint64, MaxUserBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = uint64(i + 1)
+	}
+	testCases := []struct {
+		desc string
+		ids  []uint64
+		ok   bool
+	}{
+		{desc: "success some ids", ids: []uint64{1, 2, 3}, ok: true},
+		{desc: "success batch at the cap", ids: tooMany[:MaxUserBatchSize], ok: true},
+		{desc: "error no ids", ids: []uint64{}},
+		{desc: "error over the cap", ids: tooMany},
+		{desc: "error zero id", ids: []uint64{1, 0}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := UserBatchRequest{IDs: tC.ids}.Validate()
+			if tC.ok {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, []string{"ids"}, fieldsOf(t, err))
+		})
+	}
+}
+
+func TestUserToResponse(t *testing.T) {
+	user := User{ID: 1, Username: "user1", Email: "user1@mail.com", Password: "$2a$10$hash"}
+
+	b, err := json.Marshal(ToUserResponses([]User{user}))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(b), "$2a$10$hash")
+	assert.NotContains(t, string(b), "password")
+}
+
+func TestUserToPublic(t *testing.T) {
+	user := User{ID: 1, Username: "user1", Email: "user1@mail.com", Age: 20, Role: RoleAdmin, DisplayName: "User One", AvatarURL: "https://cdn.example/1.png"}
+
+	b, err := json.Marshal(ToPublicUsers([]User{user}))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[{"id":1,"username":"user1","display_name":"User One","avatar_url":"https://cdn.example/1.png"}]`, string(b))
+}
+
+func TestUserReplaceValidate(t *testing.T) {
+	t.Run("error missing fields", func(t *testing.T) {
+		err := UserReplace{}.Validate()
+		assert.Equal(t, []string{"email", "username"}, fieldsOf(t, err))
+	})
+
+	t.Run("error blank username and invalid version", func(t *testing.T) {
+		version := int64(0)
+		err := UserReplace{Email: "user1@mail.com", Username: "  ", Version: &version}.Validate()
+		assert.Equal(t, []string{"username", "version"}, fieldsOf(t, err))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		assert.Nil(t, UserReplace{Email: "user1@mail.com", Username: "user1"}.Validate())
+	})
+
+	t.Run("error email domain outside the list", func(t *testing.T) {
+		defer func(old []string) { AllowedEmailDomains = old }(AllowedEmailDomains)
+		AllowedEmailDomains = []string{"corp.com"}
+
+		err := UserReplace{Email: "user1@mail.com", Username: "user1"}.Validate()
+		assert.Equal(t, []string{"email"}, fieldsOf(t, err))
+	})
 }
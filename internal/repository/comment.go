@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+
+	"go-mygram/internal/infrastructure"
+	"go-mygram/internal/model"
+	"go-mygram/pkg"
+)
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment model.Comment) (model.Comment, error)
+	GetCommentByID(ctx context.Context, id uint64) (model.Comment, error)
+	GetComments(ctx context.Context, viewerID uint64, photoID uint64, after *pkg.Cursor, limit int) ([]model.Comment, error)
+	GetPhotoComments(ctx context.Context, photoID uint64, after *pkg.Cursor, limit int) ([]model.Comment, error)
+	UpdateComment(ctx context.Context, comment model.Comment) (model.Comment, error)
+	DeleteComment(ctx context.Context, id uint64) error
+	GetLatestComments(ctx context.Context, photoIDs []uint64, perPhoto int) ([]model.Comment, error)
+}
+
+type commentRepositoryImpl struct {
+	db infrastructure.GormPostgres
+}
+
+func NewCommentRepository(db infrastructure.GormPostgres) CommentRepository {
+	return &commentRepositoryImpl{db: db}
+}
+
+func (r *commentRepositoryImpl) CreateComment(ctx context.Context, comment model.Comment) (model.Comment, error) {
+	err := connection(ctx, r.db).WithContext(ctx).Create(&comment).Error
+	return comment, err
+}
+
+func (r *commentRepositoryImpl) GetCommentByID(ctx context.Context, id uint64) (model.Comment, error) {
+	var comment model.Comment
+	err := connection(ctx, r.db).WithContext(ctx).Where("id = ?", id).First(&comment).Error
+	return comment, err
+}
+
+// GetComments returns up to limit comments with their author and photo,
+// oldest first and starting right after the cursor when one is given. A zero
+// photoID returns comments of every photo viewerID may see
+func (r *commentRepositoryImpl) GetComments(ctx context.Context, viewerID uint64, photoID uint64, after *pkg.Cursor, limit int) ([]model.Comment, error) {
+	comments := []model.Comment{}
+	query := connection(ctx, r.db).WithContext(ctx).
+		Preload("User").
+		Preload("Photo").
+		Joins("JOIN photos ON photos.id = comments.photo_id AND photos.deleted_at IS NULL").
+		Scopes(visibleTo(viewerID))
+	if photoID != 0 {
+		query = query.Where("comments.photo_id = ?", photoID)
+	}
+	if after != nil {
+		query = query.Where("(comments.created_at, comments.id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+	err := query.Order("comments.created_at, comments.id").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+// GetPhotoComments returns up to limit comments of photoID with their
+// author, newest first and starting right after the cursor when one is given
+func (r *commentRepositoryImpl) GetPhotoComments(ctx context.Context, photoID uint64, after *pkg.Cursor, limit int) ([]model.Comment, error) {
+	comments := []model.Comment{}
+	query := connection(ctx, r.db).WithContext(ctx).Preload("User").Where("photo_id = ?", photoID)
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+func (r *commentRepositoryImpl) UpdateComment(ctx context.Context, comment model.Comment) (model.Comment, error) {
+	err := connection(ctx, r.db).WithContext(ctx).Model(&model.Comment{}).Where("id = ?", comment.ID).Updates(map[string]any{
+		"message":     comment.Message,
+		"raw_message": comment.RawMessage,
+		"updated_at":  comment.UpdatedAt,
+	}).Error
+	return comment, err
+}
+
+func (r *commentRepositoryImpl) DeleteComment(ctx context.Context, id uint64) error {
+	err := connection(ctx, r.db).WithContext(ctx).Where("id = ?", id).Delete(&model.Comment{}).Error
+	return err
+}
+
+// GetLatestComments returns the perPhoto most recent comments of each photo
+// with their author, in a single query
+func (r *commentRepositoryImpl) GetLatestComments(ctx context.Context, photoIDs []uint64, perPhoto int) ([]model.Comment, error) {
+	comments := []model.Comment{}
+	if len(photoIDs) == 0 {
+		return comments, nil
+	}
+	db := connection(ctx, r.db).WithContext(ctx)
+	ranked := db.Model(&model.Comment{}).
+		Select("comments.*, ROW_NUMBER() OVER (PARTITION BY photo_id ORDER BY created_at DESC, id DESC) AS comment_rank").
+		Where("photo_id IN ?", photoIDs)
+	err := db.Table("(?) AS comments", ranked).
+		Preload("User").
+		Where("comment_rank <= ?", perPhoto).
+		Order("photo_id, created_at DESC, id DESC").
+		Find(&comments).Error
+	return comments, err
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"go-mygram/internal/infrastructure/mocks"
+	"go-mygram/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetComments(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	// comments of photos the viewer can't see are left out
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "comments" JOIN photos ON photos.id = comments.photo_id AND photos.deleted_at IS NULL WHERE comments.photo_id = $1 AND (comments.created_at, comments.id) > ($2, $3) AND (photos.visibility = $4 OR photos.user_id = $5 OR (photos.visibility = $6 AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $7 AND follows.followee_id = photos.user_id))) AND "comments"."deleted_at" IS NULL ORDER BY comments.created_at, comments.id LIMIT $8`)).
+		WithArgs(1, createdAt, 9, "public", 7, "followers", 7, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "photo_id"}))
+
+	commentRepo := commentRepositoryImpl{db: postgresMock}
+	comments, err := commentRepo.GetComments(context.Background(), 7, 1, &pkg.Cursor{CreatedAt: createdAt, ID: 9}, 21)
+	assert.Nil(t, err)
+	assert.Empty(t, comments)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPhotoComments(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE photo_id = $1 AND (created_at, id) < ($2, $3) AND "comments"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $4`)).
+		WithArgs(1, createdAt, 9, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "photo_id", "user_id"}).AddRow(8, 1, 4))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(4, "alice"))
+
+	commentRepo := commentRepositoryImpl{db: postgresMock}
+	comments, err := commentRepo.GetPhotoComments(context.Background(), 1, &pkg.Cursor{CreatedAt: createdAt, ID: 9}, 21)
+	assert.Nil(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, "alice", comments[0].User.Username)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLatestComments(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM (SELECT comments.*, ROW_NUMBER() OVER (PARTITION BY photo_id ORDER BY created_at DESC, id DESC) AS comment_rank FROM "comments" WHERE photo_id IN ($1,$2) AND "comments"."deleted_at" IS NULL) AS comments WHERE comment_rank <= $3`)).
+		WithArgs(1, 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "photo_id", "user_id"}).AddRow(5, 1, 4))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(4, "alice"))
+
+	commentRepo := commentRepositoryImpl{db: postgresMock}
+	comments, err := commentRepo.GetLatestComments(context.Background(), []uint64{1, 2}, 3)
+	assert.Nil(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, "alice", comments[0].User.Username)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
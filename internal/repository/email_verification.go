@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"go-mygram/internal/infrastructure"
+	"go-mygram/internal/model"
+)
+
+type EmailVerificationRepository interface {
+	CreateToken(ctx context.Context, token model.EmailVerificationToken) (model.EmailVerificationToken, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (model.EmailVerificationToken, error)
+	DeleteByUserID(ctx context.Context, userID uint64) error
+}
+
+type emailVerificationRepositoryImpl struct {
+	db infrastructure.GormPostgres
+}
+
+func NewEmailVerificationRepository(db infrastructure.GormPostgres) EmailVerificationRepository {
+	return &emailVerificationRepositoryImpl{db: db}
+}
+
+func (r *emailVerificationRepositoryImpl) CreateToken(ctx context.Context, token model.EmailVerificationToken) (model.EmailVerificationToken, error) {
+	db := connection(ctx, r.db)
+	if err := db.
+		WithContext(ctx).
+		Create(&token).Error; err != nil {
+		return model.EmailVerificationToken{}, err
+	}
+	return token, nil
+}
+
+func (r *emailVerificationRepositoryImpl) FindByTokenHash(ctx context.Context, tokenHash string) (model.EmailVerificationToken, error) {
+	db := connection(ctx, r.db)
+	token := model.EmailVerificationToken{}
+	if err := db.
+		WithContext(ctx).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error; err != nil {
+		return model.EmailVerificationToken{}, err
+	}
+	return token, nil
+}
+
+// DeleteByUserID drops every token of the user, they are useless once the
+// email is verified
+func (r *emailVerificationRepositoryImpl) DeleteByUserID(ctx context.Context, userID uint64) error {
+	db := connection(ctx, r.db)
+	return db.
+		WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&model.EmailVerificationToken{}).Error
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrDuplicateEmail    = errors.New("duplicate email")
+	ErrDuplicateUsername = errors.New("duplicate username")
+	// the row changed after it was read
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// postgres error code for unique_violation
+const pgUniqueViolation = "23505"
+
+// translateUserError maps unique constraint violations on the users table
+// to repository errors, so callers never see the raw sql error
+func translateUserError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return err
+	}
+	switch {
+	case strings.Contains(pgErr.ConstraintName, "email"):
+		return ErrDuplicateEmail
+	case strings.Contains(pgErr.ConstraintName, "username"):
+		return ErrDuplicateUsername
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-mygram/internal/infrastructure"
+	"go-mygram/internal/model"
+	"go-mygram/pkg"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepository interface {
+	CreateFollow(ctx context.Context, follow model.Follow) error
+	DeleteFollow(ctx context.Context, followerID uint64, followeeID uint64) error
+	GetFollowers(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) ([]model.FollowUser, error)
+	GetFollowing(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) ([]model.FollowUser, error)
+	GetFollowCounts(ctx context.Context, userIDs []uint64) (map[uint64]model.FollowCounts, error)
+	IsFollowing(ctx context.Context, followerID uint64, followeeID uint64) (bool, error)
+}
+
+type followRepositoryImpl struct {
+	db infrastructure.GormPostgres
+}
+
+func NewFollowRepository(db infrastructure.GormPostgres) FollowRepository {
+	return &followRepositoryImpl{db: db}
+}
+
+// CreateFollow does nothing when the user already follows the other one
+func (r *followRepositoryImpl) CreateFollow(ctx context.Context, follow model.Follow) error {
+	return connection(ctx, r.db).WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "follower_id"}, {Name: "followee_id"}}, DoNothing: true}).
+		Create(&follow).Error
+}
+
+func (r *followRepositoryImpl) DeleteFollow(ctx context.Context, followerID uint64, followeeID uint64) error {
+	return connection(ctx, r.db).WithContext(ctx).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Delete(&model.Follow{}).Error
+}
+
+func (r *followRepositoryImpl) IsFollowing(ctx context.Context, followerID uint64, followeeID uint64) (bool, error) {
+	var count int64
+	err := connection(ctx, r.db).WithContext(ctx).Model(&model.Follow{}).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetFollowers returns the users following userID, latest follow first
+func (r *followRepositoryImpl) GetFollowers(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) ([]model.FollowUser, error) {
+	return r.listFollows(ctx, "follows.follower_id", "follows.followee_id", userID, after, limit)
+}
+
+// GetFollowing returns the users userID follows, latest follow first
+func (r *followRepositoryImpl) GetFollowing(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) ([]model.FollowUser, error) {
+	return r.listFollows(ctx, "follows.followee_id", "follows.follower_id", userID, after, limit)
+}
+
+// followRow is a user joined with the time of the follow
+type followRow struct {
+	model.User
+	FollowedAt time.Time
+}
+
+// listFollows joins the users on userColumn of the follows where
+// filterColumn is userID, soft deleted users are left out
+func (r *followRepositoryImpl) listFollows(ctx context.Context, userColumn, filterColumn string, userID uint64, after *pkg.Cursor, limit int) ([]model.FollowUser, error) {
+	rows := []followRow{}
+	query := connection(ctx, r.db).WithContext(ctx).
+		Model(&model.User{}).
+		Select("users.*, follows.created_at AS followed_at").
+		Joins("JOIN follows ON "+userColumn+" = users.id").
+		Where(filterColumn+" = ?", userID)
+	if after != nil {
+		query = query.Where("(follows.created_at, users.id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	if err := query.
+		Order("follows.created_at DESC, users.id DESC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	users := make([]model.FollowUser, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, model.FollowUser{PublicUser: row.User.ToPublic(), FollowedAt: row.FollowedAt})
+	}
+	return users, nil
+}
+
+// GetFollowCounts counts followers and followings of each user, follows of
+// soft deleted users are not counted. Users without any follow are missing
+// from the result
+func (r *followRepositoryImpl) GetFollowCounts(ctx context.Context, userIDs []uint64) (map[uint64]model.FollowCounts, error) {
+	counts := map[uint64]model.FollowCounts{}
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+	followers, err := r.countFollows(ctx, "followee_id", "follower_id", userIDs)
+	if err != nil {
+		return nil, err
+	}
+	following, err := r.countFollows(ctx, "follower_id", "followee_id", userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for id, n := range followers {
+		c := counts[id]
+		c.UserID, c.FollowerCount = id, n
+		counts[id] = c
+	}
+	for id, n := range following {
+		c := counts[id]
+		c.UserID, c.FollowingCount = id, n
+		counts[id] = c
+	}
+	return counts, nil
+}
+
+// countFollows groups the follows of userIDs by column, counting only those
+// whose otherColumn user still exists
+func (r *followRepositoryImpl) countFollows(ctx context.Context, column, otherColumn string, userIDs []uint64) (map[uint64]int64, error) {
+	rows := []struct {
+		UserID uint64
+		Count  int64
+	}{}
+	err := connection(ctx, r.db).WithContext(ctx).
+		Model(&model.Follow{}).
+		Select("follows."+column+" AS user_id, COUNT(*) AS count").
+		Joins("JOIN users ON users.id = follows."+otherColumn+" AND users.deleted_at IS NULL").
+		Where("follows."+column+" IN ?", userIDs).
+		Group("follows." + column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.Count
+	}
+	return counts, nil
+}
+
+// deleteFollows removes every follow from or to the user
+func deleteFollows(tx *gorm.DB, id uint64) error {
+	return tx.
+		Where("follower_id = ? OR followee_id = ?", id, id).
+		Delete(&model.Follow{}).Error
+}
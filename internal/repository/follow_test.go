@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"go-mygram/internal/infrastructure/mocks"
+	"go-mygram/internal/model"
+	"go-mygram/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateFollow(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	// following twice hits the primary key and is silently ignored
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "follows" ("follower_id","followee_id","created_at") VALUES ($1,$2,$3) ON CONFLICT ("follower_id","followee_id") DO NOTHING`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	followRepo := followRepositoryImpl{db: postgresMock}
+	err := followRepo.CreateFollow(context.Background(), model.Follow{FollowerID: 1, FolloweeID: 2})
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFollowers(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	followedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT users.*, follows.created_at AS followed_at FROM "users" JOIN follows ON follows.follower_id = users.id WHERE follows.followee_id = $1 AND (follows.created_at, users.id) < ($2, $3) AND "users"."deleted_at" IS NULL ORDER BY follows.created_at DESC, users.id DESC LIMIT $4`)).
+		WithArgs(2, followedAt, 9, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "email", "followed_at"}).AddRow(3, "carol", "carol@mail.com", followedAt.Add(-time.Hour)))
+
+	followRepo := followRepositoryImpl{db: postgresMock}
+	users, err := followRepo.GetFollowers(context.Background(), 2, &pkg.Cursor{CreatedAt: followedAt, ID: 9}, 21)
+	assert.Nil(t, err)
+	// the email is read but never listed
+	assert.Equal(t, []model.FollowUser{{PublicUser: model.PublicUser{ID: 3, Username: "carol"}, FollowedAt: followedAt.Add(-time.Hour)}}, users)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFollowCounts(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT follows.followee_id AS user_id, COUNT(*) AS count FROM "follows" JOIN users ON users.id = follows.follower_id AND users.deleted_at IS NULL WHERE follows.followee_id IN ($1,$2) GROUP BY "follows"."followee_id"`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "count"}).AddRow(1, 4))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT follows.follower_id AS user_id, COUNT(*) AS count FROM "follows" JOIN users ON users.id = follows.followee_id AND users.deleted_at IS NULL WHERE follows.follower_id IN ($1,$2) GROUP BY "follows"."follower_id"`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "count"}).AddRow(1, 2).AddRow(2, 5))
+
+	followRepo := followRepositoryImpl{db: postgresMock}
+	counts, err := followRepo.GetFollowCounts(context.Background(), []uint64{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, map[uint64]model.FollowCounts{
+		1: {UserID: 1, FollowerCount: 4, FollowingCount: 2},
+		2: {UserID: 2, FollowingCount: 5},
+	}, counts)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"go-mygram/internal/infrastructure"
+	"go-mygram/internal/model"
+
+	"gorm.io/gorm/clause"
+)
+
+type LikeRepository interface {
+	CreateLike(ctx context.Context, like model.Like) error
+	DeleteLike(ctx context.Context, userID uint64, photoID uint64) error
+	GetLikeStats(ctx context.Context, viewerID uint64, photoIDs []uint64) (map[uint64]model.LikeStats, error)
+	GetLikers(ctx context.Context, photoID uint64) ([]model.User, error)
+}
+
+type likeRepositoryImpl struct {
+	db infrastructure.GormPostgres
+}
+
+func NewLikeRepository(db infrastructure.GormPostgres) LikeRepository {
+	return &likeRepositoryImpl{db: db}
+}
+
+// CreateLike does nothing when the user already likes the photo
+func (r *likeRepositoryImpl) CreateLike(ctx context.Context, like model.Like) error {
+	return connection(ctx, r.db).WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "photo_id"}}, DoNothing: true}).
+		Create(&like).Error
+}
+
+func (r *likeRepositoryImpl) DeleteLike(ctx context.Context, userID uint64, photoID uint64) error {
+	return connection(ctx, r.db).WithContext(ctx).
+		Where("user_id = ? AND photo_id = ?", userID, photoID).
+		Delete(&model.Like{}).Error
+}
+
+// GetLikeStats counts the likes of each photo in a single query, likes of
+// soft deleted users are not counted. Photos without likes are missing
+// from the result
+func (r *likeRepositoryImpl) GetLikeStats(ctx context.Context, viewerID uint64, photoIDs []uint64) (map[uint64]model.LikeStats, error) {
+	stats := map[uint64]model.LikeStats{}
+	if len(photoIDs) == 0 {
+		return stats, nil
+	}
+	rows := []model.LikeStats{}
+	err := connection(ctx, r.db).WithContext(ctx).
+		Model(&model.Like{}).
+		Select("likes.photo_id, COUNT(*) AS like_count, BOOL_OR(likes.user_id = ?) AS liked_by_me", viewerID).
+		Joins("JOIN users ON users.id = likes.user_id AND users.deleted_at IS NULL").
+		Where("likes.photo_id IN ?", photoIDs).
+		Group("likes.photo_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stats[row.PhotoID] = row
+	}
+	return stats, nil
+}
+
+// GetLikers returns the users who like the photo, latest like first
+func (r *likeRepositoryImpl) GetLikers(ctx context.Context, photoID uint64) ([]model.User, error) {
+	users := []model.User{}
+	err := connection(ctx, r.db).WithContext(ctx).
+		Joins("JOIN likes ON likes.user_id = users.id").
+		Where("likes.photo_id = ?", photoID).
+		Order("likes.created_at DESC").
+		Find(&users).Error
+	return users, err
+}
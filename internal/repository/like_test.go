@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"go-mygram/internal/infrastructure/mocks"
+	"go-mygram/internal/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateLike(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	// liking twice hits the unique pair and is silently ignored
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "likes" ("user_id","photo_id","created_at") VALUES ($1,$2,$3) ON CONFLICT ("user_id","photo_id") DO NOTHING RETURNING "id"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	likeRepo := likeRepositoryImpl{db: postgresMock}
+	err := likeRepo.CreateLike(context.Background(), model.Like{UserID: 1, PhotoID: 2})
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateLikeTwiceCountsOnce(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	upsert := regexp.QuoteMeta(`INSERT INTO "likes" ("user_id","photo_id","created_at") VALUES ($1,$2,$3) ON CONFLICT ("user_id","photo_id") DO NOTHING RETURNING "id"`)
+	// the first like inserts a row, the repeat conflicts and returns none
+	mock.ExpectBegin()
+	mock.ExpectQuery(upsert).
+		WithArgs(1, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(upsert).
+		WithArgs(1, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT likes.photo_id, COUNT(*) AS like_count, BOOL_OR(likes.user_id = $1) AS liked_by_me FROM "likes" JOIN users ON users.id = likes.user_id AND users.deleted_at IS NULL WHERE likes.photo_id IN ($2) GROUP BY "likes"."photo_id"`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"photo_id", "like_count", "liked_by_me"}).AddRow(2, 1, true))
+
+	likeRepo := likeRepositoryImpl{db: postgresMock}
+	assert.Nil(t, likeRepo.CreateLike(context.Background(), model.Like{UserID: 1, PhotoID: 2}))
+	assert.Nil(t, likeRepo.CreateLike(context.Background(), model.Like{UserID: 1, PhotoID: 2}))
+	stats, err := likeRepo.GetLikeStats(context.Background(), 1, []uint64{2})
+	assert.Nil(t, err)
+	assert.Equal(t, map[uint64]model.LikeStats{2: {PhotoID: 2, LikeCount: 1, LikedByMe: true}}, stats)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLikeStats(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT likes.photo_id, COUNT(*) AS like_count, BOOL_OR(likes.user_id = $1) AS liked_by_me FROM "likes" JOIN users ON users.id = likes.user_id AND users.deleted_at IS NULL WHERE likes.photo_id IN ($2,$3) GROUP BY "likes"."photo_id"`)).
+		WithArgs(7, 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"photo_id", "like_count", "liked_by_me"}).AddRow(1, 3, true))
+
+	likeRepo := likeRepositoryImpl{db: postgresMock}
+	stats, err := likeRepo.GetLikeStats(context.Background(), 7, []uint64{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, map[uint64]model.LikeStats{1: {PhotoID: 1, LikeCount: 3, LikedByMe: true}}, stats)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
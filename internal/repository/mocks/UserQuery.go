@@ -4,9 +4,11 @@ package mocks
 
 import (
 	context "context"
-
 	model "go-mygram/internal/model"
+
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // UserQuery is an autogenerated mock type for the UserQuery type
@@ -14,6 +16,34 @@ type UserQuery struct {
 	mock.Mock
 }
 
+// CountUsers provides a mock function with given fields: ctx, params
+func (_m *UserQuery) CountUsers(ctx context.Context, params model.UserCountParams) (int64, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUsers")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserCountParams) (int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserCountParams) int64); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.UserCountParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateUser provides a mock function with given fields: ctx, user
 func (_m *UserQuery) CreateUser(ctx context.Context, user model.User) (model.User, error) {
 	ret := _m.Called(ctx, user)
@@ -60,29 +90,85 @@ func (_m *UserQuery) DeleteUsersByID(ctx context.Context, id uint64) error {
 	return r0
 }
 
-// GetUsers provides a mock function with given fields: ctx
-func (_m *UserQuery) GetUsers(ctx context.Context) ([]model.User, error) {
-	ret := _m.Called(ctx)
+// ExistsByUsername provides a mock function with given fields: ctx, username
+func (_m *UserQuery) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	ret := _m.Called(ctx, username)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUsers")
+		panic("no return value specified for ExistsByUsername")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByEmail provides a mock function with given fields: ctx, email
+func (_m *UserQuery) FindByEmail(ctx context.Context, email string) (model.User, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByEmail")
+	}
+
+	var r0 model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (model.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByUsernames provides a mock function with given fields: ctx, usernames
+func (_m *UserQuery) FindByUsernames(ctx context.Context, usernames []string) ([]model.User, error) {
+	ret := _m.Called(ctx, usernames)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByUsernames")
 	}
 
 	var r0 []model.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]model.User, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]model.User, error)); ok {
+		return rf(ctx, usernames)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []model.User); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []model.User); ok {
+		r0 = rf(ctx, usernames)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, usernames)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -90,6 +176,129 @@ func (_m *UserQuery) GetUsers(ctx context.Context) ([]model.User, error) {
 	return r0, r1
 }
 
+// GetByUsername provides a mock function with given fields: ctx, username
+func (_m *UserQuery) GetByUsername(ctx context.Context, username string) (model.User, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUsername")
+	}
+
+	var r0 model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (model.User, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeletedUserIDs provides a mock function with given fields: ctx, before
+func (_m *UserQuery) GetDeletedUserIDs(ctx context.Context, before time.Time) ([]uint64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeletedUserIDs")
+	}
+
+	var r0 []uint64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]uint64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []uint64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserStats provides a mock function with given fields: ctx, viewerID, id
+func (_m *UserQuery) GetUserStats(ctx context.Context, viewerID uint64, id uint64) (model.UserStats, error) {
+	ret := _m.Called(ctx, viewerID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserStats")
+	}
+
+	var r0 model.UserStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) (model.UserStats, error)); ok {
+		return rf(ctx, viewerID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) model.UserStats); ok {
+		r0 = rf(ctx, viewerID, id)
+	} else {
+		r0 = ret.Get(0).(model.UserStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, viewerID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsers provides a mock function with given fields: ctx, params
+func (_m *UserQuery) GetUsers(ctx context.Context, params model.UserListParams) ([]model.User, int64, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsers")
+	}
+
+	var r0 []model.User
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserListParams) ([]model.User, int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserListParams) []model.User); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.UserListParams) int64); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.UserListParams) error); ok {
+		r2 = rf(ctx, params)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetUsersByID provides a mock function with given fields: ctx, id
 func (_m *UserQuery) GetUsersByID(ctx context.Context, id uint64) (model.User, error) {
 	ret := _m.Called(ctx, id)
@@ -118,6 +327,202 @@ func (_m *UserQuery) GetUsersByID(ctx context.Context, id uint64) (model.User, e
 	return r0, r1
 }
 
+// GetUsersByIDs provides a mock function with given fields: ctx, ids
+func (_m *UserQuery) GetUsersByIDs(ctx context.Context, ids []uint64) ([]model.User, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsersByIDs")
+	}
+
+	var r0 []model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64) ([]model.User, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64) []model.User); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uint64) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HardDeleteUser provides a mock function with given fields: ctx, id
+func (_m *UserQuery) HardDeleteUser(ctx context.Context, id uint64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HardDeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkEmailVerified provides a mock function with given fields: ctx, id
+func (_m *UserQuery) MarkEmailVerified(ctx context.Context, id uint64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkEmailVerified")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecordFailedLogin provides a mock function with given fields: ctx, id, threshold, lockUntil
+func (_m *UserQuery) RecordFailedLogin(ctx context.Context, id uint64, threshold int, lockUntil time.Time) error {
+	ret := _m.Called(ctx, id, threshold, lockUntil)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordFailedLogin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int, time.Time) error); ok {
+		r0 = rf(ctx, id, threshold, lockUntil)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RecordLogin provides a mock function with given fields: ctx, id, at
+func (_m *UserQuery) RecordLogin(ctx context.Context, id uint64, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordLogin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchUsernames provides a mock function with given fields: ctx, prefix, limit
+func (_m *UserQuery) SearchUsernames(ctx context.Context, prefix string, limit int) ([]model.MentionedUser, error) {
+	ret := _m.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchUsernames")
+	}
+
+	var r0 []model.MentionedUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]model.MentionedUser, error)); ok {
+		return rf(ctx, prefix, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []model.MentionedUser); ok {
+		r0 = rf(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MentionedUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAvatar provides a mock function with given fields: ctx, id, url
+func (_m *UserQuery) UpdateAvatar(ctx context.Context, id uint64, url string) error {
+	ret := _m.Called(ctx, id, url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAvatar")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string) error); ok {
+		r0 = rf(ctx, id, url)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePassword provides a mock function with given fields: ctx, id, hash
+func (_m *UserQuery) UpdatePassword(ctx context.Context, id uint64, hash string) error {
+	ret := _m.Called(ctx, id, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string) error); ok {
+		r0 = rf(ctx, id, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateUserIfVersion provides a mock function with given fields: ctx, user, version
+func (_m *UserQuery) UpdateUserIfVersion(ctx context.Context, user model.User, version int64) (model.User, error) {
+	ret := _m.Called(ctx, user, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserIfVersion")
+	}
+
+	var r0 model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.User, int64) (model.User, error)); ok {
+		return rf(ctx, user, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.User, int64) model.User); ok {
+		r0 = rf(ctx, user, version)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.User, int64) error); ok {
+		r1 = rf(ctx, user, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewUserQuery creates a new instance of UserQuery. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserQuery(t interface {
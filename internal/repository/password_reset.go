@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"go-mygram/internal/infrastructure"
+	"go-mygram/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PasswordResetRepository interface {
+	CreateToken(ctx context.Context, token model.PasswordResetToken) (model.PasswordResetToken, error)
+	ConsumeToken(ctx context.Context, tokenHash string) (model.PasswordResetToken, error)
+	DeleteByUserID(ctx context.Context, userID uint64) error
+}
+
+type passwordResetRepositoryImpl struct {
+	db infrastructure.GormPostgres
+}
+
+func NewPasswordResetRepository(db infrastructure.GormPostgres) PasswordResetRepository {
+	return &passwordResetRepositoryImpl{db: db}
+}
+
+func (r *passwordResetRepositoryImpl) CreateToken(ctx context.Context, token model.PasswordResetToken) (model.PasswordResetToken, error) {
+	db := connection(ctx, r.db)
+	if err := db.
+		WithContext(ctx).
+		Create(&token).Error; err != nil {
+		return model.PasswordResetToken{}, err
+	}
+	return token, nil
+}
+
+// ConsumeToken deletes the token and returns it, so two requests racing
+// with the same token can't both succeed. It returns gorm.ErrRecordNotFound
+// when no such token exists
+func (r *passwordResetRepositoryImpl) ConsumeToken(ctx context.Context, tokenHash string) (model.PasswordResetToken, error) {
+	db := connection(ctx, r.db)
+	tokens := []model.PasswordResetToken{}
+	if err := db.
+		WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ?", tokenHash).
+		Delete(&tokens).Error; err != nil {
+		return model.PasswordResetToken{}, err
+	}
+	if len(tokens) == 0 {
+		return model.PasswordResetToken{}, gorm.ErrRecordNotFound
+	}
+	return tokens[0], nil
+}
+
+func (r *passwordResetRepositoryImpl) DeleteByUserID(ctx context.Context, userID uint64) error {
+	db := connection(ctx, r.db)
+	return db.
+		WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&model.PasswordResetToken{}).Error
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"go-mygram/internal/infrastructure/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestConsumeToken(t *testing.T) {
+	t.Run("success delete and return the token", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "password_reset_tokens" WHERE token_hash = $1 RETURNING *`)).
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash"}).AddRow(3, 7, "hash"))
+		mock.ExpectCommit()
+
+		repo := passwordResetRepositoryImpl{db: postgresMock}
+		token, err := repo.ConsumeToken(context.Background(), "hash")
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(7), token.UserID)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error token already used", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "password_reset_tokens" WHERE token_hash = $1 RETURNING *`)).
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash"}))
+		mock.ExpectCommit()
+
+		repo := passwordResetRepositoryImpl{db: postgresMock}
+		_, err := repo.ConsumeToken(context.Background(), "hash")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
@@ -2,17 +2,27 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"go-mygram/internal/infrastructure"
 	"go-mygram/internal/model"
+	"go-mygram/pkg"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PhotoRepository interface {
-	GetPhotos(ctx context.Context) ([]model.Photo, error)
+	GetPhotos(ctx context.Context, viewerID uint64, tag string, after *pkg.Cursor, limit int) ([]model.Photo, error)
+	GetPhotosByUserID(ctx context.Context, viewerID uint64, userID uint64, after *pkg.Cursor, limit int) ([]model.Photo, error)
+	GetPopularPhotos(ctx context.Context, viewerID uint64, tag string, since time.Time, after *pkg.Cursor, limit int) ([]model.Photo, error)
+	GetFollowingPhotos(ctx context.Context, viewerID uint64, after *pkg.Cursor, limit int) ([]model.Photo, error)
 	GetPhotoByID(ctx context.Context, id uint64) (model.Photo, error)
 	UpdatePhoto(ctx context.Context, photo model.Photo) (model.Photo, error)
 	DeletePhotoByID(ctx context.Context, id uint64) error
 	CreatePhoto(ctx context.Context, photo model.Photo) (model.Photo, error)
+	CountPhotosByUserID(ctx context.Context, userID uint64) (int64, error)
+	GetPopularTags(ctx context.Context, limit int) ([]model.TagCount, error)
 }
 
 type photoRepositoryImpl struct {
@@ -23,40 +33,125 @@ func NewPhotoRepository(db infrastructure.GormPostgres) PhotoRepository {
 	return &photoRepositoryImpl{db: db}
 }
 
-func (p *photoRepositoryImpl) GetPhotos(ctx context.Context) ([]model.Photo, error) {
-	db := p.db.GetConnection()
+// GetPhotos returns up to limit photos of every user that viewerID may see,
+// newest first, starting right after the cursor when one is given. A non
+// empty tag keeps the photos carrying it
+func (p *photoRepositoryImpl) GetPhotos(ctx context.Context, viewerID uint64, tag string, after *pkg.Cursor, limit int) ([]model.Photo, error) {
+	db := connection(ctx, p.db)
 	photos := []model.Photo{}
-	if err := db.
-		WithContext(ctx).
+	query := db.WithContext(ctx).Preload("Mentions").Preload("Tags").Scopes(visibleTo(viewerID), taggedWith(tag))
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&photos).Error; err != nil {
+		return nil, err
+	}
+	return photos, nil
+}
+
+// GetPopularPhotos is GetPhotos restricted to photos posted since since and
+// ranked by like count, the newest first between equal counts
+func (p *photoRepositoryImpl) GetPopularPhotos(ctx context.Context, viewerID uint64, tag string, since time.Time, after *pkg.Cursor, limit int) ([]model.Photo, error) {
+	db := connection(ctx, p.db)
+	photos := []model.Photo{}
+	query := db.WithContext(ctx).Preload("Mentions").Preload("Tags").Scopes(visibleTo(viewerID), taggedWith(tag)).
+		Joins("LEFT JOIN likes ON likes.photo_id = photos.id").
+		Where("photos.created_at >= ?", since).
+		Group("photos.id")
+	if after != nil {
+		query = query.Having("(COUNT(likes.id), photos.created_at, photos.id) < (?, ?, ?)", after.Score, after.CreatedAt, after.ID)
+	}
+	if err := query.
+		Order("COUNT(likes.id) DESC, photos.created_at DESC, photos.id DESC").
+		Limit(limit).
+		Find(&photos).Error; err != nil {
+		return nil, err
+	}
+	return photos, nil
+}
+
+// GetPhotosByUserID is GetPhotos restricted to the photos of userID
+func (p *photoRepositoryImpl) GetPhotosByUserID(ctx context.Context, viewerID uint64, userID uint64, after *pkg.Cursor, limit int) ([]model.Photo, error) {
+	db := connection(ctx, p.db)
+	photos := []model.Photo{}
+	query := db.WithContext(ctx).Preload("Mentions").Preload("Tags").Scopes(visibleTo(viewerID)).Where("user_id = ?", userID)
+	if after != nil {
+		query = query.Where("(created_at, id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&photos).Error; err != nil {
+		return nil, err
+	}
+	return photos, nil
+}
+
+// GetFollowingPhotos is GetPhotos restricted to the users viewerID follows
+func (p *photoRepositoryImpl) GetFollowingPhotos(ctx context.Context, viewerID uint64, after *pkg.Cursor, limit int) ([]model.Photo, error) {
+	db := connection(ctx, p.db)
+	photos := []model.Photo{}
+	// a follower sees every photo but the private ones, the join rides on the
+	// follows primary key and idx_photos_user_id_created_at
+	query := db.WithContext(ctx).Preload("Mentions").Preload("Tags").
+		Joins("JOIN follows ON follows.followee_id = photos.user_id AND follows.follower_id = ?", viewerID).
+		Where("photos.visibility <> ?", model.VisibilityPrivate)
+	if after != nil {
+		query = query.Where("(photos.created_at, photos.id) < (?, ?)", after.CreatedAt, after.ID)
+	}
+	if err := query.
+		Order("photos.created_at DESC, photos.id DESC").
+		Limit(limit).
 		Find(&photos).Error; err != nil {
 		return nil, err
 	}
 	return photos, nil
 }
 
+// GetPhotoByID doesn't filter on visibility, the service decides what the
+// viewer gets to know about a hidden photo
 func (p *photoRepositoryImpl) GetPhotoByID(ctx context.Context, id uint64) (model.Photo, error) {
-	db := p.db.GetConnection()
+	db := connection(ctx, p.db)
 	photo := model.Photo{}
 	if err := db.
 		WithContext(ctx).
+		Preload("Mentions").Preload("Tags").
 		First(&photo, id).Error; err != nil {
 		return model.Photo{}, err
 	}
 	return photo, nil
 }
 
+// UpdatePhoto saves the photo and replaces its mentions and tags with
+// photo.Mentions and photo.Tags
 func (p *photoRepositoryImpl) UpdatePhoto(ctx context.Context, photo model.Photo) (model.Photo, error) {
-	db := p.db.GetConnection()
-	if err := db.
-		WithContext(ctx).
-		Save(&photo).Error; err != nil {
+	db := connection(ctx, p.db)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Mentions", "Tags").Save(&photo).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id = ?", photo.ID).Delete(&model.PhotoMention{}).Error; err != nil {
+			return err
+		}
+		if err := createMentions(tx, photo); err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id = ?", photo.ID).Delete(&model.PhotoTag{}).Error; err != nil {
+			return err
+		}
+		return createTags(tx, &photo)
+	})
+	if err != nil {
 		return model.Photo{}, err
 	}
 	return photo, nil
 }
 
 func (p *photoRepositoryImpl) DeletePhotoByID(ctx context.Context, id uint64) error {
-	db := p.db.GetConnection()
+	db := connection(ctx, p.db)
 	if err := db.
 		WithContext(ctx).
 		Delete(&model.Photo{}, id).Error; err != nil {
@@ -65,12 +160,109 @@ func (p *photoRepositoryImpl) DeletePhotoByID(ctx context.Context, id uint64) er
 	return nil
 }
 
+// CountPhotosByUserID counts the photos userID kept, deleted ones excluded
+func (p *photoRepositoryImpl) CountPhotosByUserID(ctx context.Context, userID uint64) (int64, error) {
+	var count int64
+	err := connection(ctx, p.db).WithContext(ctx).
+		Model(&model.Photo{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
 func (p *photoRepositoryImpl) CreatePhoto(ctx context.Context, photo model.Photo) (model.Photo, error) {
-	db := p.db.GetConnection()
-	if err := db.
-		WithContext(ctx).
-		Create(&photo).Error; err != nil {
+	db := connection(ctx, p.db)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// users and tags are only linked, never written through the
+		// association
+		if err := tx.Omit("Mentions", "Tags").Create(&photo).Error; err != nil {
+			return err
+		}
+		if err := createMentions(tx, photo); err != nil {
+			return err
+		}
+		return createTags(tx, &photo)
+	})
+	if err != nil {
 		return model.Photo{}, err
 	}
 	return photo, nil
 }
+
+// GetPopularTags ranks the tags by the number of public photos carrying
+// them, tags only used on hidden photos are never listed
+func (p *photoRepositoryImpl) GetPopularTags(ctx context.Context, limit int) ([]model.TagCount, error) {
+	tags := []model.TagCount{}
+	err := connection(ctx, p.db).WithContext(ctx).
+		Table("tags").
+		Select("tags.name, COUNT(*) AS photo_count").
+		Joins("JOIN photo_tags ON photo_tags.tag_id = tags.id").
+		Joins("JOIN photos ON photos.id = photo_tags.photo_id AND photos.deleted_at IS NULL AND photos.visibility = ?", model.VisibilityPublic).
+		Group("tags.name").
+		Order("photo_count DESC, tags.name").
+		Limit(limit).
+		Scan(&tags).Error
+	return tags, err
+}
+
+// visibleTo keeps the public photos, those of viewerID and the followers
+// only photos of the users viewerID follows
+func visibleTo(viewerID uint64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("photos.visibility = ? OR photos.user_id = ? OR (photos.visibility = ? AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = ? AND follows.followee_id = photos.user_id))",
+			model.VisibilityPublic, viewerID, model.VisibilityFollowers, viewerID)
+	}
+}
+
+// taggedWith keeps the photos carrying tag, every photo when tag is empty
+func taggedWith(tag string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tag == "" {
+			return db
+		}
+		return db.Where("EXISTS (SELECT 1 FROM photo_tags JOIN tags ON tags.id = photo_tags.tag_id WHERE photo_tags.photo_id = photos.id AND tags.name = ?)", tag)
+	}
+}
+
+// createTags links the photo to its tags by name, creating the missing
+// ones, and fills in their ids
+func createTags(tx *gorm.DB, photo *model.Photo) error {
+	if len(photo.Tags) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(photo.Tags))
+	for _, tag := range photo.Tags {
+		names = append(names, tag.Name)
+	}
+	// the unique name settles two photos creating the same tag at once
+	if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(&photo.Tags).Error; err != nil {
+		return err
+	}
+	// ids of the tags that already existed are not returned by the insert
+	var stored []model.Tag
+	if err := tx.Where("name IN ?", names).Find(&stored).Error; err != nil {
+		return err
+	}
+	ids := make(map[string]uint64, len(stored))
+	for _, tag := range stored {
+		ids[tag.Name] = tag.ID
+	}
+	links := make([]model.PhotoTag, 0, len(photo.Tags))
+	for i := range photo.Tags {
+		photo.Tags[i].ID = ids[photo.Tags[i].Name]
+		links = append(links, model.PhotoTag{PhotoID: photo.ID, TagID: photo.Tags[i].ID})
+	}
+	return tx.Create(&links).Error
+}
+
+func createMentions(tx *gorm.DB, photo model.Photo) error {
+	if len(photo.Mentions) == 0 {
+		return nil
+	}
+	mentions := make([]model.PhotoMention, 0, len(photo.Mentions))
+	for _, user := range photo.Mentions {
+		mentions = append(mentions, model.PhotoMention{PhotoID: photo.ID, UserID: user.ID})
+	}
+	return tx.Create(&mentions).Error
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"go-mygram/internal/infrastructure/mocks"
+	"go-mygram/internal/model"
+	"go-mygram/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPhotos(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "photos" WHERE (created_at, id) < ($1, $2) AND (photos.visibility = $3 OR photos.user_id = $4 OR (photos.visibility = $5 AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $6 AND follows.followee_id = photos.user_id))) AND (EXISTS (SELECT 1 FROM photo_tags JOIN tags ON tags.id = photo_tags.tag_id WHERE photo_tags.photo_id = photos.id AND tags.name = $7)) AND "photos"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $8`)).
+		WithArgs(createdAt, 9, "public", 3, "followers", 3, "sunset", 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(8, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "photo_mentions" WHERE "photo_mentions"."photo_id" = $1`)).
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"photo_id", "user_id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "photo_tags" WHERE "photo_tags"."photo_id" = $1`)).
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"photo_id", "tag_id"}).AddRow(8, 2))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE "tags"."id" = $1`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "sunset"))
+
+	photoRepo := photoRepositoryImpl{db: postgresMock}
+	photos, err := photoRepo.GetPhotos(context.Background(), 3, "sunset", &pkg.Cursor{CreatedAt: createdAt, ID: 9}, 21)
+	assert.Nil(t, err)
+	assert.Len(t, photos, 1)
+	assert.Equal(t, []model.Tag{{ID: 2, Name: "sunset"}}, photos[0].Tags)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPopularPhotos(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	since := time.Date(2024, 4, 24, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "photos"."id","photos"."title","photos"."caption","photos"."raw_caption","photos"."photo_url","photos"."user_id","photos"."visibility","photos"."created_at","photos"."updated_at","photos"."deleted_at" FROM "photos" LEFT JOIN likes ON likes.photo_id = photos.id WHERE photos.created_at >= $1 AND (photos.visibility = $2 OR photos.user_id = $3 OR (photos.visibility = $4 AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $5 AND follows.followee_id = photos.user_id))) AND "photos"."deleted_at" IS NULL GROUP BY "photos"."id" HAVING (COUNT(likes.id), photos.created_at, photos.id) < ($6, $7, $8) ORDER BY COUNT(likes.id) DESC, photos.created_at DESC, photos.id DESC LIMIT $9`)).
+		WithArgs(since, "public", 3, "followers", 3, 4, createdAt, 9, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}))
+
+	photoRepo := photoRepositoryImpl{db: postgresMock}
+	photos, err := photoRepo.GetPopularPhotos(context.Background(), 3, "", since, &pkg.Cursor{Score: 4, CreatedAt: createdAt, ID: 9}, 21)
+	assert.Nil(t, err)
+	assert.Empty(t, photos)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFollowingPhotos(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "photos"."id","photos"."title","photos"."caption","photos"."raw_caption","photos"."photo_url","photos"."user_id","photos"."visibility","photos"."created_at","photos"."updated_at","photos"."deleted_at" FROM "photos" JOIN follows ON follows.followee_id = photos.user_id AND follows.follower_id = $1 WHERE photos.visibility <> $2 AND (photos.created_at, photos.id) < ($3, $4) AND "photos"."deleted_at" IS NULL ORDER BY photos.created_at DESC, photos.id DESC LIMIT $5`)).
+		WithArgs(3, "private", createdAt, 9, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}))
+
+	photoRepo := photoRepositoryImpl{db: postgresMock}
+	photos, err := photoRepo.GetFollowingPhotos(context.Background(), 3, &pkg.Cursor{CreatedAt: createdAt, ID: 9}, 21)
+	assert.Nil(t, err)
+	assert.Empty(t, photos)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestPhotoPagesIdenticalTimestamps(t *testing.T) {
+	// three photos posted within the same microsecond fill the first page,
+	// the second must continue from the id of the last one
+	same := time.Date(2024, 5, 2, 10, 0, 0, 123456000, time.UTC)
+	visible := `(photos.visibility = $%d OR photos.user_id = $%d OR (photos.visibility = $%d AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $%d AND follows.followee_id = photos.user_id)))`
+	testCases := []struct {
+		desc      string
+		firstSQL  string
+		firstArgs []driver.Value
+		nextSQL   string
+		nextArgs  []driver.Value
+		page      func(repo photoRepositoryImpl, after *pkg.Cursor) ([]model.Photo, error)
+	}{
+		{
+			desc:      "feed and photo list",
+			firstSQL:  `SELECT * FROM "photos" WHERE ` + fmt.Sprintf(visible, 1, 2, 3, 4) + ` AND "photos"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $5`,
+			firstArgs: []driver.Value{"public", 3, "followers", 3, 3},
+			nextSQL:   `SELECT * FROM "photos" WHERE (created_at, id) < ($1, $2) AND ` + fmt.Sprintf(visible, 3, 4, 5, 6) + ` AND "photos"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $7`,
+			nextArgs:  []driver.Value{same, 6, "public", 3, "followers", 3, 3},
+			page: func(repo photoRepositoryImpl, after *pkg.Cursor) ([]model.Photo, error) {
+				return repo.GetPhotos(context.Background(), 3, "", after, 3)
+			},
+		},
+		{
+			desc:      "following feed",
+			firstSQL:  `FROM "photos" JOIN follows ON follows.followee_id = photos.user_id AND follows.follower_id = $1 WHERE photos.visibility <> $2 AND "photos"."deleted_at" IS NULL ORDER BY photos.created_at DESC, photos.id DESC LIMIT $3`,
+			firstArgs: []driver.Value{3, "private", 3},
+			nextSQL:   `FROM "photos" JOIN follows ON follows.followee_id = photos.user_id AND follows.follower_id = $1 WHERE photos.visibility <> $2 AND (photos.created_at, photos.id) < ($3, $4) AND "photos"."deleted_at" IS NULL ORDER BY photos.created_at DESC, photos.id DESC LIMIT $5`,
+			nextArgs:  []driver.Value{3, "private", same, 6, 3},
+			page: func(repo photoRepositoryImpl, after *pkg.Cursor) ([]model.Photo, error) {
+				return repo.GetFollowingPhotos(context.Background(), 3, after, 3)
+			},
+		},
+		{
+			desc:      "photos of a user",
+			firstSQL:  `SELECT * FROM "photos" WHERE user_id = $1 AND ` + fmt.Sprintf(visible, 2, 3, 4, 5) + ` AND "photos"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $6`,
+			firstArgs: []driver.Value{4, "public", 3, "followers", 3, 3},
+			nextSQL:   `SELECT * FROM "photos" WHERE user_id = $1 AND (created_at, id) < ($2, $3) AND ` + fmt.Sprintf(visible, 4, 5, 6, 7) + ` AND "photos"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $8`,
+			nextArgs:  []driver.Value{4, same, 6, "public", 3, "followers", 3, 3},
+			page: func(repo photoRepositoryImpl, after *pkg.Cursor) ([]model.Photo, error) {
+				return repo.GetPhotosByUserID(context.Background(), 3, 4, after, 3)
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			db, mock := newMockGorm()
+			postgresMock := mocks.NewGormPostgres(t)
+			postgresMock.On("GetConnection").Return(db)
+
+			mock.ExpectQuery(regexp.QuoteMeta(tC.firstSQL)).
+				WithArgs(tC.firstArgs...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}).AddRow(8, 4, same).AddRow(7, 4, same).AddRow(6, 4, same))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "photo_mentions" WHERE "photo_mentions"."photo_id" IN ($1,$2,$3)`)).
+				WithArgs(8, 7, 6).
+				WillReturnRows(sqlmock.NewRows([]string{"photo_id", "user_id"}))
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "photo_tags" WHERE "photo_tags"."photo_id" IN ($1,$2,$3)`)).
+				WithArgs(8, 7, 6).
+				WillReturnRows(sqlmock.NewRows([]string{"photo_id", "tag_id"}))
+			mock.ExpectQuery(regexp.QuoteMeta(tC.nextSQL)).
+				WithArgs(tC.nextArgs...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "created_at"}))
+
+			repo := photoRepositoryImpl{db: postgresMock}
+			first, err := tC.page(repo, nil)
+			assert.Nil(t, err)
+			assert.Len(t, first, 3)
+
+			// the cursor goes through the client the way NextCursor does
+			after, err := pkg.DecodeCursor(first[len(first)-1].Cursor().Encode())
+			assert.Nil(t, err)
+			next, err := tC.page(repo, &after)
+			assert.Nil(t, err)
+			assert.Empty(t, next)
+			assert.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetPhotosByUserID(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "photos" WHERE user_id = $1 AND (photos.visibility = $2 OR photos.user_id = $3 OR (photos.visibility = $4 AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $5 AND follows.followee_id = photos.user_id))) AND "photos"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $6`)).
+		WithArgs(4, "public", 3, "followers", 3, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}))
+
+	photoRepo := photoRepositoryImpl{db: postgresMock}
+	photos, err := photoRepo.GetPhotosByUserID(context.Background(), 3, 4, nil, 21)
+	assert.Nil(t, err)
+	assert.Empty(t, photos)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestCreatePhotoTags(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "photos"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"visibility", "id"}).AddRow("public", 8))
+	// beach already exists, only sunset is inserted
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tags" ("name","created_at") VALUES ($1,$2),($3,$4) ON CONFLICT ("name") DO NOTHING RETURNING "id"`)).
+		WithArgs("sunset", sqlmock.AnyArg(), "beach", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE name IN ($1,$2)`)).
+		WithArgs("sunset", "beach").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "beach").AddRow(5, "sunset"))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "photo_tags" ("photo_id","tag_id") VALUES ($1,$2),($3,$4)`)).
+		WithArgs(8, 5, 8, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	photoRepo := photoRepositoryImpl{db: postgresMock}
+	photo, err := photoRepo.CreatePhoto(context.Background(), model.Photo{Title: "title", UserID: 1, Tags: []model.Tag{{Name: "sunset"}, {Name: "beach"}}})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{5, 2}, []uint64{photo.Tags[0].ID, photo.Tags[1].ID})
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPopularTags(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT tags.name, COUNT(*) AS photo_count FROM "tags" JOIN photo_tags ON photo_tags.tag_id = tags.id JOIN photos ON photos.id = photo_tags.photo_id AND photos.deleted_at IS NULL AND photos.visibility = $1 GROUP BY "tags"."name" ORDER BY photo_count DESC, tags.name LIMIT $2`)).
+		WithArgs("public", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "photo_count"}).AddRow("sunset", 4).AddRow("beach", 2))
+
+	photoRepo := photoRepositoryImpl{db: postgresMock}
+	tags, err := photoRepo.GetPopularTags(context.Background(), 20)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.TagCount{{Name: "sunset", PhotoCount: 4}, {Name: "beach", PhotoCount: 2}}, tags)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestCountPhotosByUserID(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "photos" WHERE user_id = $1 AND "photos"."deleted_at" IS NULL`)).
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(500))
+
+	photoRepo := photoRepositoryImpl{db: postgresMock}
+	count, err := photoRepo.CountPhotosByUserID(context.Background(), 4)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(500), count)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go-mygram/internal/infrastructure"
+	"go-mygram/internal/model"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token model.RefreshToken) (model.RefreshToken, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (model.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id uint64) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uint64, keepTokenHash string) error
+}
+
+type refreshTokenRepositoryImpl struct {
+	db infrastructure.GormPostgres
+}
+
+func NewRefreshTokenRepository(db infrastructure.GormPostgres) RefreshTokenRepository {
+	return &refreshTokenRepositoryImpl{db: db}
+}
+
+func (r *refreshTokenRepositoryImpl) CreateRefreshToken(ctx context.Context, token model.RefreshToken) (model.RefreshToken, error) {
+	db := connection(ctx, r.db)
+	if err := db.
+		WithContext(ctx).
+		Create(&token).Error; err != nil {
+		return model.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (r *refreshTokenRepositoryImpl) FindByTokenHash(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	db := connection(ctx, r.db)
+	token := model.RefreshToken{}
+	if err := db.
+		WithContext(ctx).
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error; err != nil {
+		return model.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (r *refreshTokenRepositoryImpl) RevokeRefreshToken(ctx context.Context, id uint64) error {
+	db := connection(ctx, r.db)
+	if err := db.
+		WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token of the user that is
+// still live but the one hashed to keepTokenHash, signing out all of their
+// other sessions. An empty keepTokenHash revokes them all
+func (r *refreshTokenRepositoryImpl) RevokeUserRefreshTokens(ctx context.Context, userID uint64, keepTokenHash string) error {
+	db := connection(ctx, r.db).
+		WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+	if keepTokenHash != "" {
+		db = db.Where("token_hash <> ?", keepTokenHash)
+	}
+	if err := db.Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"go-mygram/internal/infrastructure/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokeUserRefreshTokens(t *testing.T) {
+	t.Run("success revoke every session", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "revoked_at"=$1 WHERE user_id = $2 AND revoked_at IS NULL`)).
+			WithArgs(sqlmock.AnyArg(), 7).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		repo := refreshTokenRepositoryImpl{db: postgresMock}
+		assert.Nil(t, repo.RevokeUserRefreshTokens(context.Background(), 7, ""))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success keep the current session", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "refresh_tokens" SET "revoked_at"=$1 WHERE (user_id = $2 AND revoked_at IS NULL) AND token_hash <> $3`)).
+			WithArgs(sqlmock.AnyArg(), 7, "hash").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		repo := refreshTokenRepositoryImpl{db: postgresMock}
+		assert.Nil(t, repo.RevokeUserRefreshTokens(context.Background(), 7, "hash"))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"go-mygram/internal/infrastructure"
+	"go-mygram/internal/model"
+	"go-mygram/pkg"
+
+	"gorm.io/gorm/clause"
+)
+
+type ReportRepository interface {
+	CreateReport(ctx context.Context, report model.Report) error
+	GetReportedContent(ctx context.Context, pagination pkg.Pagination) ([]model.ReportedContent, int64, error)
+}
+
+type reportRepositoryImpl struct {
+	db infrastructure.GormPostgres
+}
+
+func NewReportRepository(db infrastructure.GormPostgres) ReportRepository {
+	return &reportRepositoryImpl{db: db}
+}
+
+// CreateReport does nothing when the user already reported the item, the
+// first reason is kept
+func (r *reportRepositoryImpl) CreateReport(ctx context.Context, report model.Report) error {
+	return connection(ctx, r.db).WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "reporter_id"}, {Name: "target_type"}, {Name: "target_id"}}, DoNothing: true}).
+		Create(&report).Error
+}
+
+// reportTargetExists leaves out the reports of deleted photos and comments,
+// a comment is gone with its photo too. Its args are the target types
+const reportTargetExists = `(target_type = ? AND EXISTS (SELECT 1 FROM photos WHERE photos.id = reports.target_id AND photos.deleted_at IS NULL)) OR ` +
+	`(target_type = ? AND EXISTS (SELECT 1 FROM comments JOIN photos ON photos.id = comments.photo_id WHERE comments.id = reports.target_id AND comments.deleted_at IS NULL AND photos.deleted_at IS NULL))`
+
+// GetReportedContent groups the reports by item, the most reported first
+// and the latest reported between equals. total counts the items, items
+// deleted since they were reported are left out
+func (r *reportRepositoryImpl) GetReportedContent(ctx context.Context, pagination pkg.Pagination) ([]model.ReportedContent, int64, error) {
+	db := connection(ctx, r.db)
+	var total int64
+	if err := db.WithContext(ctx).
+		Raw("SELECT COUNT(*) FROM (SELECT 1 FROM reports WHERE "+reportTargetExists+" GROUP BY target_type, target_id) AS targets", model.ReportTargetPhoto, model.ReportTargetComment).
+		Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	items := []model.ReportedContent{}
+	err := db.WithContext(ctx).
+		Model(&model.Report{}).
+		Where(reportTargetExists, model.ReportTargetPhoto, model.ReportTargetComment).
+		Select("target_type, target_id, COUNT(*) AS report_count, (ARRAY_AGG(reason ORDER BY created_at DESC))[1] AS latest_reason, MAX(created_at) AS last_reported_at").
+		Group("target_type, target_id").
+		Order("report_count DESC, last_reported_at DESC, target_type, target_id").
+		Offset(pagination.Offset()).
+		Limit(pagination.Limit()).
+		Scan(&items).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"go-mygram/internal/infrastructure/mocks"
+	"go-mygram/internal/model"
+	"go-mygram/pkg"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReport(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	// reporting twice hits the unique triple and is silently ignored
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "reports" ("reporter_id","target_type","target_id","reason","created_at") VALUES ($1,$2,$3,$4,$5) ON CONFLICT ("reporter_id","target_type","target_id") DO NOTHING RETURNING "id"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	reportRepo := reportRepositoryImpl{db: postgresMock}
+	err := reportRepo.CreateReport(context.Background(), model.Report{ReporterID: 1, TargetType: model.ReportTargetPhoto, TargetID: 2, Reason: "spam"})
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReportedContent(t *testing.T) {
+	t.Run("success get reported content", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		now := time.Now()
+		// reports of deleted items are left out of both
+		targetExists := `(target_type = $1 AND EXISTS (SELECT 1 FROM photos WHERE photos.id = reports.target_id AND photos.deleted_at IS NULL)) OR ` +
+			`(target_type = $2 AND EXISTS (SELECT 1 FROM comments JOIN photos ON photos.id = comments.photo_id WHERE comments.id = reports.target_id AND comments.deleted_at IS NULL AND photos.deleted_at IS NULL))`
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM (SELECT 1 FROM reports WHERE `+targetExists+` GROUP BY target_type, target_id) AS targets`)).
+			WithArgs("photo", "comment").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT target_type, target_id, COUNT(*) AS report_count, (ARRAY_AGG(reason ORDER BY created_at DESC))[1] AS latest_reason, MAX(created_at) AS last_reported_at FROM "reports" WHERE `+targetExists+` GROUP BY target_type, target_id ORDER BY report_count DESC, last_reported_at DESC, target_type, target_id LIMIT $3 OFFSET $4`)).
+			WithArgs("photo", "comment", 1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"target_type", "target_id", "report_count", "latest_reason", "last_reported_at"}).
+				AddRow("comment", 4, 1, "rude", now))
+
+		reportRepo := reportRepositoryImpl{db: postgresMock}
+		res, total, err := reportRepo.GetReportedContent(context.Background(), pkg.NewPagination(2, 1))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, []model.ReportedContent{{TargetType: "comment", TargetID: 4, ReportCount: 1, LatestReason: "rude", LastReportedAt: now}}, res)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error count", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM`)).WillReturnError(errors.New("some error"))
+
+		reportRepo := reportRepositoryImpl{db: postgresMock}
+		res, total, err := reportRepo.GetReportedContent(context.Background(), pkg.NewPagination(1, 20))
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+		assert.Equal(t, int64(0), total)
+	})
+}
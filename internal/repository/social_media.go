@@ -10,6 +10,7 @@ import (
 type SocialMediaRepository interface {
 	CreateSocialMedia(ctx context.Context, socialMedia model.SocialMedia) (model.SocialMedia, error)
 	GetSocialMediaByID(ctx context.Context, id uint64) (model.SocialMedia, error)
+	GetSocialMediasByUserID(ctx context.Context, userID uint64, includeUser bool) ([]model.SocialMedia, error)
 	UpdateSocialMedia(ctx context.Context, socialMedia model.SocialMedia) (model.SocialMedia, error)
 	DeleteSocialMediaByID(ctx context.Context, id uint64) error
 }
@@ -26,24 +27,36 @@ func (r *socialMediaRepositoryImpl) CreateSocialMedia(ctx context.Context, socia
 	socialMedia.CreatedAt = time.Now()
 	socialMedia.UpdatedAt = time.Now()
 
-	err := r.db.GetConnection().WithContext(ctx).Create(&socialMedia).Error
+	err := connection(ctx, r.db).WithContext(ctx).Create(&socialMedia).Error
 	return socialMedia, err
 }
 
 func (r *socialMediaRepositoryImpl) GetSocialMediaByID(ctx context.Context, id uint64) (model.SocialMedia, error) {
 	var socialMedia model.SocialMedia
-	err := r.db.GetConnection().WithContext(ctx).First(&socialMedia, id).Error
+	err := connection(ctx, r.db).WithContext(ctx).First(&socialMedia, id).Error
 	return socialMedia, err
 }
 
+// GetSocialMediasByUserID returns the social media of userID, with the
+// owner loaded in one extra query when includeUser is set
+func (r *socialMediaRepositoryImpl) GetSocialMediasByUserID(ctx context.Context, userID uint64, includeUser bool) ([]model.SocialMedia, error) {
+	socialMedias := []model.SocialMedia{}
+	query := connection(ctx, r.db).WithContext(ctx)
+	if includeUser {
+		query = query.Preload("User")
+	}
+	err := query.Where("user_id = ?", userID).Order("id").Find(&socialMedias).Error
+	return socialMedias, err
+}
+
 func (r *socialMediaRepositoryImpl) UpdateSocialMedia(ctx context.Context, socialMedia model.SocialMedia) (model.SocialMedia, error) {
 	socialMedia.UpdatedAt = time.Now()
 
-	err := r.db.GetConnection().WithContext(ctx).Save(&socialMedia).Error
+	err := connection(ctx, r.db).WithContext(ctx).Save(&socialMedia).Error
 	return socialMedia, err
 }
 
 func (r *socialMediaRepositoryImpl) DeleteSocialMediaByID(ctx context.Context, id uint64) error {
-	err := r.db.GetConnection().WithContext(ctx).Delete(&model.SocialMedia{}, id).Error
+	err := connection(ctx, r.db).WithContext(ctx).Delete(&model.SocialMedia{}, id).Error
 	return err
 }
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"go-mygram/internal/infrastructure/mocks"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSocialMediasByUserID(t *testing.T) {
+	t.Run("success without user", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "social_media" WHERE user_id = $1 AND "social_media"."deleted_at" IS NULL ORDER BY id`)).
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, 4))
+
+		repo := socialMediaRepositoryImpl{db: postgresMock}
+		socialMedias, err := repo.GetSocialMediasByUserID(context.Background(), 4, false)
+		assert.Nil(t, err)
+		assert.Len(t, socialMedias, 1)
+		assert.Nil(t, socialMedias[0].User)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success with user", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "social_media" WHERE user_id = $1`)).
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(1, 4).AddRow(2, 4))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(4, "alice"))
+
+		repo := socialMediaRepositoryImpl{db: postgresMock}
+		socialMedias, err := repo.GetSocialMediasByUserID(context.Background(), 4, true)
+		assert.Nil(t, err)
+		assert.Len(t, socialMedias, 2)
+		assert.Equal(t, "alice", socialMedias[1].User.Username)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"go-mygram/internal/infrastructure"
+
+	"gorm.io/gorm"
+)
+
+// Transactor runs several repository calls atomically. Repositories called
+// with the ctx handed to fn join the transaction, it is committed when fn
+// returns nil and rolled back otherwise
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type transactorImpl struct {
+	db infrastructure.GormPostgres
+}
+
+func NewTransactor(db infrastructure.GormPostgres) Transactor {
+	return &transactorImpl{db: db}
+}
+
+func (t *transactorImpl) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// nested calls run inside the outer transaction
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.GetConnection().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// connection returns the transaction started by WithTx for ctx, or the
+// plain connection outside of one
+func connection(ctx context.Context, db infrastructure.GormPostgres) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.GetConnection()
+}
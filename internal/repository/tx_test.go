@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"go-mygram/internal/infrastructure/mocks"
+	"go-mygram/internal/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTx(t *testing.T) {
+	t.Run("success commit both inserts", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "refresh_tokens"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		userRepo := userQueryImpl{db: postgresMock}
+		tokenRepo := NewRefreshTokenRepository(postgresMock)
+		err := NewTransactor(postgresMock).WithTx(context.Background(), func(ctx context.Context) error {
+			user, err := userRepo.CreateUser(ctx, model.User{Username: "user1", Email: "user1@mail.com"})
+			if err != nil {
+				return err
+			}
+			_, err = tokenRepo.CreateRefreshToken(ctx, model.RefreshToken{UserID: user.ID, TokenHash: "hash"})
+			return err
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error rolls back the created user", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectRollback()
+
+		userRepo := userQueryImpl{db: postgresMock}
+		err := NewTransactor(postgresMock).WithTx(context.Background(), func(ctx context.Context) error {
+			if _, err := userRepo.CreateUser(ctx, model.User{Username: "user1", Email: "user1@mail.com"}); err != nil {
+				return err
+			}
+			return errors.New("token failed")
+		})
+		assert.EqualError(t, err, "token failed")
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
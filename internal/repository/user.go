@@ -2,20 +2,37 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"go-mygram/internal/infrastructure"
 	"go-mygram/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserQuery interface {
-	GetUsers(ctx context.Context) ([]model.User, error)
+	GetUsers(ctx context.Context, params model.UserListParams) ([]model.User, int64, error)
+	CountUsers(ctx context.Context, params model.UserCountParams) (int64, error)
+	GetUserStats(ctx context.Context, viewerID, id uint64) (model.UserStats, error)
 	GetUsersByID(ctx context.Context, id uint64) (model.User, error)
+	GetByUsername(ctx context.Context, username string) (model.User, error)
 	FindByEmail(ctx context.Context, email string) (model.User, error)
-	UpdateUser(ctx context.Context, user model.User) (model.User, error)
+	UpdateUserIfVersion(ctx context.Context, user model.User, version int64) (model.User, error)
+	UpdatePassword(ctx context.Context, id uint64, hash string) error
 	DeleteUsersByID(ctx context.Context, id uint64) error
+	HardDeleteUser(ctx context.Context, id uint64) error
+	GetDeletedUserIDs(ctx context.Context, before time.Time) ([]uint64, error)
 	CreateUser(ctx context.Context, user model.User) (model.User, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	FindByUsernames(ctx context.Context, usernames []string) ([]model.User, error)
+	SearchUsernames(ctx context.Context, prefix string, limit int) ([]model.MentionedUser, error)
+	GetUsersByIDs(ctx context.Context, ids []uint64) ([]model.User, error)
+	UpdateAvatar(ctx context.Context, id uint64, url string) error
+	MarkEmailVerified(ctx context.Context, id uint64) error
+	RecordFailedLogin(ctx context.Context, id uint64, threshold int, lockUntil time.Time) error
+	RecordLogin(ctx context.Context, id uint64, at time.Time) error
 }
 
 type UserCommand interface {
@@ -30,20 +47,77 @@ func NewUserQuery(db infrastructure.GormPostgres) UserQuery {
 	return &userQueryImpl{db: db}
 }
 
-func (u *userQueryImpl) GetUsers(ctx context.Context) ([]model.User, error) {
-	db := u.db.GetConnection()
-	users := []model.User{}
+func (u *userQueryImpl) GetUsers(ctx context.Context, params model.UserListParams) ([]model.User, int64, error) {
+	db := connection(ctx, u.db)
+	var total int64
 	if err := db.
+		WithContext(ctx).
+		Model(&model.User{}).
+		Scopes(searchUsers(params.Search), createdBetween(params.CreatedAfter, params.CreatedBefore)).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	sortBy := params.SortBy
+	if !model.UserSortFields[sortBy] {
+		sortBy = model.DefaultUserSortBy
+	}
+	query := db.
 		WithContext(ctx).
 		Table("users").
+		Scopes(searchUsers(params.Search), createdBetween(params.CreatedAfter, params.CreatedBefore)).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: params.Order != model.SortAsc})
+	// keep pages stable when several rows share the sort value
+	if sortBy != "id" {
+		query = query.Order("id")
+	}
+
+	users := []model.User{}
+	if err := query.
+		Offset(params.Offset()).
+		Limit(params.Limit()).
 		Find(&users).Error; err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return users, nil
+	return users, total, nil
+}
+
+func (u *userQueryImpl) CountUsers(ctx context.Context, params model.UserCountParams) (int64, error) {
+	var total int64
+	err := connection(ctx, u.db).
+		WithContext(ctx).
+		Model(&model.User{}).
+		Scopes(searchUsers(params.Search), createdBetween(params.CreatedAfter, params.CreatedBefore)).
+		Count(&total).Error
+	return total, err
+}
+
+// GetUserStats counts the photos, comments and follows of the user in one
+// round trip, photos and the photos commented on are counted the way
+// visibleTo shows them to viewerID
+func (u *userQueryImpl) GetUserStats(ctx context.Context, viewerID, id uint64) (model.UserStats, error) {
+	var stats model.UserStats
+	err := connection(ctx, u.db).
+		WithContext(ctx).
+		Raw(`SELECT
+	(SELECT COUNT(*) FROM photos WHERE photos.user_id = ? AND photos.deleted_at IS NULL
+		AND (photos.visibility = ? OR photos.user_id = ?
+			OR (photos.visibility = ? AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = ? AND follows.followee_id = photos.user_id)))) AS photo_count,
+	(SELECT COUNT(*) FROM comments JOIN photos ON photos.id = comments.photo_id AND photos.deleted_at IS NULL
+		WHERE comments.user_id = ? AND comments.deleted_at IS NULL
+		AND (photos.visibility = ? OR photos.user_id = ?
+			OR (photos.visibility = ? AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = ? AND follows.followee_id = photos.user_id)))) AS comment_count,
+	(SELECT COUNT(*) FROM follows WHERE follows.followee_id = ?) AS follower_count,
+	(SELECT COUNT(*) FROM follows WHERE follows.follower_id = ?) AS following_count`,
+			id, model.VisibilityPublic, viewerID, model.VisibilityFollowers, viewerID,
+			id, model.VisibilityPublic, viewerID, model.VisibilityFollowers, viewerID,
+			id, id).
+		Scan(&stats).Error
+	return stats, err
 }
 
 func (u *userQueryImpl) GetUsersByID(ctx context.Context, id uint64) (model.User, error) {
-	db := u.db.GetConnection()
+	db := connection(ctx, u.db)
 	users := model.User{}
 	if err := db.
 		WithContext(ctx).
@@ -60,43 +134,306 @@ func (u *userQueryImpl) GetUsersByID(ctx context.Context, id uint64) (model.User
 	return users, nil
 }
 
-func (u *userQueryImpl) UpdateUser(ctx context.Context, user model.User) (model.User, error) {
-	db := u.db.GetConnection()
-	users := model.User{}
-	if err := db.WithContext(ctx).Save(&user).Error; err != nil {
-		return model.User{}, err
+// UpdateUserIfVersion saves the profile fields of user only while the row
+// is still at version, and bumps the version. ErrVersionConflict means
+// another update got there first
+func (u *userQueryImpl) UpdateUserIfVersion(ctx context.Context, user model.User, version int64) (model.User, error) {
+	db := connection(ctx, u.db)
+	user.UpdatedAt = time.Now()
+	res := db.
+		WithContext(ctx).
+		Model(&model.User{}).
+		Where("id = ? AND version = ?", user.ID, version).
+		Updates(map[string]interface{}{
+			"username":       user.Username,
+			"email":          user.Email,
+			"email_verified": user.EmailVerified,
+			"display_name":   user.DisplayName,
+			"bio":            user.Bio,
+			"updated_at":     user.UpdatedAt,
+			"version":        gorm.Expr("version + 1"),
+		})
+	if res.Error != nil {
+		return model.User{}, translateUserError(res.Error)
 	}
-	return users, nil
+	if res.RowsAffected == 0 {
+		return model.User{}, ErrVersionConflict
+	}
+	user.Version = version + 1
+	return user, nil
 }
 
+// UpdatePassword only touches the password column, so a concurrent
+// profile update isn't overwritten
+func (u *userQueryImpl) UpdatePassword(ctx context.Context, id uint64, hash string) error {
+	db := connection(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Model(&model.User{ID: id}).
+		Update("password", hash).Error
+}
+
+func (u *userQueryImpl) UpdateAvatar(ctx context.Context, id uint64, url string) error {
+	db := connection(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Model(&model.User{ID: id}).
+		Update("avatar_url", url).Error
+}
+
+func (u *userQueryImpl) MarkEmailVerified(ctx context.Context, id uint64) error {
+	db := connection(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Model(&model.User{ID: id}).
+		Update("email_verified", true).Error
+}
+
+// RecordFailedLogin counts a wrong password, the attempt reaching threshold
+// locks the account until lockUntil and starts the count over. It is a
+// single statement so concurrent attempts are all counted
+func (u *userQueryImpl) RecordFailedLogin(ctx context.Context, id uint64, threshold int, lockUntil time.Time) error {
+	db := connection(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Model(&model.User{ID: id}).
+		UpdateColumns(map[string]interface{}{
+			"failed_login_attempts": gorm.Expr("CASE WHEN failed_login_attempts + 1 >= ? THEN 0 ELSE failed_login_attempts + 1 END", threshold),
+			"locked_until":          gorm.Expr("CASE WHEN failed_login_attempts + 1 >= ? THEN ? ELSE locked_until END", threshold, lockUntil),
+		}).Error
+}
+
+// RecordLogin stamps a successful sign in at at and clears the failed
+// attempts, UpdateColumns keeps updated_at for profile changes
+func (u *userQueryImpl) RecordLogin(ctx context.Context, id uint64, at time.Time) error {
+	db := connection(ctx, u.db)
+	return db.
+		WithContext(ctx).
+		Model(&model.User{ID: id}).
+		UpdateColumns(map[string]interface{}{"last_login_at": at, "failed_login_attempts": 0, "locked_until": nil}).Error
+}
+
+// DeleteUsersByID soft deletes the user together with everything the user
+// owns, all rows go in a single transaction so a failure leaves nothing orphaned
 func (u *userQueryImpl) DeleteUsersByID(ctx context.Context, id uint64) error {
-	db := u.db.GetConnection()
+	db := connection(ctx, u.db)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteUserCascade(tx, id)
+	})
+}
+
+// HardDeleteUser permanently removes the user and the owned rows, including
+// rows that were already soft deleted
+func (u *userQueryImpl) HardDeleteUser(ctx context.Context, id uint64) error {
+	db := connection(ctx, u.db)
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tx = tx.Unscoped().Session(&gorm.Session{})
+		// mentions, likes and follows have no soft delete, they only go
+		// away with the rows they link
+		userPhotos := tx.Model(&model.Photo{}).Select("id").Where("user_id = ?", id)
+		if err := tx.
+			Where("user_id = ? OR photo_id IN (?)", id, userPhotos).
+			Delete(&model.PhotoMention{}).Error; err != nil {
+			return err
+		}
+		if err := tx.
+			Where("user_id = ? OR photo_id IN (?)", id, userPhotos).
+			Delete(&model.Like{}).Error; err != nil {
+			return err
+		}
+		if err := deleteFollows(tx, id); err != nil {
+			return err
+		}
+		return deleteUserCascade(tx, id)
+	})
+}
+
+// GetDeletedUserIDs returns ids of users soft deleted before the given time
+func (u *userQueryImpl) GetDeletedUserIDs(ctx context.Context, before time.Time) ([]uint64, error) {
+	db := connection(ctx, u.db)
+	ids := []uint64{}
 	if err := db.
 		WithContext(ctx).
+		Unscoped().
+		Model(&model.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func deleteUserCascade(tx *gorm.DB, id uint64) error {
+	// comments written by the user, or left by others on the user's photos
+	userPhotos := tx.Model(&model.Photo{}).Select("id").Where("user_id = ?", id)
+	if err := tx.
+		Where("user_id = ? OR photo_id IN (?)", id, userPhotos).
+		Delete(&model.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.
+		Where("user_id = ?", id).
+		Delete(&model.Photo{}).Error; err != nil {
+		return err
+	}
+	if err := tx.
+		Where("user_id = ?", id).
+		Delete(&model.SocialMedia{}).Error; err != nil {
+		return err
+	}
+	if err := tx.
+		Where("user_id = ?", id).
+		Delete(&model.RefreshToken{}).Error; err != nil {
+		return err
+	}
+	if err := tx.
 		Table("users").
-		Delete(&model.User{ID: id}).
-		Error; err != nil {
+		Delete(&model.User{ID: id}).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
 func (u *userQueryImpl) CreateUser(ctx context.Context, user model.User) (model.User, error) {
-	db := u.db.GetConnection()
+	db := connection(ctx, u.db)
 	if err := db.
 		WithContext(ctx).
 		Table("users").
 		Save(&user).Error; err != nil {
-		return model.User{}, err
+		return model.User{}, translateUserError(err)
 	}
 	return user, nil
 }
 
 func (u *userQueryImpl) FindByEmail(ctx context.Context, email string) (model.User, error) {
 	var user model.User
-	db := u.db.GetConnection()
-	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+	db := connection(ctx, u.db)
+	// compare case-insensitively so rows stored before normalization still match
+	if err := db.
+		WithContext(ctx).
+		Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).
+		First(&user).Error; err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// GetByUsername matches username case-insensitively, like GetUsersByID it
+// returns an empty user when there is no such account
+func (u *userQueryImpl) GetByUsername(ctx context.Context, username string) (model.User, error) {
+	var user model.User
+	db := connection(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Where("LOWER(username) = ?", strings.ToLower(strings.TrimSpace(username))).
+		Limit(1).
+		Find(&user).Error; err != nil {
 		return model.User{}, err
 	}
 	return user, nil
 }
+
+// ExistsByUsername also counts soft deleted users, their rows still hold the
+// unique username until they are purged
+func (u *userQueryImpl) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	var count int64
+	db := connection(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Unscoped().
+		Model(&model.User{}).
+		Where("LOWER(username) = ?", strings.ToLower(strings.TrimSpace(username))).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SearchUsernames returns the users whose username starts with prefix
+// case-insensitively. Every match shares the prefix, so the shortest names
+// come first and an exact match always leads
+func (u *userQueryImpl) SearchUsernames(ctx context.Context, prefix string, limit int) ([]model.MentionedUser, error) {
+	users := []model.MentionedUser{}
+	db := connection(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Where(`LOWER(username) LIKE ? ESCAPE '\'`, escapeLike(strings.ToLower(prefix))+"%").
+		Order("LENGTH(username), LOWER(username)").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindByUsernames returns the users matching any of usernames
+// case-insensitively, names without an account are simply left out
+func (u *userQueryImpl) FindByUsernames(ctx context.Context, usernames []string) ([]model.User, error) {
+	users := []model.User{}
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	lowered := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		lowered = append(lowered, strings.ToLower(username))
+	}
+	db := connection(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Where("LOWER(username) IN ?", lowered).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUsersByIDs returns the users with the given ids, ids of deleted users
+// are left out
+func (u *userQueryImpl) GetUsersByIDs(ctx context.Context, ids []uint64) ([]model.User, error) {
+	users := []model.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	db := connection(ctx, u.db)
+	if err := db.
+		WithContext(ctx).
+		Where("id IN ?", ids).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// searchUsers filters on a case-insensitive substring of username or email,
+// the term is bound as a parameter and its wildcards are escaped
+func searchUsers(term string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return db
+		}
+		pattern := "%" + escapeLike(strings.ToLower(term)) + "%"
+		return db.Where(`LOWER(username) LIKE ? ESCAPE '\' OR LOWER(email) LIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+}
+
+// createdBetween keeps users registered strictly between after and before,
+// a nil bound is not applied
+func createdBetween(after, before *time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if after != nil {
+			db = db.Where("created_at > ?", *after)
+		}
+		if before != nil {
+			db = db.Where("created_at < ?", *before)
+		}
+		return db
+	}
+}
+
+// escapeLike makes %, _ and the escape character itself match literally
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -6,10 +6,14 @@ import (
 	"log"
 	"regexp"
 	"testing"
+	"time"
 
 	"go-mygram/internal/infrastructure/mocks"
+	"go-mygram/internal/model"
+	"go-mygram/pkg"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -37,13 +41,14 @@ func TestGetUsers(t *testing.T) {
 		postgresMock.On("GetConnection").Return(db)
 		// mock query
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT * FROM "users"
+			SELECT count(*) FROM "users"
 		`)).WillReturnError(errors.New("some error"))
 
 		userRepo := userQueryImpl{db: postgresMock}
-		res, err := userRepo.GetUsers(context.Background())
+		res, total, err := userRepo.GetUsers(context.Background(), model.UserListParams{Pagination: pkg.NewPagination(1, 20)})
 		assert.NotNil(t, err)
 		assert.Equal(t, 0, len(res))
+		assert.Equal(t, int64(0), total)
 	})
 
 	t.Run("success get users", func(t *testing.T) {
@@ -52,17 +57,434 @@ func TestGetUsers(t *testing.T) {
 		postgresMock := mocks.NewGormPostgres(t)
 		postgresMock.On("GetConnection").Return(db)
 		// mock query
+		mock.ExpectQuery(regexp.QuoteMeta(`
+			SELECT count(*) FROM "users"
+		`)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+
 		row := sqlmock.
 			NewRows([]string{"id", "username"}).
 			AddRow(1, "username")
 
 		mock.ExpectQuery(regexp.QuoteMeta(`
-			SELECT * FROM "users"
+			SELECT * FROM "users" WHERE "users"."deleted_at" IS NULL ORDER BY "username",id LIMIT $1 OFFSET $2
 		`)).WillReturnRows(row)
 
 		userRepo := userQueryImpl{db: postgresMock}
-		res, err := userRepo.GetUsers(context.Background())
+		res, total, err := userRepo.GetUsers(context.Background(), model.UserListParams{Pagination: pkg.NewPagination(2, 20), SortBy: "username", Order: "asc"})
 		assert.Nil(t, err)
 		assert.Equal(t, 1, len(res))
+		assert.Equal(t, int64(21), total)
+	})
+
+	t.Run("success default sort created_at desc", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY "created_at" DESC,id`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		userRepo := userQueryImpl{db: postgresMock}
+		_, _, err := userRepo.GetUsers(context.Background(), model.UserListParams{Pagination: pkg.NewPagination(1, 20)})
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetUsersSearch(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	// wildcards typed by the client must match literally
+	pattern := `%50\%\_off%`
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE (LOWER(username) LIKE $1 ESCAPE '\' OR LOWER(email) LIKE $2 ESCAPE '\')`)).
+		WithArgs(pattern, pattern).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE (LOWER(username) LIKE $1 ESCAPE '\' OR LOWER(email) LIKE $2 ESCAPE '\')`)).
+		WithArgs(pattern, pattern, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	res, total, err := userRepo.GetUsers(context.Background(), model.UserListParams{Pagination: pkg.NewPagination(1, 20), Search: " 50%_OFF "})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, int64(1), total)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUsersCreatedBetween(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE created_at > $1 AND created_at < $2`)).
+		WithArgs(after, before).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE created_at > $1 AND created_at < $2 AND "users"."deleted_at" IS NULL`)).
+		WithArgs(after, before, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	res, total, err := userRepo.GetUsers(context.Background(), model.UserListParams{Pagination: pkg.NewPagination(1, 20), CreatedAfter: &after, CreatedBefore: &before})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, int64(1), total)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestCountUsers(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE created_at > $1`)).
+		WithArgs(after).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	total, err := userRepo.CountUsers(context.Background(), model.UserCountParams{CreatedAfter: &after})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), total)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUser(t *testing.T) {
+	t.Run("error duplicate email", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"})
+		mock.ExpectRollback()
+
+		userRepo := userQueryImpl{db: postgresMock}
+		_, err := userRepo.CreateUser(context.Background(), model.User{Username: "user1", Email: "user1@mail.com"})
+		assert.ErrorIs(t, err, ErrDuplicateEmail)
+		assert.NotContains(t, err.Error(), "23505")
+	})
+
+	t.Run("error username differs only by case", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+			WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "users_username_lower_key"})
+		mock.ExpectRollback()
+
+		userRepo := userQueryImpl{db: postgresMock}
+		_, err := userRepo.CreateUser(context.Background(), model.User{Username: "User1", Email: "user1@mail.com"})
+		assert.ErrorIs(t, err, ErrDuplicateUsername)
+	})
+}
+
+func TestUpdateUserIfVersion(t *testing.T) {
+	query := `UPDATE "users" SET "bio"=$1,"display_name"=$2,"email"=$3,"email_verified"=$4,"updated_at"=$5,"username"=$6,"version"=version + 1 WHERE (id = $7 AND version = $8) AND "users"."deleted_at" IS NULL`
+
+	t.Run("success bump version", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WithArgs("", "User One", "user1@mail.com", true, sqlmock.AnyArg(), "user1", 1, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		userRepo := userQueryImpl{db: postgresMock}
+		user, err := userRepo.UpdateUserIfVersion(context.Background(), model.User{ID: 1, Username: "user1", Email: "user1@mail.com", EmailVerified: true, DisplayName: "User One", Version: 3}, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), user.Version)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error version changed", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(query)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		userRepo := userQueryImpl{db: postgresMock}
+		_, err := userRepo.UpdateUserIfVersion(context.Background(), model.User{ID: 1, Username: "user1", Email: "user1@mail.com"}, 3)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+	})
+
+	for _, tC := range []struct {
+		desc       string
+		constraint string
+		err        error
+	}{
+		{desc: "error email differs only by case", constraint: "users_email_lower_key", err: ErrDuplicateEmail},
+		{desc: "error username differs only by case", constraint: "users_username_lower_key", err: ErrDuplicateUsername},
+	} {
+		t.Run(tC.desc, func(t *testing.T) {
+			db, mock := newMockGorm()
+			postgresMock := mocks.NewGormPostgres(t)
+			postgresMock.On("GetConnection").Return(db)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(query)).
+				WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: tC.constraint})
+			mock.ExpectRollback()
+
+			userRepo := userQueryImpl{db: postgresMock}
+			_, err := userRepo.UpdateUserIfVersion(context.Background(), model.User{ID: 1, Username: "User1", Email: "User1@mail.com"}, 3)
+			assert.ErrorIs(t, err, tC.err)
+			assert.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRecordFailedLogin(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	lockUntil := time.Date(2024, 1, 1, 0, 15, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "failed_login_attempts"=CASE WHEN failed_login_attempts + 1 >= $1 THEN 0 ELSE failed_login_attempts + 1 END,"locked_until"=CASE WHEN failed_login_attempts + 1 >= $2 THEN $3 ELSE locked_until END WHERE "users"."deleted_at" IS NULL AND "id" = $4`)).
+		WithArgs(5, 5, lockUntil, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	userRepo := userQueryImpl{db: postgresMock}
+	assert.Nil(t, userRepo.RecordFailedLogin(context.Background(), 1, 5, lockUntil))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordLogin(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	at := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "failed_login_attempts"=$1,"last_login_at"=$2,"locked_until"=$3 WHERE "users"."deleted_at" IS NULL AND "id" = $4`)).
+		WithArgs(0, at, nil, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	userRepo := userQueryImpl{db: postgresMock}
+	assert.Nil(t, userRepo.RecordLogin(context.Background(), 1, at))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteUsersByID(t *testing.T) {
+	t.Run("success delete user and owned data", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "comments" SET "deleted_at"=$1 WHERE (user_id = $2 OR photo_id IN (SELECT "id" FROM "photos" WHERE user_id = $3`)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "photos" SET "deleted_at"=$1 WHERE user_id = $2`)).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "social_media" SET "deleted_at"=$1 WHERE user_id = $2`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "refresh_tokens" WHERE user_id = $1`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "deleted_at"=$1 WHERE "users"."id" = $2`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// photos of the deleted user are filtered by the soft delete clause
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "photos" WHERE (photos.visibility = $1 OR photos.user_id = $2 OR (photos.visibility = $3 AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $4 AND follows.followee_id = photos.user_id))) AND "photos"."deleted_at" IS NULL ORDER BY created_at DESC, id DESC LIMIT $5`)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}))
+
+		userRepo := userQueryImpl{db: postgresMock}
+		err := userRepo.DeleteUsersByID(context.Background(), 1)
+		assert.Nil(t, err)
+
+		photoRepo := photoRepositoryImpl{db: postgresMock}
+		photos, err := photoRepo.GetPhotos(context.Background(), 2, "", nil, 20)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(photos))
+		assert.Nil(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("error rollback when deleting photos fails", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "comments"`)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "photos"`)).
+			WillReturnError(errors.New("some error"))
+		mock.ExpectRollback()
+
+		userRepo := userQueryImpl{db: postgresMock}
+		err := userRepo.DeleteUsersByID(context.Background(), 1)
+		assert.NotNil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetUsersByIDSkipsSoftDeleted(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	user, err := userRepo.GetUsersByID(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), user.ID)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestHardDeleteUser(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "photo_mentions" WHERE user_id = $1 OR photo_id IN (SELECT "id" FROM "photos" WHERE user_id = $2)`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "likes" WHERE user_id = $1 OR photo_id IN (SELECT "id" FROM "photos" WHERE user_id = $2)`)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "follows" WHERE follower_id = $1 OR followee_id = $2`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "comments" WHERE user_id = $1 OR photo_id IN (SELECT "id" FROM "photos" WHERE user_id = $2)`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "photos" WHERE user_id = $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "social_media" WHERE user_id = $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "refresh_tokens" WHERE user_id = $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "users" WHERE "users"."id" = $1`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	userRepo := userQueryImpl{db: postgresMock}
+	err := userRepo.HardDeleteUser(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeletedUserIDs(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	before := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "users" WHERE deleted_at IS NOT NULL AND deleted_at < $1`)).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(7))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	ids, err := userRepo.GetDeletedUserIDs(context.Background(), before)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{3, 7}, ids)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestExistsByUsername(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	// soft deleted users still count, so no deleted_at filter
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" WHERE LOWER(username) = $1`)).
+		WithArgs("foo").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	exists, err := userRepo.ExistsByUsername(context.Background(), " Foo ")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetByUsername(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE LOWER(username) = $1 AND "users"."deleted_at" IS NULL LIMIT $2`)).
+		WithArgs("foo", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(4, "Foo"))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	user, err := userRepo.GetByUsername(context.Background(), " Foo ")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), user.ID)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestFindByUsernames(t *testing.T) {
+	t.Run("success skip query without usernames", func(t *testing.T) {
+		userRepo := userQueryImpl{}
+		users, err := userRepo.FindByUsernames(context.Background(), nil)
+		assert.Nil(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("success match case-insensitively", func(t *testing.T) {
+		db, mock := newMockGorm()
+		postgresMock := mocks.NewGormPostgres(t)
+		postgresMock.On("GetConnection").Return(db)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE LOWER(username) IN ($1,$2) AND "users"."deleted_at" IS NULL`)).
+			WithArgs("alice", "bob").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "Alice"))
+
+		userRepo := userQueryImpl{db: postgresMock}
+		users, err := userRepo.FindByUsernames(context.Background(), []string{"Alice", "bob"})
+		assert.Nil(t, err)
+		assert.Equal(t, []model.User{{ID: 1, Username: "Alice"}}, users)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSearchUsernames(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	// wildcards typed by the user match literally
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE LOWER(username) LIKE $1 ESCAPE '\' AND "users"."deleted_at" IS NULL ORDER BY LENGTH(username), LOWER(username) LIMIT $2`)).
+		WithArgs(`jo\_%`, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "Jo_").AddRow(2, "jo_hn"))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	users, err := userRepo.SearchUsernames(context.Background(), "Jo_", 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.MentionedUser{{ID: 1, Username: "Jo_"}, {ID: 2, Username: "jo_hn"}}, users)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserStats(t *testing.T) {
+	db, mock := newMockGorm()
+	postgresMock := mocks.NewGormPostgres(t)
+	postgresMock.On("GetConnection").Return(db)
+
+	mock.ExpectQuery(`SELECT\s+\(SELECT COUNT\(\*\) FROM photos .+\(SELECT COUNT\(\*\) FROM comments JOIN photos ON photos.id = comments.photo_id .+photos.visibility = \$7`).
+		WithArgs(3, model.VisibilityPublic, 7, model.VisibilityFollowers, 7, 3, model.VisibilityPublic, 7, model.VisibilityFollowers, 7, 3, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"photo_count", "comment_count", "follower_count", "following_count"}).AddRow(4, 2, 5, 1))
+
+	userRepo := userQueryImpl{db: postgresMock}
+	stats, err := userRepo.GetUserStats(context.Background(), 7, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, model.UserStats{PhotoCount: 4, CommentCount: 2, FollowerCount: 5, FollowingCount: 1}, stats)
+	assert.Nil(t, mock.ExpectationsWereMet())
 }
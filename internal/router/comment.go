@@ -0,0 +1,36 @@
+package router
+
+import (
+	"go-mygram/internal/handler"
+	"go-mygram/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentRouter interface {
+	Mount()
+}
+
+type commentRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.CommentHandler
+	auth    middleware.AuthMiddleware
+	// idempotent replays retried creates, see middleware.Idempotency
+	idempotent gin.HandlerFunc
+}
+
+func NewCommentRouter(v *gin.RouterGroup, handler handler.CommentHandler, auth middleware.AuthMiddleware, idempotent gin.HandlerFunc) CommentRouter {
+	return &commentRouterImpl{v: v, handler: handler, auth: auth, idempotent: idempotent}
+}
+
+func (c *commentRouterImpl) Mount() {
+	authed := c.v.Group("", c.auth.CheckAuthBearer)
+
+	authed.POST("/comments", middleware.RequireVerified(), c.idempotent, c.handler.CreateComment)
+	authed.GET("/comments", c.handler.GetComments)
+	authed.GET("/photos/:id/comments", c.handler.GetPhotoComments)
+	authed.POST("/photos/:id/comments", middleware.RequireVerified(), c.idempotent, c.handler.CreatePhotoComment)
+	authed.GET("/comments/:id", c.handler.GetCommentByID)
+	authed.PUT("/comments/:id", c.handler.UpdateComment)
+	authed.DELETE("/comments/:id", c.handler.DeleteComment)
+}
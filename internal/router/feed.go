@@ -0,0 +1,28 @@
+package router
+
+import (
+	"go-mygram/internal/handler"
+	"go-mygram/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedRouter interface {
+	Mount()
+}
+
+type feedRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.FeedHandler
+	auth    middleware.AuthMiddleware
+}
+
+func NewFeedRouter(v *gin.RouterGroup, handler handler.FeedHandler, auth middleware.AuthMiddleware) FeedRouter {
+	return &feedRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (f *feedRouterImpl) Mount() {
+	authed := f.v.Group("", f.auth.CheckAuthBearer)
+	authed.GET("/feed", f.handler.GetFeed)
+	authed.GET("/feed/following", f.handler.GetFollowingFeed)
+}
@@ -0,0 +1,30 @@
+package router
+
+import (
+	"go-mygram/internal/handler"
+	"go-mygram/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowRouter interface {
+	Mount()
+}
+
+type followRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.FollowHandler
+	auth    middleware.AuthMiddleware
+}
+
+func NewFollowRouter(v *gin.RouterGroup, handler handler.FollowHandler, auth middleware.AuthMiddleware) FollowRouter {
+	return &followRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (f *followRouterImpl) Mount() {
+	authed := f.v.Group("", f.auth.CheckAuthBearer)
+	authed.POST("/users/:id/follow", f.handler.Follow)
+	authed.DELETE("/users/:id/follow", f.handler.Unfollow)
+	authed.GET("/users/:id/followers", f.handler.GetFollowers)
+	authed.GET("/users/:id/following", f.handler.GetFollowing)
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	"go-mygram/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthRouter interface {
+	Mount()
+}
+
+type healthRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.HealthHandler
+	// debug exposes /debug/db
+	debug bool
+}
+
+func NewHealthRouter(v *gin.RouterGroup, handler handler.HealthHandler, debug bool) HealthRouter {
+	return &healthRouterImpl{v: v, handler: handler, debug: debug}
+}
+
+// Mount registers the probes, they are public so the orchestrator can hit
+// them without a token
+func (h *healthRouterImpl) Mount() {
+	h.v.GET("/healthz", h.handler.Healthz)
+	h.v.GET("/readyz", h.handler.Readyz)
+	if h.debug {
+		h.v.GET("/debug/db", h.handler.DBStats)
+	}
+}
@@ -14,18 +14,29 @@ type PhotoRouter interface {
 type photoRouterImpl struct {
 	v       *gin.RouterGroup
 	handler handler.PhotoHandler
+	auth    middleware.AuthMiddleware
+	// idempotent replays retried creates, see middleware.Idempotency
+	idempotent gin.HandlerFunc
+	// uploadLimit replaces the default body limit on the image upload
+	uploadLimit gin.HandlerFunc
 }
 
-func NewPhotoRouter(v *gin.RouterGroup, handler handler.PhotoHandler) PhotoRouter {
-	return &photoRouterImpl{v: v, handler: handler}
+func NewPhotoRouter(v *gin.RouterGroup, handler handler.PhotoHandler, auth middleware.AuthMiddleware, idempotent, uploadLimit gin.HandlerFunc) PhotoRouter {
+	return &photoRouterImpl{v: v, handler: handler, auth: auth, idempotent: idempotent, uploadLimit: uploadLimit}
 }
 
 func (p *photoRouterImpl) Mount() {
 	// Authenticated routes
-	p.v.Use(middleware.CheckAuthBearer)
-	p.v.GET("/photos", p.handler.GetPhotos)
-	p.v.GET("/photos/:id", p.handler.GetPhotoByID)
-	p.v.POST("/photos", p.handler.CreatePhoto)
-	p.v.PUT("/photos/:id", p.handler.UpdatePhoto)
-	p.v.DELETE("/photos/:id", p.handler.DeletePhotoByID)
+	authed := p.v.Group("", p.auth.CheckAuthBearer)
+	authed.GET("/photos", p.handler.GetPhotos)
+	authed.GET("/photos/:id", p.handler.GetPhotoByID)
+	authed.POST("/photos", middleware.RequireVerified(), p.idempotent, p.handler.CreatePhoto)
+	authed.POST("/photos/images", p.uploadLimit, middleware.RequireVerified(), p.handler.UploadPhotoImage)
+	authed.PUT("/photos/:id", p.handler.UpdatePhoto)
+	authed.DELETE("/photos/:id", p.handler.DeletePhoto)
+	authed.POST("/photos/:id/like", p.handler.LikePhoto)
+	authed.DELETE("/photos/:id/like", p.handler.UnlikePhoto)
+	authed.GET("/photos/:id/likes", p.handler.GetPhotoLikers)
+	authed.GET("/users/:id/photos", p.handler.GetPhotosByUserID)
+	authed.GET("/tags/popular", p.handler.GetPopularTags)
 }
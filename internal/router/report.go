@@ -0,0 +1,30 @@
+package router
+
+import (
+	"go-mygram/internal/handler"
+	"go-mygram/internal/middleware"
+	"go-mygram/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportRouter interface {
+	Mount()
+}
+
+type reportRouterImpl struct {
+	v       *gin.RouterGroup
+	handler handler.ReportHandler
+	auth    middleware.AuthMiddleware
+}
+
+func NewReportRouter(v *gin.RouterGroup, handler handler.ReportHandler, auth middleware.AuthMiddleware) ReportRouter {
+	return &reportRouterImpl{v: v, handler: handler, auth: auth}
+}
+
+func (r *reportRouterImpl) Mount() {
+	authed := r.v.Group("", r.auth.CheckAuthBearer)
+	authed.POST("/photos/:id/report", r.handler.ReportPhoto)
+	authed.POST("/comments/:id/report", r.handler.ReportComment)
+	authed.GET("/reports", middleware.RequireRole(model.RoleAdmin), r.handler.GetReports)
+}
@@ -14,22 +14,24 @@ type SocialMediaRouter interface {
 type socialMediaRouterImpl struct {
 	v       *gin.RouterGroup
 	handler handler.SocialMediaHandler
+	auth    middleware.AuthMiddleware
 }
 
-func NewSocialMediaRouter(v *gin.RouterGroup, handler handler.SocialMediaHandler) SocialMediaRouter {
+func NewSocialMediaRouter(v *gin.RouterGroup, handler handler.SocialMediaHandler, auth middleware.AuthMiddleware) SocialMediaRouter {
 	return &socialMediaRouterImpl{
 		v:       v,
 		handler: handler,
+		auth:    auth,
 	}
 }
 
 func (r *socialMediaRouterImpl) Mount() {
-	socialMediaGroup := r.v.Group("/socialmedias")
-	r.v.Use(middleware.CheckAuthBearer)
+	socialMediaGroup := r.v.Group("/socialmedias", r.auth.CheckAuthBearer)
 	{
-		socialMediaGroup.POST("/", r.handler.CreateSocialMedia)
+		socialMediaGroup.POST("", r.handler.CreateSocialMedia)
+		socialMediaGroup.GET("", r.handler.GetSocialMedias)
 		socialMediaGroup.GET("/:id", r.handler.GetSocialMediaByID)
 		socialMediaGroup.PUT("/:id", r.handler.UpdateSocialMedia)
-		socialMediaGroup.DELETE("/:id", r.handler.DeleteSocialMediaByID)
+		socialMediaGroup.DELETE("/:id", r.handler.DeleteSocialMedia)
 	}
 }
@@ -3,6 +3,8 @@ package router
 import (
 	"go-mygram/internal/handler"
 	"go-mygram/internal/middleware"
+	"go-mygram/internal/model"
+	"go-mygram/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,19 +16,48 @@ type UserRouter interface {
 type userRouterImpl struct {
 	v       *gin.RouterGroup
 	handler handler.UserHandler
+	auth    middleware.AuthMiddleware
+	limiter ratelimit.Limiter
+	// availabilityLimiter throttles the public username check
+	availabilityLimiter ratelimit.Limiter
+	// searchLimiter throttles the username autocomplete per user
+	searchLimiter ratelimit.Limiter
+	// avatarLimit replaces the default body limit on the avatar upload
+	avatarLimit gin.HandlerFunc
 }
 
-func NewUserRouter(v *gin.RouterGroup, handler handler.UserHandler) UserRouter {
-	return &userRouterImpl{v: v, handler: handler}
+func NewUserRouter(v *gin.RouterGroup, handler handler.UserHandler, auth middleware.AuthMiddleware, limiter, availabilityLimiter, searchLimiter ratelimit.Limiter, avatarLimit gin.HandlerFunc) UserRouter {
+	return &userRouterImpl{v: v, handler: handler, auth: auth, limiter: limiter, availabilityLimiter: availabilityLimiter, searchLimiter: searchLimiter, avatarLimit: avatarLimit}
 }
 
 func (u *userRouterImpl) Mount() {
-	u.v.POST("/users/register", u.handler.UserSignUp)
-	u.v.POST("/users/login", u.handler.UserSignIn)
-
-	u.v.Use(middleware.CheckAuthBearer)
-
-	u.v.GET("/users", u.handler.GetUsers)
-	u.v.PUT("/users", u.handler.UpdateUserByID)
-	u.v.DELETE("/users", u.handler.DeleteUsersById)
+	u.v.POST("/users/register", middleware.ValidateJSON[model.UserSignUp](), u.handler.UserSignUp)
+	u.v.POST("/users/login", middleware.RateLimitSignIn(u.limiter), u.handler.UserSignIn)
+	u.v.POST("/users/refresh", u.handler.RefreshToken)
+	u.v.GET("/users/verify", u.handler.VerifyEmail)
+	u.v.POST("/users/forgot-password", u.handler.ForgotPassword)
+	u.v.POST("/users/reset-password", u.handler.ResetPassword)
+	u.v.GET("/users/username-available", middleware.RateLimitByIP(u.availabilityLimiter, "username-available"), u.handler.UsernameAvailable)
+
+	authed := u.v.Group("", u.auth.CheckAuthBearer)
+
+	authed.POST("/users/signout", u.handler.UserSignOut)
+	authed.GET("/users", middleware.RequireRole(model.RoleAdmin), u.handler.GetUsers)
+	authed.GET("/users/count", middleware.RequireRole(model.RoleAdmin), u.handler.GetUserCount)
+	authed.GET("/users/me", u.handler.GetCurrentUser)
+	authed.GET("/users/search", middleware.RateLimitByUser(u.searchLimiter, "user-search"), u.handler.SearchUsers)
+	// an id or a username, static routes like /users/me take precedence
+	authed.GET("/users/:id", u.handler.GetUsersById)
+	authed.POST("/users/batch", u.handler.GetUsersBatch)
+	authed.POST("/users/me/avatar", u.avatarLimit, u.handler.UploadAvatar)
+	authed.POST("/users/me/password", u.handler.ChangePassword)
+	authed.PUT("/users/me", u.handler.ReplaceCurrentUser)
+	authed.PATCH("/users/me", u.handler.UpdateUserByID)
+	// kept for older clients, it has always been a partial update
+	authed.PUT("/users", u.handler.UpdateUserByID)
+	authed.DELETE("/users/me", u.handler.DeleteCurrentUser)
+	authed.DELETE("/users/:id", middleware.RequireRole(model.RoleAdmin), u.handler.DeleteUsersById)
+
+	admin := authed.Group("/admin", middleware.RequireRole(model.RoleAdmin))
+	admin.DELETE("/users/:id", u.handler.HardDeleteUser)
 }
@@ -0,0 +1,185 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"go-mygram/internal/config"
+	"go-mygram/internal/model"
+	"go-mygram/internal/service"
+	"go-mygram/pkg"
+)
+
+var ErrProduction = errors.New("refusing to seed a production environment")
+
+// SeedPassword is shared by every seeded account so devs can sign in
+const SeedPassword = "password123"
+
+var users = []model.UserSignUp{
+	{Username: "alice", Email: "alice@example.com", Password: SeedPassword, Age: 25},
+	{Username: "bob", Email: "bob@example.com", Password: SeedPassword, Age: 31},
+	{Username: "carol", Email: "carol@example.com", Password: SeedPassword, Age: 19},
+}
+
+// photos are keyed by the username of their owner
+var photos = map[string][]model.PhotoPost{
+	"alice": {
+		{Title: "Sunrise", Caption: "Early hike", PhotoURL: "https://picsum.photos/id/10/800/600"},
+		{Title: "Coffee", Caption: "Monday fuel", PhotoURL: "https://picsum.photos/id/30/800/600"},
+	},
+	"bob": {
+		{Title: "City lights", Caption: "Night walk", PhotoURL: "https://picsum.photos/id/43/800/600"},
+	},
+}
+
+// comments are left on every seeded photo by the listed users
+var comments = map[string]string{
+	"bob":   "Great shot!",
+	"carol": "Love this one",
+}
+
+type Seeder interface {
+	Seed(ctx context.Context) error
+}
+
+type seederImpl struct {
+	env      string
+	users    service.UserService
+	photos   service.PhotoService
+	comments service.CommentService
+}
+
+func NewSeeder(env string, users service.UserService, photos service.PhotoService, comments service.CommentService) Seeder {
+	return &seederImpl{env: env, users: users, photos: photos, comments: comments}
+}
+
+// Seed inserts the sample data through the services, so it gets the same
+// validation and password hashing as real requests. Rows that already
+// exist are reused, running it twice adds nothing.
+func (s *seederImpl) Seed(ctx context.Context) error {
+	if s.env == config.EnvProduction {
+		return ErrProduction
+	}
+
+	userIDs := map[string]uint64{}
+	for _, signUp := range users {
+		user, err := s.seedUser(ctx, signUp)
+		if err != nil {
+			return fmt.Errorf("seed user %s: %w", signUp.Username, err)
+		}
+		userIDs[signUp.Username] = user.ID
+	}
+
+	existing, err := s.allPhotos(ctx)
+	if err != nil {
+		return err
+	}
+	for username, posts := range photos {
+		for _, post := range posts {
+			photo, err := s.seedPhoto(ctx, existing, userIDs[username], post)
+			if err != nil {
+				return fmt.Errorf("seed photo %s: %w", post.Title, err)
+			}
+			if err := s.seedComments(ctx, photo.ID, userIDs); err != nil {
+				return fmt.Errorf("seed comments on %s: %w", post.Title, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *seederImpl) seedUser(ctx context.Context, signUp model.UserSignUp) (model.User, error) {
+	if err := signUp.Validate(); err != nil {
+		return model.User{}, err
+	}
+	user, err := s.users.SignUp(ctx, signUp)
+	if errors.Is(err, service.ErrEmailAlreadyExists) || errors.Is(err, service.ErrUsernameAlreadyExists) {
+		// seeded on an earlier run, the known password gets us the row
+		return s.users.SignIn(ctx, model.UserSignIn{Email: signUp.Email, Password: signUp.Password})
+	}
+	if err == nil {
+		log.Printf("seeded user %s", signUp.Username)
+	}
+	return user, err
+}
+
+func (s *seederImpl) seedPhoto(ctx context.Context, existing []model.Photo, userID uint64, post model.PhotoPost) (model.Photo, error) {
+	for _, photo := range existing {
+		if photo.UserID == userID && photo.Title == post.Title {
+			return photo, nil
+		}
+	}
+	if err := post.Validate(); err != nil {
+		return model.Photo{}, err
+	}
+	return s.photos.CreatePhoto(ctx, userID, post)
+}
+
+func (s *seederImpl) seedComments(ctx context.Context, photoID uint64, userIDs map[string]uint64) error {
+	existing, err := s.allComments(ctx, photoID)
+	if err != nil {
+		return err
+	}
+	for username, message := range comments {
+		if hasComment(existing, userIDs[username], message) {
+			continue
+		}
+		post := model.CommentPost{PhotoID: photoID, Message: message}
+		if err := post.Validate(); err != nil {
+			return err
+		}
+		if _, err := s.comments.CreateComment(ctx, userIDs[username], post); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedPageSize is the page size used to walk lists when looking for rows of
+// an earlier run
+const seedPageSize = 100
+
+func (s *seederImpl) allPhotos(ctx context.Context) ([]model.Photo, error) {
+	var all []model.Photo
+	var after *pkg.Cursor
+	for {
+		page, err := s.photos.GetPhotos(ctx, 0, "", after, seedPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		if page.NextCursor == "" {
+			return all, nil
+		}
+		cursor := page.Data[len(page.Data)-1].Cursor()
+		after = &cursor
+	}
+}
+
+func (s *seederImpl) allComments(ctx context.Context, photoID uint64) ([]model.Comment, error) {
+	var all []model.Comment
+	var after *pkg.Cursor
+	for {
+		page, err := s.comments.GetComments(ctx, 0, photoID, after, seedPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Data...)
+		if page.NextCursor == "" {
+			return all, nil
+		}
+		cursor := page.Data[len(page.Data)-1].Cursor()
+		after = &cursor
+	}
+}
+
+func hasComment(comments []model.Comment, userID uint64, message string) bool {
+	for _, c := range comments {
+		if c.UserID == userID && c.Message == message {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package seed
+
+import (
+	"context"
+	"testing"
+
+	"go-mygram/internal/config"
+	"go-mygram/internal/model"
+	"go-mygram/internal/service"
+	"go-mygram/internal/service/mocks"
+	"go-mygram/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSeedRefusesProduction(t *testing.T) {
+	// no expectations, the services must not be touched
+	s := NewSeeder(config.EnvProduction, mocks.NewUserService(t), mocks.NewPhotoService(t), mocks.NewCommentService(t))
+	assert.ErrorIs(t, s.Seed(context.Background()), ErrProduction)
+}
+
+func TestSeedIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	userSvc := mocks.NewUserService(t)
+	photoSvc := mocks.NewPhotoService(t)
+	commentSvc := mocks.NewCommentService(t)
+
+	// everything already exists from an earlier run
+	ids := map[string]uint64{"alice": 1, "bob": 2, "carol": 3}
+	for _, u := range users {
+		userSvc.On("SignUp", ctx, u).Return(model.User{}, service.ErrEmailAlreadyExists)
+		userSvc.
+			On("SignIn", ctx, model.UserSignIn{Email: u.Email, Password: SeedPassword}).
+			Return(model.User{ID: ids[u.Username], Username: u.Username}, nil)
+	}
+	existing := []model.Photo{}
+	for username, posts := range photos {
+		for _, p := range posts {
+			existing = append(existing, model.Photo{ID: uint64(len(existing) + 1), UserID: ids[username], Title: p.Title})
+		}
+	}
+	photoSvc.On("GetPhotos", ctx, uint64(0), "", (*pkg.Cursor)(nil), seedPageSize).Return(pkg.CursorPage[model.Photo]{Data: existing}, nil)
+	for _, photo := range existing {
+		seeded := []model.Comment{}
+		for username, message := range comments {
+			seeded = append(seeded, model.Comment{PhotoID: photo.ID, UserID: ids[username], Message: message})
+		}
+		commentSvc.On("GetComments", ctx, uint64(0), photo.ID, (*pkg.Cursor)(nil), seedPageSize).Return(pkg.CursorPage[model.Comment]{Data: seeded}, nil)
+	}
+
+	s := NewSeeder("development", userSvc, photoSvc, commentSvc)
+	assert.Nil(t, s.Seed(ctx))
+	photoSvc.AssertNotCalled(t, "CreatePhoto", mock.Anything, mock.Anything, mock.Anything)
+	commentSvc.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything, mock.Anything)
+}
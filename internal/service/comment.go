@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-mygram/internal/config"
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository"
+	"go-mygram/pkg"
+
+	"gorm.io/gorm"
+)
+
+type CommentService interface {
+	CreateComment(ctx context.Context, userID uint64, commentPost model.CommentPost) (model.Comment, error)
+	GetComments(ctx context.Context, viewerID uint64, photoID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Comment], error)
+	GetPhotoComments(ctx context.Context, viewerID uint64, photoID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Comment], error)
+	GetCommentByID(ctx context.Context, viewerID uint64, id uint64) (model.Comment, error)
+	UpdateComment(ctx context.Context, userID uint64, id uint64, commentUpdate model.CommentUpdate) (model.Comment, error)
+	DeleteComment(ctx context.Context, userID uint64, id uint64) error
+}
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrCommentNotOwner = errors.New("comment does not belong to user")
+	// ErrCommentEditWindowExpired is returned when the comment is older than
+	// the configured edit window
+	ErrCommentEditWindowExpired = errors.New("edit window expired")
+)
+
+type commentServiceImpl struct {
+	commentRepository repository.CommentRepository
+	photoRepository   repository.PhotoRepository
+	followRepository  repository.FollowRepository
+	cfg               config.CommentConfig
+}
+
+func NewCommentService(commentRepository repository.CommentRepository, photoRepository repository.PhotoRepository, followRepository repository.FollowRepository, cfg config.CommentConfig) CommentService {
+	return &commentServiceImpl{
+		commentRepository: commentRepository,
+		photoRepository:   photoRepository,
+		followRepository:  followRepository,
+		cfg:               cfg,
+	}
+}
+
+func (s *commentServiceImpl) CreateComment(ctx context.Context, userID uint64, commentPost model.CommentPost) (model.Comment, error) {
+	// make sure the commented photo exists and userID may see it
+	if err := s.checkPhotoVisible(ctx, userID, commentPost.PhotoID); err != nil {
+		return model.Comment{}, err
+	}
+
+	comment := model.Comment{
+		UserID:     userID,
+		PhotoID:    commentPost.PhotoID,
+		Message:    pkg.StripHTML(commentPost.Message),
+		RawMessage: commentPost.Message,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	return s.commentRepository.CreateComment(ctx, comment)
+}
+
+// GetComments only returns comments of photos viewerID may see, a photo
+// asked for by id must be visible
+func (s *commentServiceImpl) GetComments(ctx context.Context, viewerID uint64, photoID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Comment], error) {
+	if photoID != 0 {
+		if err := s.checkPhotoVisible(ctx, viewerID, photoID); err != nil {
+			return pkg.CursorPage[model.Comment]{}, err
+		}
+	}
+	comments, err := s.commentRepository.GetComments(ctx, viewerID, photoID, after, limit+1)
+	if err != nil {
+		return pkg.CursorPage[model.Comment]{}, err
+	}
+	return pkg.NewCursorPage(comments, limit, model.Comment.Cursor), nil
+}
+
+// GetPhotoComments lists the comments of a photo viewerID may see, newest
+// first
+func (s *commentServiceImpl) GetPhotoComments(ctx context.Context, viewerID uint64, photoID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Comment], error) {
+	if err := s.checkPhotoVisible(ctx, viewerID, photoID); err != nil {
+		return pkg.CursorPage[model.Comment]{}, err
+	}
+	comments, err := s.commentRepository.GetPhotoComments(ctx, photoID, after, limit+1)
+	if err != nil {
+		return pkg.CursorPage[model.Comment]{}, err
+	}
+	return pkg.NewCursorPage(comments, limit, model.Comment.Cursor), nil
+}
+
+// GetCommentByID returns a comment on a photo viewerID may see
+func (s *commentServiceImpl) GetCommentByID(ctx context.Context, viewerID uint64, id uint64) (model.Comment, error) {
+	comment, err := s.commentRepository.GetCommentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Comment{}, ErrCommentNotFound
+		}
+		return model.Comment{}, err
+	}
+	if err := s.checkPhotoVisible(ctx, viewerID, comment.PhotoID); err != nil {
+		return model.Comment{}, err
+	}
+	return comment, nil
+}
+
+func (s *commentServiceImpl) checkPhotoVisible(ctx context.Context, viewerID uint64, photoID uint64) error {
+	_, err := findVisiblePhoto(ctx, s.photoRepository, s.followRepository, viewerID, photoID)
+	return err
+}
+
+// getOwnedComment loads a comment and makes sure it was written by userID
+func (s *commentServiceImpl) getOwnedComment(ctx context.Context, userID uint64, id uint64) (model.Comment, error) {
+	comment, err := s.commentRepository.GetCommentByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Comment{}, ErrCommentNotFound
+		}
+		return model.Comment{}, err
+	}
+	if comment.UserID != userID {
+		return model.Comment{}, ErrCommentNotOwner
+	}
+	return comment, nil
+}
+
+func (s *commentServiceImpl) UpdateComment(ctx context.Context, userID uint64, id uint64, commentUpdate model.CommentUpdate) (model.Comment, error) {
+	comment, err := s.getOwnedComment(ctx, userID, id)
+	if err != nil {
+		return model.Comment{}, err
+	}
+	if s.cfg.EditWindow > 0 && time.Since(comment.CreatedAt) > s.cfg.EditWindow {
+		return model.Comment{}, ErrCommentEditWindowExpired
+	}
+
+	// Update comment fields
+	comment.Message = pkg.StripHTML(commentUpdate.Message)
+	comment.RawMessage = commentUpdate.Message
+	comment.UpdatedAt = time.Now()
+
+	return s.commentRepository.UpdateComment(ctx, comment)
+}
+
+func (s *commentServiceImpl) DeleteComment(ctx context.Context, userID uint64, id uint64) error {
+	if _, err := s.getOwnedComment(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.commentRepository.DeleteComment(ctx, id)
+}
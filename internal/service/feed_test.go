@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository/mocks"
+	"go-mygram/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFeed(t *testing.T) {
+	ctx := context.Background()
+	newer := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	older := newer.Add(-time.Hour)
+
+	t.Run("success empty feed", func(t *testing.T) {
+		photoMock := mocks.NewPhotoRepository(t)
+		photoMock.On("GetPhotos", ctx, uint64(7), "", (*pkg.Cursor)(nil), 3).Return([]model.Photo{}, nil)
+
+		svc := feedServiceImpl{photoRepository: photoMock}
+		page, err := svc.GetFeed(ctx, 7, nil, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, pkg.CursorPage[model.FeedItem]{Data: []model.FeedItem{}}, page)
+	})
+
+	t.Run("success aggregate page with next cursor", func(t *testing.T) {
+		after := &pkg.Cursor{CreatedAt: newer.Add(time.Hour), ID: 9}
+		photoMock := mocks.NewPhotoRepository(t)
+		photoMock.On("GetPhotos", ctx, uint64(7), "", after, 3).Return([]model.Photo{
+			{ID: 5, UserID: 1, CreatedAt: newer},
+			{ID: 4, UserID: 2, CreatedAt: older},
+			{ID: 3, UserID: 1, CreatedAt: older},
+		}, nil)
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByIDs", ctx, []uint64{1, 2}).Return([]model.User{{ID: 1, Username: "alice"}, {ID: 2, Username: "bob", Email: "bob@mail.com", Role: "admin"}}, nil)
+		commentMock := mocks.NewCommentRepository(t)
+		commentMock.On("GetLatestComments", ctx, []uint64{5, 4}, model.FeedTopComments).Return([]model.Comment{{ID: 8, PhotoID: 4, Message: "nice"}}, nil)
+		likeMock := mocks.NewLikeRepository(t)
+		likeMock.On("GetLikeStats", ctx, uint64(7), []uint64{5, 4}).Return(map[uint64]model.LikeStats{5: {PhotoID: 5, LikeCount: 2, LikedByMe: true}}, nil)
+
+		svc := feedServiceImpl{photoRepository: photoMock, userRepository: userMock, commentRepository: commentMock, likeRepository: likeMock}
+		page, err := svc.GetFeed(ctx, 7, after, 2)
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 2)
+
+		assert.Equal(t, "alice", page.Data[0].User.Username)
+		assert.Equal(t, int64(2), page.Data[0].LikeCount)
+		assert.True(t, page.Data[0].LikedByMe)
+		assert.Equal(t, []model.Comment{}, page.Data[0].TopComments)
+
+		// only the public fields of the owner
+		assert.Equal(t, &model.PublicUser{ID: 2, Username: "bob"}, page.Data[1].User)
+		assert.Equal(t, int64(0), page.Data[1].LikeCount)
+		assert.Equal(t, []model.Comment{{ID: 8, PhotoID: 4, Message: "nice"}}, page.Data[1].TopComments)
+
+		cursor, err := pkg.DecodeCursor(page.NextCursor)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(4), cursor.ID)
+		assert.True(t, older.Equal(cursor.CreatedAt))
+	})
+}
+
+func TestGetFollowingFeed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success empty when following nobody", func(t *testing.T) {
+		photoMock := mocks.NewPhotoRepository(t)
+		photoMock.On("GetFollowingPhotos", ctx, uint64(7), (*pkg.Cursor)(nil), 21).Return([]model.Photo{}, nil)
+
+		svc := feedServiceImpl{photoRepository: photoMock}
+		page, err := svc.GetFollowingFeed(ctx, 7, nil, 20)
+		assert.Nil(t, err)
+		assert.Equal(t, pkg.CursorPage[model.FeedItem]{Data: []model.FeedItem{}}, page)
+	})
+}
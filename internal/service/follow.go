@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository"
+	"go-mygram/pkg"
+)
+
+type FollowService interface {
+	Follow(ctx context.Context, followerID uint64, followeeID uint64) error
+	Unfollow(ctx context.Context, followerID uint64, followeeID uint64) error
+	GetFollowers(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FollowUser], error)
+	GetFollowing(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FollowUser], error)
+}
+
+var ErrFollowSelf = errors.New("you cannot follow yourself")
+
+type followServiceImpl struct {
+	followRepository repository.FollowRepository
+	userRepository   repository.UserQuery
+}
+
+func NewFollowService(followRepository repository.FollowRepository, userRepository repository.UserQuery) FollowService {
+	return &followServiceImpl{
+		followRepository: followRepository,
+		userRepository:   userRepository,
+	}
+}
+
+// Follow is idempotent, following a user twice keeps a single follow
+func (s *followServiceImpl) Follow(ctx context.Context, followerID uint64, followeeID uint64) error {
+	if followerID == followeeID {
+		return ErrFollowSelf
+	}
+	if err := s.checkUserExists(ctx, followeeID); err != nil {
+		return err
+	}
+	return s.followRepository.CreateFollow(ctx, model.Follow{FollowerID: followerID, FolloweeID: followeeID})
+}
+
+// Unfollow succeeds when the user was not followed
+func (s *followServiceImpl) Unfollow(ctx context.Context, followerID uint64, followeeID uint64) error {
+	if followerID == followeeID {
+		return ErrFollowSelf
+	}
+	if err := s.checkUserExists(ctx, followeeID); err != nil {
+		return err
+	}
+	return s.followRepository.DeleteFollow(ctx, followerID, followeeID)
+}
+
+func (s *followServiceImpl) GetFollowers(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FollowUser], error) {
+	return s.listFollows(ctx, s.followRepository.GetFollowers, userID, after, limit)
+}
+
+func (s *followServiceImpl) GetFollowing(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FollowUser], error) {
+	return s.listFollows(ctx, s.followRepository.GetFollowing, userID, after, limit)
+}
+
+type listFollowsFunc func(ctx context.Context, userID uint64, after *pkg.Cursor, limit int) ([]model.FollowUser, error)
+
+// listFollows pages through list for an existing user, filling the follow
+// counts of every entry
+func (s *followServiceImpl) listFollows(ctx context.Context, list listFollowsFunc, userID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.FollowUser], error) {
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return pkg.CursorPage[model.FollowUser]{}, err
+	}
+	users, err := list(ctx, userID, after, limit+1)
+	if err != nil {
+		return pkg.CursorPage[model.FollowUser]{}, err
+	}
+	page := pkg.NewCursorPage(users, limit, model.FollowUser.Cursor)
+	if len(page.Data) == 0 {
+		return page, nil
+	}
+	ids := make([]uint64, 0, len(page.Data))
+	for _, user := range page.Data {
+		ids = append(ids, user.ID)
+	}
+	counts, err := s.followRepository.GetFollowCounts(ctx, ids)
+	if err != nil {
+		return pkg.CursorPage[model.FollowUser]{}, err
+	}
+	for i := range page.Data {
+		page.Data[i].FollowerCount = counts[page.Data[i].ID].FollowerCount
+		page.Data[i].FollowingCount = counts[page.Data[i].ID].FollowingCount
+	}
+	return page, nil
+}
+
+func (s *followServiceImpl) checkUserExists(ctx context.Context, id uint64) error {
+	user, err := s.userRepository.GetUsersByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// fillFollowCounts sets the follow counts of users in place
+func fillFollowCounts(ctx context.Context, follows repository.FollowRepository, users []model.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	ids := make([]uint64, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	counts, err := follows.GetFollowCounts(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		users[i].FollowerCount = counts[users[i].ID].FollowerCount
+		users[i].FollowingCount = counts[users[i].ID].FollowingCount
+	}
+	return nil
+}
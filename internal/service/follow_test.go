@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository/mocks"
+	"go-mygram/pkg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollow(t *testing.T) {
+	t.Run("error follow yourself", func(t *testing.T) {
+		svc := followServiceImpl{}
+		err := svc.Follow(context.Background(), 1, 1)
+		assert.ErrorIs(t, err, ErrFollowSelf)
+	})
+
+	t.Run("error unknown user", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(2)).Return(model.User{}, nil)
+
+		svc := followServiceImpl{userRepository: userMock}
+		err := svc.Follow(context.Background(), 1, 2)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("success follow another user", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(2)).Return(model.User{ID: 2}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("CreateFollow", context.Background(), model.Follow{FollowerID: 1, FolloweeID: 2}).Return(nil)
+
+		svc := followServiceImpl{followRepository: followMock, userRepository: userMock}
+		err := svc.Follow(context.Background(), 1, 2)
+		assert.Nil(t, err)
+	})
+}
+
+func TestUnfollow(t *testing.T) {
+	t.Run("error unfollow yourself", func(t *testing.T) {
+		svc := followServiceImpl{}
+		err := svc.Unfollow(context.Background(), 1, 1)
+		assert.ErrorIs(t, err, ErrFollowSelf)
+	})
+
+	t.Run("success unfollow", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(2)).Return(model.User{ID: 2}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("DeleteFollow", context.Background(), uint64(1), uint64(2)).Return(nil)
+
+		svc := followServiceImpl{followRepository: followMock, userRepository: userMock}
+		err := svc.Unfollow(context.Background(), 1, 2)
+		assert.Nil(t, err)
+	})
+}
+
+func TestGetFollowers(t *testing.T) {
+	followedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("error unknown user", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(2)).Return(model.User{}, nil)
+
+		svc := followServiceImpl{followRepository: mocks.NewFollowRepository(t), userRepository: userMock}
+		_, err := svc.GetFollowers(context.Background(), 2, nil, 20)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("error count follows", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(2)).Return(model.User{ID: 2}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("GetFollowers", context.Background(), uint64(2), (*pkg.Cursor)(nil), 21).
+			Return([]model.FollowUser{{PublicUser: model.PublicUser{ID: 3}, FollowedAt: followedAt}}, nil)
+		followMock.On("GetFollowCounts", context.Background(), []uint64{3}).Return(nil, errors.New("some error"))
+
+		svc := followServiceImpl{followRepository: followMock, userRepository: userMock}
+		_, err := svc.GetFollowers(context.Background(), 2, nil, 20)
+		assert.EqualError(t, err, "some error")
+	})
+
+	t.Run("success page with next cursor and counts", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(2)).Return(model.User{ID: 2}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("GetFollowers", context.Background(), uint64(2), (*pkg.Cursor)(nil), 2).Return([]model.FollowUser{
+			{PublicUser: model.PublicUser{ID: 3}, FollowedAt: followedAt},
+			{PublicUser: model.PublicUser{ID: 4}, FollowedAt: followedAt.Add(-time.Hour)},
+		}, nil)
+		followMock.On("GetFollowCounts", context.Background(), []uint64{3}).
+			Return(map[uint64]model.FollowCounts{3: {UserID: 3, FollowerCount: 1, FollowingCount: 7}}, nil)
+
+		svc := followServiceImpl{followRepository: followMock, userRepository: userMock}
+		page, err := svc.GetFollowers(context.Background(), 2, nil, 1)
+		assert.Nil(t, err)
+		assert.Len(t, page.Data, 1)
+		assert.Equal(t, int64(1), page.Data[0].FollowerCount)
+		assert.Equal(t, int64(7), page.Data[0].FollowingCount)
+
+		cursor, err := pkg.DecodeCursor(page.NextCursor)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(3), cursor.ID)
+		assert.True(t, followedAt.Equal(cursor.CreatedAt))
+	})
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"go-mygram/pkg/helper"
+	"go-mygram/pkg/storage"
+)
+
+var ErrUnsupportedImageType = errors.New("image must be a jpeg or png")
+
+// imageExtensions are the accepted image content types
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// putImage stores an uploaded image under prefix with a fresh name, so
+// clients and caches never keep an old image around. It returns the key
+// together with the url
+func putImage(ctx context.Context, files storage.Storage, prefix string, r io.Reader, contentType string) (key string, url string, err error) {
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedImageType
+	}
+	suffix, err := helper.GenerateRandomToken(8)
+	if err != nil {
+		return "", "", err
+	}
+	key = fmt.Sprintf("%s-%s%s", prefix, suffix, ext)
+	url, err = files.Put(ctx, key, r, contentType)
+	if err != nil {
+		return "", "", err
+	}
+	return key, url, nil
+}
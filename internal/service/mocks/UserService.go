@@ -4,9 +4,13 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 
-	model "go-mygram/internal/model"
 	mock "github.com/stretchr/testify/mock"
+
+	model "go-mygram/internal/model"
+
+	time "time"
 )
 
 // UserService is an autogenerated mock type for the UserService type
@@ -14,6 +18,24 @@ type UserService struct {
 	mock.Mock
 }
 
+// ChangePassword provides a mock function with given fields: ctx, id, currentPassword, newPassword, refreshToken
+func (_m *UserService) ChangePassword(ctx context.Context, id uint64, currentPassword string, newPassword string, refreshToken string) error {
+	ret := _m.Called(ctx, id, currentPassword, newPassword, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string, string, string) error); ok {
+		r0 = rf(ctx, id, currentPassword, newPassword, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteUsersById provides a mock function with given fields: ctx, id
 func (_m *UserService) DeleteUsersById(ctx context.Context, id uint64) (model.User, error) {
 	ret := _m.Called(ctx, id)
@@ -42,12 +64,12 @@ func (_m *UserService) DeleteUsersById(ctx context.Context, id uint64) (model.Us
 	return r0, r1
 }
 
-// GenerateUserAccessToken provides a mock function with given fields: ctx, user
-func (_m *UserService) GenerateUserAccessToken(ctx context.Context, user model.User) (string, error) {
+// GenerateRefreshToken provides a mock function with given fields: ctx, user
+func (_m *UserService) GenerateRefreshToken(ctx context.Context, user model.User) (string, error) {
 	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GenerateUserAccessToken")
+		panic("no return value specified for GenerateRefreshToken")
 	}
 
 	var r0 string
@@ -70,29 +92,178 @@ func (_m *UserService) GenerateUserAccessToken(ctx context.Context, user model.U
 	return r0, r1
 }
 
-// GetUsers provides a mock function with given fields: ctx
-func (_m *UserService) GetUsers(ctx context.Context) ([]model.User, error) {
-	ret := _m.Called(ctx)
+// GenerateUserAccessToken provides a mock function with given fields: ctx, user
+func (_m *UserService) GenerateUserAccessToken(ctx context.Context, user model.User) (model.AccessToken, error) {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateUserAccessToken")
+	}
+
+	var r0 model.AccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.User) (model.AccessToken, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.User) model.AccessToken); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Get(0).(model.AccessToken)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserByIDOrUsername provides a mock function with given fields: ctx, key
+func (_m *UserService) GetUserByIDOrUsername(ctx context.Context, key string) (model.User, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByIDOrUsername")
+	}
+
+	var r0 model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (model.User, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.User); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserCount provides a mock function with given fields: ctx, params
+func (_m *UserService) GetUserCount(ctx context.Context, params model.UserCountParams) (int64, error) {
+	ret := _m.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserCount")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserCountParams) (int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserCountParams) int64); ok {
+		r0 = rf(ctx, params)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.UserCountParams) error); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserStats provides a mock function with given fields: ctx, viewerID, id
+func (_m *UserService) GetUserStats(ctx context.Context, viewerID uint64, id uint64) (model.UserStats, error) {
+	ret := _m.Called(ctx, viewerID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserStats")
+	}
+
+	var r0 model.UserStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) (model.UserStats, error)); ok {
+		return rf(ctx, viewerID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) model.UserStats); ok {
+		r0 = rf(ctx, viewerID, id)
+	} else {
+		r0 = ret.Get(0).(model.UserStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, viewerID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsers provides a mock function with given fields: ctx, params
+func (_m *UserService) GetUsers(ctx context.Context, params model.UserListParams) ([]model.User, int64, error) {
+	ret := _m.Called(ctx, params)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUsers")
 	}
 
+	var r0 []model.User
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserListParams) ([]model.User, int64, error)); ok {
+		return rf(ctx, params)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserListParams) []model.User); ok {
+		r0 = rf(ctx, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.UserListParams) int64); ok {
+		r1 = rf(ctx, params)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.UserListParams) error); ok {
+		r2 = rf(ctx, params)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetUsersByIDs provides a mock function with given fields: ctx, ids
+func (_m *UserService) GetUsersByIDs(ctx context.Context, ids []uint64) ([]model.User, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsersByIDs")
+	}
+
 	var r0 []model.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]model.User, error)); ok {
-		return rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64) ([]model.User, error)); ok {
+		return rf(ctx, ids)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []model.User); ok {
-		r0 = rf(ctx)
+	if rf, ok := ret.Get(0).(func(context.Context, []uint64) []model.User); ok {
+		r0 = rf(ctx, ids)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]model.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
+	if rf, ok := ret.Get(1).(func(context.Context, []uint64) error); ok {
+		r1 = rf(ctx, ids)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -128,6 +299,248 @@ func (_m *UserService) GetUsersById(ctx context.Context, id uint64) (model.User,
 	return r0, r1
 }
 
+// HardDeleteUser provides a mock function with given fields: ctx, id
+func (_m *UserService) HardDeleteUser(ctx context.Context, id uint64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HardDeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IsUsernameAvailable provides a mock function with given fields: ctx, username
+func (_m *UserService) IsUsernameAvailable(ctx context.Context, username string) (bool, error) {
+	ret := _m.Called(ctx, username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsUsernameAvailable")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeletedUsers provides a mock function with given fields: ctx, before
+func (_m *UserService) PurgeDeletedUsers(ctx context.Context, before time.Time) (int, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeletedUsers")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefreshAccessToken provides a mock function with given fields: ctx, refreshToken
+func (_m *UserService) RefreshAccessToken(ctx context.Context, refreshToken string) (model.AccessToken, error) {
+	ret := _m.Called(ctx, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshAccessToken")
+	}
+
+	var r0 model.AccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (model.AccessToken, error)); ok {
+		return rf(ctx, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) model.AccessToken); ok {
+		r0 = rf(ctx, refreshToken)
+	} else {
+		r0 = ret.Get(0).(model.AccessToken)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceUser provides a mock function with given fields: ctx, id, replaceUser
+func (_m *UserService) ReplaceUser(ctx context.Context, id uint64, replaceUser model.UserReplace) (model.User, error) {
+	ret := _m.Called(ctx, id, replaceUser)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceUser")
+	}
+
+	var r0 model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, model.UserReplace) (model.User, error)); ok {
+		return rf(ctx, id, replaceUser)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, model.UserReplace) model.User); ok {
+		r0 = rf(ctx, id, replaceUser)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, model.UserReplace) error); ok {
+		r1 = rf(ctx, id, replaceUser)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestPasswordReset provides a mock function with given fields: ctx, email
+func (_m *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestPasswordReset")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResetPassword provides a mock function with given fields: ctx, token, newPassword
+func (_m *UserService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	ret := _m.Called(ctx, token, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, token, newPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchUsernames provides a mock function with given fields: ctx, prefix, limit
+func (_m *UserService) SearchUsernames(ctx context.Context, prefix string, limit int) ([]model.MentionedUser, error) {
+	ret := _m.Called(ctx, prefix, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchUsernames")
+	}
+
+	var r0 []model.MentionedUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]model.MentionedUser, error)); ok {
+		return rf(ctx, prefix, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []model.MentionedUser); ok {
+		r0 = rf(ctx, prefix, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.MentionedUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, prefix, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SignIn provides a mock function with given fields: ctx, userSignIn
+func (_m *UserService) SignIn(ctx context.Context, userSignIn model.UserSignIn) (model.User, error) {
+	ret := _m.Called(ctx, userSignIn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SignIn")
+	}
+
+	var r0 model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserSignIn) (model.User, error)); ok {
+		return rf(ctx, userSignIn)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserSignIn) model.User); ok {
+		r0 = rf(ctx, userSignIn)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.UserSignIn) error); ok {
+		r1 = rf(ctx, userSignIn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SignOut provides a mock function with given fields: ctx, jti, expiresAt
+func (_m *UserService) SignOut(ctx context.Context, jti string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, jti, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SignOut")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, jti, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SignUp provides a mock function with given fields: ctx, userSignUp
 func (_m *UserService) SignUp(ctx context.Context, userSignUp model.UserSignUp) (model.User, error) {
 	ret := _m.Called(ctx, userSignUp)
@@ -156,6 +569,115 @@ func (_m *UserService) SignUp(ctx context.Context, userSignUp model.UserSignUp)
 	return r0, r1
 }
 
+// SignUpWithTokens provides a mock function with given fields: ctx, userSignUp
+func (_m *UserService) SignUpWithTokens(ctx context.Context, userSignUp model.UserSignUp) (model.User, model.TokenPair, error) {
+	ret := _m.Called(ctx, userSignUp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SignUpWithTokens")
+	}
+
+	var r0 model.User
+	var r1 model.TokenPair
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserSignUp) (model.User, model.TokenPair, error)); ok {
+		return rf(ctx, userSignUp)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserSignUp) model.User); ok {
+		r0 = rf(ctx, userSignUp)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.UserSignUp) model.TokenPair); ok {
+		r1 = rf(ctx, userSignUp)
+	} else {
+		r1 = ret.Get(1).(model.TokenPair)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.UserSignUp) error); ok {
+		r2 = rf(ctx, userSignUp)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateAvatar provides a mock function with given fields: ctx, id, file, contentType
+func (_m *UserService) UpdateAvatar(ctx context.Context, id uint64, file io.Reader, contentType string) (model.User, error) {
+	ret := _m.Called(ctx, id, file, contentType)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAvatar")
+	}
+
+	var r0 model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, io.Reader, string) (model.User, error)); ok {
+		return rf(ctx, id, file, contentType)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, io.Reader, string) model.User); ok {
+		r0 = rf(ctx, id, file, contentType)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, io.Reader, string) error); ok {
+		r1 = rf(ctx, id, file, contentType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateUserByID provides a mock function with given fields: ctx, id, updateUser
+func (_m *UserService) UpdateUserByID(ctx context.Context, id uint64, updateUser model.UserUpdate) (model.User, error) {
+	ret := _m.Called(ctx, id, updateUser)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserByID")
+	}
+
+	var r0 model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, model.UserUpdate) (model.User, error)); ok {
+		return rf(ctx, id, updateUser)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, model.UserUpdate) model.User); ok {
+		r0 = rf(ctx, id, updateUser)
+	} else {
+		r0 = ret.Get(0).(model.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, model.UserUpdate) error); ok {
+		r1 = rf(ctx, id, updateUser)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyEmail provides a mock function with given fields: ctx, token
+func (_m *UserService) VerifyEmail(ctx context.Context, token string) error {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewUserService creates a new instance of UserService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserService(t interface {
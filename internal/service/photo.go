@@ -3,52 +3,225 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"time"
 
+	"go-mygram/internal/config"
 	"go-mygram/internal/model"
 	"go-mygram/internal/repository"
+	"go-mygram/pkg"
+	"go-mygram/pkg/storage"
+
+	"gorm.io/gorm"
 )
 
+// PhotoService fills LikeCount and LikedByMe of the returned photos as seen
+// by viewerID
 type PhotoService interface {
-	GetPhotos(ctx context.Context) ([]model.Photo, error)
-	GetPhotoByID(ctx context.Context, id uint64) (model.Photo, error)
-	UpdatePhoto(ctx context.Context, id uint64, updatedPhoto model.PhotoPost) (model.Photo, error)
-	DeletePhotoByID(ctx context.Context, id uint64) error
+	GetPhotos(ctx context.Context, viewerID uint64, tag string, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Photo], error)
+	GetPhotosByUserID(ctx context.Context, viewerID uint64, userID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Photo], error)
+	GetPopularPhotos(ctx context.Context, viewerID uint64, tag string, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Photo], error)
+	GetPhotoByID(ctx context.Context, viewerID uint64, id uint64) (model.Photo, error)
+	UpdatePhoto(ctx context.Context, userID uint64, id uint64, updatedPhoto model.PhotoPost) (model.Photo, error)
+	DeletePhoto(ctx context.Context, userID uint64, id uint64) error
 	CreatePhoto(ctx context.Context, userID uint64, photo model.PhotoPost) (model.Photo, error)
+	LikePhoto(ctx context.Context, userID uint64, id uint64) error
+	UnlikePhoto(ctx context.Context, userID uint64, id uint64) error
+	GetPhotoLikers(ctx context.Context, viewerID uint64, id uint64) ([]model.User, error)
+	UploadPhotoImage(ctx context.Context, userID uint64, file io.Reader, contentType string) (string, error)
+	GetPopularTags(ctx context.Context, limit int) ([]model.TagCount, error)
 }
 
+var (
+	ErrPhotoNotFound = errors.New("photo not found")
+	ErrPhotoNotOwner = errors.New("photo does not belong to user")
+	// ErrPhotoNotVisible is returned for a followers only photo, private
+	// photos are reported as ErrPhotoNotFound to not reveal they exist
+	ErrPhotoNotVisible = errors.New("photo is only visible to followers")
+	// ErrPhotoLimitReached is wrapped with the limit, see config.PhotoConfig
+	ErrPhotoLimitReached = errors.New("photo limit reached")
+)
+
 type photoServiceImpl struct {
-	photoRepository repository.PhotoRepository
+	photoRepository  repository.PhotoRepository
+	userRepository   repository.UserQuery
+	likeRepository   repository.LikeRepository
+	followRepository repository.FollowRepository
+	images           storage.Storage
+	cfg              config.PhotoConfig
 }
 
-func NewPhotoService(photoRepository repository.PhotoRepository) PhotoService {
+func NewPhotoService(photoRepository repository.PhotoRepository, userRepository repository.UserQuery, likeRepository repository.LikeRepository, followRepository repository.FollowRepository, images storage.Storage, cfg config.PhotoConfig) PhotoService {
 	return &photoServiceImpl{
-		photoRepository: photoRepository,
+		photoRepository:  photoRepository,
+		userRepository:   userRepository,
+		likeRepository:   likeRepository,
+		followRepository: followRepository,
+		images:           images,
+		cfg:              cfg,
 	}
 }
 
-func (s *photoServiceImpl) GetPhotos(ctx context.Context) ([]model.Photo, error) {
-	return s.photoRepository.GetPhotos(ctx)
+func (s *photoServiceImpl) GetPhotos(ctx context.Context, viewerID uint64, tag string, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Photo], error) {
+	photos, err := s.photoRepository.GetPhotos(ctx, viewerID, tag, after, limit+1)
+	if err != nil {
+		return pkg.CursorPage[model.Photo]{}, err
+	}
+	page := pkg.NewCursorPage(photos, limit, model.Photo.Cursor)
+	if err := s.fillLikes(ctx, viewerID, page.Data); err != nil {
+		return pkg.CursorPage[model.Photo]{}, err
+	}
+	return page, nil
 }
 
-func (s *photoServiceImpl) GetPhotoByID(ctx context.Context, id uint64) (model.Photo, error) {
-	return s.photoRepository.GetPhotoByID(ctx, id)
+// GetPopularPhotos ranks the photos posted within cfg.PopularWindow by like
+// count. Counts move as likes come in, so a photo liked between two pages
+// may show up twice or not at all
+func (s *photoServiceImpl) GetPopularPhotos(ctx context.Context, viewerID uint64, tag string, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Photo], error) {
+	since := time.Now().Add(-s.cfg.PopularWindow)
+	photos, err := s.photoRepository.GetPopularPhotos(ctx, viewerID, tag, since, after, limit+1)
+	if err != nil {
+		return pkg.CursorPage[model.Photo]{}, err
+	}
+	// the cursor carries the like count, so it is filled before paging
+	if err := s.fillLikes(ctx, viewerID, photos); err != nil {
+		return pkg.CursorPage[model.Photo]{}, err
+	}
+	return pkg.NewCursorPage(photos, limit, model.Photo.PopularCursor), nil
 }
 
-func (s *photoServiceImpl) UpdatePhoto(ctx context.Context, id uint64, updatedPhoto model.PhotoPost) (model.Photo, error) {
-	// Get photo by ID
-	photo, err := s.photoRepository.GetPhotoByID(ctx, id)
+// GetPhotosByUserID lists the photos of userID, a user without photos gets
+// an empty page while an unknown user is ErrUserNotFound
+func (s *photoServiceImpl) GetPhotosByUserID(ctx context.Context, viewerID uint64, userID uint64, after *pkg.Cursor, limit int) (pkg.CursorPage[model.Photo], error) {
+	user, err := s.userRepository.GetUsersByID(ctx, userID)
+	if err != nil {
+		return pkg.CursorPage[model.Photo]{}, err
+	}
+	if user.ID == 0 {
+		return pkg.CursorPage[model.Photo]{}, ErrUserNotFound
+	}
+
+	photos, err := s.photoRepository.GetPhotosByUserID(ctx, viewerID, userID, after, limit+1)
+	if err != nil {
+		return pkg.CursorPage[model.Photo]{}, err
+	}
+	page := pkg.NewCursorPage(photos, limit, model.Photo.Cursor)
+	if err := s.fillLikes(ctx, viewerID, page.Data); err != nil {
+		return pkg.CursorPage[model.Photo]{}, err
+	}
+	return page, nil
+}
+
+func (s *photoServiceImpl) GetPhotoByID(ctx context.Context, viewerID uint64, id uint64) (model.Photo, error) {
+	photo, err := s.findVisiblePhoto(ctx, viewerID, id)
+	if err != nil {
+		return model.Photo{}, err
+	}
+	photos := []model.Photo{photo}
+	if err := s.fillLikes(ctx, viewerID, photos); err != nil {
+		return model.Photo{}, err
+	}
+	return photos[0], nil
+}
+
+func (s *photoServiceImpl) findPhoto(ctx context.Context, id uint64) (model.Photo, error) {
+	return findPhoto(ctx, s.photoRepository, id)
+}
+
+// findVisiblePhoto is findPhoto for a photo viewerID is allowed to see
+func (s *photoServiceImpl) findVisiblePhoto(ctx context.Context, viewerID uint64, id uint64) (model.Photo, error) {
+	return findVisiblePhoto(ctx, s.photoRepository, s.followRepository, viewerID, id)
+}
+
+func findPhoto(ctx context.Context, photoRepository repository.PhotoRepository, id uint64) (model.Photo, error) {
+	photo, err := photoRepository.GetPhotoByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Photo{}, ErrPhotoNotFound
+		}
+		return model.Photo{}, err
+	}
+	return photo, nil
+}
+
+// findVisiblePhoto applies the visibility of a photo for viewerID, every
+// service reaching a photo on behalf of a user goes through it
+func findVisiblePhoto(ctx context.Context, photoRepository repository.PhotoRepository, followRepository repository.FollowRepository, viewerID uint64, id uint64) (model.Photo, error) {
+	photo, err := findPhoto(ctx, photoRepository, id)
 	if err != nil {
 		return model.Photo{}, err
 	}
-	// If photo doesn't exist, return error
-	if photo.ID == 0 {
-		return model.Photo{}, errors.New("photo not found")
+	if photo.UserID == viewerID {
+		return photo, nil
+	}
+	switch photo.Visibility {
+	case model.VisibilityPrivate:
+		return model.Photo{}, ErrPhotoNotFound
+	case model.VisibilityFollowers:
+		following, err := followRepository.IsFollowing(ctx, viewerID, photo.UserID)
+		if err != nil {
+			return model.Photo{}, err
+		}
+		if !following {
+			return model.Photo{}, ErrPhotoNotVisible
+		}
+	}
+	return photo, nil
+}
+
+// fillLikes sets the like fields of photos in place
+func (s *photoServiceImpl) fillLikes(ctx context.Context, viewerID uint64, photos []model.Photo) error {
+	if len(photos) == 0 {
+		return nil
+	}
+	ids := make([]uint64, 0, len(photos))
+	for _, photo := range photos {
+		ids = append(ids, photo.ID)
+	}
+	stats, err := s.likeRepository.GetLikeStats(ctx, viewerID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range photos {
+		photos[i].LikeCount = stats[photos[i].ID].LikeCount
+		photos[i].LikedByMe = stats[photos[i].ID].LikedByMe
+	}
+	return nil
+}
+
+// getOwnedPhoto loads a photo and makes sure it belongs to userID
+func (s *photoServiceImpl) getOwnedPhoto(ctx context.Context, userID uint64, id uint64) (model.Photo, error) {
+	photo, err := s.findPhoto(ctx, id)
+	if err != nil {
+		return model.Photo{}, err
+	}
+	if photo.UserID != userID {
+		return model.Photo{}, ErrPhotoNotOwner
+	}
+	return photo, nil
+}
+
+func (s *photoServiceImpl) UpdatePhoto(ctx context.Context, userID uint64, id uint64, updatedPhoto model.PhotoPost) (model.Photo, error) {
+	// Get photo by ID
+	photo, err := s.getOwnedPhoto(ctx, userID, id)
+	if err != nil {
+		return model.Photo{}, err
 	}
 
 	// Update photo fields
-	photo.Title = updatedPhoto.Title
-	photo.Caption = updatedPhoto.Caption
+	photo.Title = pkg.StripHTML(updatedPhoto.Title)
+	photo.Caption = pkg.StripHTML(updatedPhoto.Caption)
+	photo.RawCaption = updatedPhoto.Caption
 	photo.PhotoURL = updatedPhoto.PhotoURL
+	if updatedPhoto.Visibility != "" {
+		photo.Visibility = updatedPhoto.Visibility
+	}
+	photo.Mentions, err = s.resolveMentions(ctx, photo.Caption)
+	if err != nil {
+		return model.Photo{}, err
+	}
+	photo.Tags = toTags(updatedPhoto.Tags)
 
 	// Save updated photo
 	updatedPhotoResult, err := s.photoRepository.UpdatePhoto(ctx, photo)
@@ -56,20 +229,114 @@ func (s *photoServiceImpl) UpdatePhoto(ctx context.Context, id uint64, updatedPh
 		return model.Photo{}, err
 	}
 
-	return updatedPhotoResult, nil
+	photos := []model.Photo{updatedPhotoResult}
+	if err := s.fillLikes(ctx, userID, photos); err != nil {
+		return model.Photo{}, err
+	}
+	return photos[0], nil
 }
 
-func (s *photoServiceImpl) DeletePhotoByID(ctx context.Context, id uint64) error {
+func (s *photoServiceImpl) DeletePhoto(ctx context.Context, userID uint64, id uint64) error {
+	if _, err := s.getOwnedPhoto(ctx, userID, id); err != nil {
+		return err
+	}
 	return s.photoRepository.DeletePhotoByID(ctx, id)
 }
 
+// CreatePhoto strips the markup from the title and caption, they are shown
+// as is by the clients. The caption as posted is kept in RawCaption
 func (s *photoServiceImpl) CreatePhoto(ctx context.Context, userID uint64, photo model.PhotoPost) (model.Photo, error) {
+	if s.cfg.MaxPerUser > 0 {
+		count, err := s.photoRepository.CountPhotosByUserID(ctx, userID)
+		if err != nil {
+			return model.Photo{}, err
+		}
+		if count >= int64(s.cfg.MaxPerUser) {
+			return model.Photo{}, fmt.Errorf("%w, you can keep at most %d photos", ErrPhotoLimitReached, s.cfg.MaxPerUser)
+		}
+	}
+
+	caption := pkg.StripHTML(photo.Caption)
+	mentions, err := s.resolveMentions(ctx, caption)
+	if err != nil {
+		return model.Photo{}, err
+	}
+
 	newPhoto := model.Photo{
-		Title:    photo.Title,
-		Caption:  photo.Caption,
-		PhotoURL: photo.PhotoURL,
-		UserID:   userID,
+		Title:      pkg.StripHTML(photo.Title),
+		Caption:    caption,
+		RawCaption: photo.Caption,
+		PhotoURL:   photo.PhotoURL,
+		UserID:     userID,
+		Visibility: photo.Visibility,
+		Mentions:   mentions,
+		Tags:       toTags(photo.Tags),
+	}
+	if newPhoto.Visibility == "" {
+		newPhoto.Visibility = model.VisibilityPublic
 	}
 
 	return s.photoRepository.CreatePhoto(ctx, newPhoto)
 }
+
+func (s *photoServiceImpl) GetPopularTags(ctx context.Context, limit int) ([]model.TagCount, error) {
+	return s.photoRepository.GetPopularTags(ctx, limit)
+}
+
+// toTags normalizes the tags of a photo post, the repository resolves the
+// ids by name
+func toTags(names []string) []model.Tag {
+	var tags []model.Tag
+	for _, name := range model.NormalizeTags(names) {
+		tags = append(tags, model.Tag{Name: name})
+	}
+	return tags
+}
+
+// resolveMentions looks up the users mentioned in caption, unknown
+// usernames are ignored
+func (s *photoServiceImpl) resolveMentions(ctx context.Context, caption string) ([]model.MentionedUser, error) {
+	usernames := model.ParseMentions(caption)
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+	users, err := s.userRepository.FindByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+	mentions := make([]model.MentionedUser, 0, len(users))
+	for _, user := range users {
+		mentions = append(mentions, model.MentionedUser{ID: user.ID, Username: user.Username})
+	}
+	return mentions, nil
+}
+
+// LikePhoto is idempotent, liking a photo twice keeps a single like
+func (s *photoServiceImpl) LikePhoto(ctx context.Context, userID uint64, id uint64) error {
+	if _, err := s.findVisiblePhoto(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.likeRepository.CreateLike(ctx, model.Like{UserID: userID, PhotoID: id})
+}
+
+// UnlikePhoto succeeds when the user did not like the photo
+func (s *photoServiceImpl) UnlikePhoto(ctx context.Context, userID uint64, id uint64) error {
+	if _, err := s.findVisiblePhoto(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.likeRepository.DeleteLike(ctx, userID, id)
+}
+
+func (s *photoServiceImpl) GetPhotoLikers(ctx context.Context, viewerID uint64, id uint64) ([]model.User, error) {
+	if _, err := s.findVisiblePhoto(ctx, viewerID, id); err != nil {
+		return nil, err
+	}
+	return s.likeRepository.GetLikers(ctx, id)
+}
+
+// UploadPhotoImage stores an image for a photo the user is about to post
+// and returns the url to use as photo_url
+func (s *photoServiceImpl) UploadPhotoImage(ctx context.Context, userID uint64, file io.Reader, contentType string) (string, error) {
+	_, url, err := putImage(ctx, s.images, fmt.Sprintf("photos/%d", userID), file, contentType)
+	return url, err
+}
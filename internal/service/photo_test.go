@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-mygram/internal/config"
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository/mocks"
+	"go-mygram/pkg"
+	"go-mygram/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestUpdatePhoto(t *testing.T) {
+	post := model.PhotoPost{Title: "title", Caption: "caption", PhotoURL: "https://example.com/a.jpg"}
+
+	t.Run("error photo not found", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{}, gorm.ErrRecordNotFound)
+
+		svc := photoServiceImpl{photoRepository: repoMock}
+		_, err := svc.UpdatePhoto(context.Background(), 1, 10, post)
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("error photo owned by other user", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock}
+		_, err := svc.UpdatePhoto(context.Background(), 1, 10, post)
+		assert.ErrorIs(t, err, ErrPhotoNotOwner)
+	})
+
+	t.Run("success update own photo", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 1}, nil)
+		repoMock.
+			On("UpdatePhoto", context.Background(), model.Photo{ID: 10, UserID: 1, Title: "title", Caption: "caption", RawCaption: "caption", PhotoURL: "https://example.com/a.jpg"}).
+			Return(model.Photo{ID: 10, UserID: 1, Title: "title"}, nil)
+		likeMock := mocks.NewLikeRepository(t)
+		likeMock.On("GetLikeStats", context.Background(), uint64(1), []uint64{10}).
+			Return(map[uint64]model.LikeStats{10: {PhotoID: 10, LikeCount: 3, LikedByMe: true}}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, likeRepository: likeMock}
+		photo, err := svc.UpdatePhoto(context.Background(), 1, 10, post)
+		assert.Nil(t, err)
+		assert.Equal(t, "title", photo.Title)
+		assert.Equal(t, int64(3), photo.LikeCount)
+	})
+}
+
+func TestDeletePhoto(t *testing.T) {
+	t.Run("error photo owned by other user", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock}
+		err := svc.DeletePhoto(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrPhotoNotOwner)
+	})
+}
+
+func TestCreatePhotoMentions(t *testing.T) {
+	post := model.PhotoPost{Title: "title", Caption: "with @alice and @ghost.", PhotoURL: "https://example.com/a.jpg"}
+
+	t.Run("error find users", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("FindByUsernames", context.Background(), []string{"alice", "ghost"}).Return(nil, errors.New("some error"))
+
+		svc := photoServiceImpl{userRepository: userMock}
+		_, err := svc.CreatePhoto(context.Background(), 1, post)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("success unknown usernames are ignored", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("FindByUsernames", context.Background(), []string{"alice", "ghost"}).
+			Return([]model.User{{ID: 2, Username: "alice", Email: "alice@example.com"}}, nil)
+		repoMock := mocks.NewPhotoRepository(t)
+		want := model.Photo{
+			Title:      "title",
+			Caption:    "with @alice and @ghost.",
+			RawCaption: "with @alice and @ghost.",
+			PhotoURL:   "https://example.com/a.jpg",
+			UserID:     1,
+			Visibility: model.VisibilityPublic,
+			Mentions:   []model.MentionedUser{{ID: 2, Username: "alice"}},
+		}
+		repoMock.On("CreatePhoto", context.Background(), want).Return(want, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, userRepository: userMock}
+		photo, err := svc.CreatePhoto(context.Background(), 1, post)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.MentionedUser{{ID: 2, Username: "alice"}}, photo.Mentions)
+	})
+}
+
+func TestCreatePhotoTags(t *testing.T) {
+	post := model.PhotoPost{Title: "title", PhotoURL: "https://example.com/a.jpg", Tags: []string{"#Sunset", "beach", "sunset"}}
+	want := model.Photo{
+		Title:      "title",
+		PhotoURL:   "https://example.com/a.jpg",
+		UserID:     1,
+		Visibility: model.VisibilityPublic,
+		Tags:       []model.Tag{{Name: "sunset"}, {Name: "beach"}},
+	}
+	repoMock := mocks.NewPhotoRepository(t)
+	repoMock.On("CreatePhoto", context.Background(), want).Return(want, nil)
+
+	svc := photoServiceImpl{photoRepository: repoMock}
+	photo, err := svc.CreatePhoto(context.Background(), 1, post)
+	assert.Nil(t, err)
+	assert.Equal(t, want.Tags, photo.Tags)
+}
+
+func TestCreatePhotoLimit(t *testing.T) {
+	post := model.PhotoPost{Title: "title", PhotoURL: "https://example.com/a.jpg"}
+
+	t.Run("success one below the limit", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("CountPhotosByUserID", context.Background(), uint64(1)).Return(int64(2), nil)
+		repoMock.On("CreatePhoto", context.Background(), mock.Anything).Return(model.Photo{ID: 3}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, cfg: config.PhotoConfig{MaxPerUser: 3}}
+		_, err := svc.CreatePhoto(context.Background(), 1, post)
+		assert.Nil(t, err)
+	})
+
+	t.Run("error at the limit", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("CountPhotosByUserID", context.Background(), uint64(1)).Return(int64(3), nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, cfg: config.PhotoConfig{MaxPerUser: 3}}
+		_, err := svc.CreatePhoto(context.Background(), 1, post)
+		assert.ErrorIs(t, err, ErrPhotoLimitReached)
+		assert.EqualError(t, err, "photo limit reached, you can keep at most 3 photos")
+	})
+
+	t.Run("success unlimited skips the count", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("CreatePhoto", context.Background(), mock.Anything).Return(model.Photo{ID: 3}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock}
+		_, err := svc.CreatePhoto(context.Background(), 1, post)
+		assert.Nil(t, err)
+	})
+}
+
+func TestCreatePhotoStripsCaptionMarkup(t *testing.T) {
+	post := model.PhotoPost{Title: "<i>title</i><script>alert(1)</script>", Caption: `<b>@alice</b> <script>alert("xss")</script>sunset`, PhotoURL: "https://example.com/a.jpg"}
+	userMock := mocks.NewUserQuery(t)
+	userMock.On("FindByUsernames", context.Background(), []string{"alice"}).Return([]model.User{{ID: 2, Username: "alice"}}, nil)
+	repoMock := mocks.NewPhotoRepository(t)
+	repoMock.On("CreatePhoto", context.Background(), mock.MatchedBy(func(p model.Photo) bool {
+		return p.Title == "title" && p.Caption == "@alice sunset" && p.RawCaption == post.Caption
+	})).Return(model.Photo{ID: 1, Caption: "@alice sunset"}, nil)
+
+	svc := photoServiceImpl{photoRepository: repoMock, userRepository: userMock}
+	photo, err := svc.CreatePhoto(context.Background(), 1, post)
+	assert.Nil(t, err)
+	assert.Equal(t, "@alice sunset", photo.Caption)
+}
+
+func TestGetPhotos(t *testing.T) {
+	repoMock := mocks.NewPhotoRepository(t)
+	repoMock.On("GetPhotos", context.Background(), uint64(7), "", (*pkg.Cursor)(nil), 3).Return([]model.Photo{{ID: 1}, {ID: 2}}, nil)
+	likeMock := mocks.NewLikeRepository(t)
+	likeMock.On("GetLikeStats", context.Background(), uint64(7), []uint64{1, 2}).
+		Return(map[uint64]model.LikeStats{2: {PhotoID: 2, LikeCount: 1, LikedByMe: true}}, nil)
+
+	svc := photoServiceImpl{photoRepository: repoMock, likeRepository: likeMock}
+	page, err := svc.GetPhotos(context.Background(), 7, "", nil, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Photo{{ID: 1}, {ID: 2, LikeCount: 1, LikedByMe: true}}, page.Data)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestGetPopularPhotos(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	repoMock := mocks.NewPhotoRepository(t)
+	repoMock.On("GetPopularPhotos", context.Background(), uint64(7), "", mock.MatchedBy(func(since time.Time) bool {
+		return time.Since(since).Round(time.Hour) == 7*24*time.Hour
+	}), (*pkg.Cursor)(nil), 2).Return([]model.Photo{{ID: 2, CreatedAt: createdAt}, {ID: 1, CreatedAt: createdAt}}, nil)
+	likeMock := mocks.NewLikeRepository(t)
+	likeMock.On("GetLikeStats", context.Background(), uint64(7), []uint64{2, 1}).
+		Return(map[uint64]model.LikeStats{2: {PhotoID: 2, LikeCount: 5}, 1: {PhotoID: 1, LikeCount: 3}}, nil)
+
+	svc := photoServiceImpl{photoRepository: repoMock, likeRepository: likeMock, cfg: config.PhotoConfig{PopularWindow: 7 * 24 * time.Hour}}
+	page, err := svc.GetPopularPhotos(context.Background(), 7, "", nil, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Photo{{ID: 2, CreatedAt: createdAt, LikeCount: 5}}, page.Data)
+
+	// the next page continues below the like count of the last photo
+	cursor, err := pkg.DecodeCursor(page.NextCursor)
+	assert.Nil(t, err)
+	assert.Equal(t, pkg.Cursor{Score: 5, CreatedAt: createdAt, ID: 2}, cursor)
+}
+
+func TestGetPhotosByUserID(t *testing.T) {
+	t.Run("error unknown user", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(4)).Return(model.User{}, nil)
+
+		svc := photoServiceImpl{userRepository: userMock}
+		_, err := svc.GetPhotosByUserID(context.Background(), 7, 4, nil, 20)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("success user without photos", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(4)).Return(model.User{ID: 4}, nil)
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotosByUserID", context.Background(), uint64(7), uint64(4), (*pkg.Cursor)(nil), 21).Return([]model.Photo{}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, userRepository: userMock}
+		page, err := svc.GetPhotosByUserID(context.Background(), 7, 4, nil, 20)
+		assert.Nil(t, err)
+		assert.NotNil(t, page.Data)
+		assert.Empty(t, page.Data)
+	})
+
+	t.Run("success photos with like counts", func(t *testing.T) {
+		userMock := mocks.NewUserQuery(t)
+		userMock.On("GetUsersByID", context.Background(), uint64(4)).Return(model.User{ID: 4}, nil)
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotosByUserID", context.Background(), uint64(7), uint64(4), (*pkg.Cursor)(nil), 21).
+			Return([]model.Photo{{ID: 1, UserID: 4}}, nil)
+		likeMock := mocks.NewLikeRepository(t)
+		likeMock.On("GetLikeStats", context.Background(), uint64(7), []uint64{1}).
+			Return(map[uint64]model.LikeStats{1: {PhotoID: 1, LikeCount: 3}}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, userRepository: userMock, likeRepository: likeMock}
+		page, err := svc.GetPhotosByUserID(context.Background(), 7, 4, nil, 20)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.Photo{{ID: 1, UserID: 4, LikeCount: 3}}, page.Data)
+	})
+}
+
+func TestGetPhotoByIDVisibility(t *testing.T) {
+	yes, no := true, false
+	testCases := []struct {
+		desc       string
+		viewerID   uint64
+		visibility string
+		following  *bool
+		err        error
+	}{
+		{desc: "success public photo of another user", viewerID: 1, visibility: model.VisibilityPublic},
+		{desc: "success private photo of the owner", viewerID: 2, visibility: model.VisibilityPrivate},
+		{desc: "success followers photo of the owner", viewerID: 2, visibility: model.VisibilityFollowers},
+		{desc: "error private photo of another user is not found", viewerID: 1, visibility: model.VisibilityPrivate, err: ErrPhotoNotFound},
+		{desc: "success followers photo of a followed user", viewerID: 1, visibility: model.VisibilityFollowers, following: &yes},
+		{desc: "error followers photo of another user", viewerID: 1, visibility: model.VisibilityFollowers, following: &no, err: ErrPhotoNotVisible},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			repoMock := mocks.NewPhotoRepository(t)
+			repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: tC.visibility}, nil)
+			likeMock := mocks.NewLikeRepository(t)
+			if tC.err == nil {
+				likeMock.On("GetLikeStats", context.Background(), tC.viewerID, []uint64{10}).Return(map[uint64]model.LikeStats{}, nil)
+			}
+			followMock := mocks.NewFollowRepository(t)
+			if tC.following != nil {
+				followMock.On("IsFollowing", context.Background(), tC.viewerID, uint64(2)).Return(*tC.following, nil)
+			}
+
+			svc := photoServiceImpl{photoRepository: repoMock, likeRepository: likeMock, followRepository: followMock}
+			photo, err := svc.GetPhotoByID(context.Background(), tC.viewerID, 10)
+			if tC.err != nil {
+				assert.ErrorIs(t, err, tC.err)
+				assert.Equal(t, model.Photo{}, photo)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, uint64(10), photo.ID)
+			}
+		})
+	}
+}
+
+func TestLikePhoto(t *testing.T) {
+	t.Run("error photo not found", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{}, gorm.ErrRecordNotFound)
+
+		svc := photoServiceImpl{photoRepository: repoMock}
+		err := svc.LikePhoto(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("success like photo of another user", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2}, nil)
+		likeMock := mocks.NewLikeRepository(t)
+		likeMock.On("CreateLike", context.Background(), model.Like{UserID: 1, PhotoID: 10}).Return(nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, likeRepository: likeMock}
+		err := svc.LikePhoto(context.Background(), 1, 10)
+		assert.Nil(t, err)
+	})
+
+	t.Run("error private photo of another user", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityPrivate}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock}
+		err := svc.LikePhoto(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+}
+
+func TestUnlikePhoto(t *testing.T) {
+	t.Run("error private photo of another user", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityPrivate}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock}
+		err := svc.UnlikePhoto(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("error followers only photo of a user not followed", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityFollowers}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("IsFollowing", context.Background(), uint64(1), uint64(2)).Return(false, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, followRepository: followMock}
+		err := svc.UnlikePhoto(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrPhotoNotVisible)
+	})
+}
+
+func TestGetPhotoLikers(t *testing.T) {
+	t.Run("error private photo of another user", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityPrivate}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock}
+		_, err := svc.GetPhotoLikers(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrPhotoNotFound)
+	})
+
+	t.Run("error followers only photo of a user not followed", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityFollowers}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("IsFollowing", context.Background(), uint64(1), uint64(2)).Return(false, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, followRepository: followMock}
+		_, err := svc.GetPhotoLikers(context.Background(), 1, 10)
+		assert.ErrorIs(t, err, ErrPhotoNotVisible)
+	})
+
+	t.Run("success followers only photo of a followed user", func(t *testing.T) {
+		repoMock := mocks.NewPhotoRepository(t)
+		repoMock.On("GetPhotoByID", context.Background(), uint64(10)).Return(model.Photo{ID: 10, UserID: 2, Visibility: model.VisibilityFollowers}, nil)
+		followMock := mocks.NewFollowRepository(t)
+		followMock.On("IsFollowing", context.Background(), uint64(1), uint64(2)).Return(true, nil)
+		likeMock := mocks.NewLikeRepository(t)
+		likeMock.On("GetLikers", context.Background(), uint64(10)).Return([]model.User{{ID: 3}}, nil)
+
+		svc := photoServiceImpl{photoRepository: repoMock, followRepository: followMock, likeRepository: likeMock}
+		users, err := svc.GetPhotoLikers(context.Background(), 1, 10)
+		assert.Nil(t, err)
+		assert.Len(t, users, 1)
+	})
+}
+
+func TestUploadPhotoImage(t *testing.T) {
+	t.Run("success store png under the user prefix", func(t *testing.T) {
+		files := storage.NewFake()
+		svc := photoServiceImpl{images: files}
+
+		url, err := svc.UploadPhotoImage(context.Background(), 7, strings.NewReader("png"), "image/png")
+		assert.Nil(t, err)
+		keys := files.Keys()
+		if assert.Len(t, keys, 1) {
+			assert.True(t, strings.HasPrefix(keys[0], "photos/7-"))
+			assert.True(t, strings.HasSuffix(keys[0], ".png"))
+			assert.Equal(t, "fake://"+keys[0], url)
+		}
+	})
+
+	t.Run("error unsupported type", func(t *testing.T) {
+		files := storage.NewFake()
+		svc := photoServiceImpl{images: files}
+
+		_, err := svc.UploadPhotoImage(context.Background(), 7, strings.NewReader("gif"), "image/gif")
+		assert.ErrorIs(t, err, ErrUnsupportedImageType)
+		assert.Empty(t, files.Keys())
+	})
+
+	t.Run("error storage failure", func(t *testing.T) {
+		files := storage.NewFake()
+		files.PutErr = errors.New("disk full")
+		svc := photoServiceImpl{images: files}
+
+		_, err := svc.UploadPhotoImage(context.Background(), 7, strings.NewReader("png"), "image/png")
+		assert.EqualError(t, err, "disk full")
+	})
+}
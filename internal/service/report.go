@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository"
+	"go-mygram/pkg"
+
+	"gorm.io/gorm"
+)
+
+type ReportService interface {
+	ReportPhoto(ctx context.Context, reporterID uint64, photoID uint64, reportPost model.ReportPost) error
+	ReportComment(ctx context.Context, reporterID uint64, commentID uint64, reportPost model.ReportPost) error
+	GetReportedContent(ctx context.Context, pagination pkg.Pagination) ([]model.ReportedContent, int64, error)
+}
+
+type reportServiceImpl struct {
+	reportRepository  repository.ReportRepository
+	commentRepository repository.CommentRepository
+	photoService      PhotoService
+}
+
+func NewReportService(reportRepository repository.ReportRepository, commentRepository repository.CommentRepository, photoService PhotoService) ReportService {
+	return &reportServiceImpl{
+		reportRepository:  reportRepository,
+		commentRepository: commentRepository,
+		photoService:      photoService,
+	}
+}
+
+// ReportPhoto is idempotent, a user only reports a photo they can see
+func (s *reportServiceImpl) ReportPhoto(ctx context.Context, reporterID uint64, photoID uint64, reportPost model.ReportPost) error {
+	if _, err := s.photoService.GetPhotoByID(ctx, reporterID, photoID); err != nil {
+		return err
+	}
+	return s.createReport(ctx, reporterID, model.ReportTargetPhoto, photoID, reportPost)
+}
+
+// ReportComment is idempotent, the comment of a photo the user cannot see
+// is reported as not found
+func (s *reportServiceImpl) ReportComment(ctx context.Context, reporterID uint64, commentID uint64, reportPost model.ReportPost) error {
+	comment, err := s.commentRepository.GetCommentByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+	if _, err := s.photoService.GetPhotoByID(ctx, reporterID, comment.PhotoID); err != nil {
+		if errors.Is(err, ErrPhotoNotFound) || errors.Is(err, ErrPhotoNotVisible) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+	return s.createReport(ctx, reporterID, model.ReportTargetComment, commentID, reportPost)
+}
+
+func (s *reportServiceImpl) createReport(ctx context.Context, reporterID uint64, targetType string, targetID uint64, reportPost model.ReportPost) error {
+	return s.reportRepository.CreateReport(ctx, model.Report{
+		ReporterID: reporterID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     strings.TrimSpace(reportPost.Reason),
+	})
+}
+
+func (s *reportServiceImpl) GetReportedContent(ctx context.Context, pagination pkg.Pagination) ([]model.ReportedContent, int64, error) {
+	return s.reportRepository.GetReportedContent(ctx, pagination)
+}
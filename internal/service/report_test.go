@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository/mocks"
+	svcmocks "go-mygram/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestReportPhoto(t *testing.T) {
+	t.Run("error photo not visible", func(t *testing.T) {
+		photoMock := svcmocks.NewPhotoService(t)
+		photoMock.On("GetPhotoByID", context.Background(), uint64(1), uint64(2)).Return(model.Photo{}, ErrPhotoNotVisible)
+
+		svc := reportServiceImpl{photoService: photoMock}
+		err := svc.ReportPhoto(context.Background(), 1, 2, model.ReportPost{Reason: "spam"})
+		assert.ErrorIs(t, err, ErrPhotoNotVisible)
+	})
+
+	t.Run("success report photo", func(t *testing.T) {
+		photoMock := svcmocks.NewPhotoService(t)
+		photoMock.On("GetPhotoByID", context.Background(), uint64(1), uint64(2)).Return(model.Photo{ID: 2}, nil)
+		reportMock := mocks.NewReportRepository(t)
+		reportMock.On("CreateReport", context.Background(), model.Report{ReporterID: 1, TargetType: model.ReportTargetPhoto, TargetID: 2, Reason: "spam"}).Return(nil)
+
+		svc := reportServiceImpl{reportRepository: reportMock, photoService: photoMock}
+		err := svc.ReportPhoto(context.Background(), 1, 2, model.ReportPost{Reason: "  spam "})
+		assert.Nil(t, err)
+	})
+}
+
+func TestReportComment(t *testing.T) {
+	t.Run("error comment not found", func(t *testing.T) {
+		commentMock := mocks.NewCommentRepository(t)
+		commentMock.On("GetCommentByID", context.Background(), uint64(3)).Return(model.Comment{}, gorm.ErrRecordNotFound)
+
+		svc := reportServiceImpl{commentRepository: commentMock}
+		err := svc.ReportComment(context.Background(), 1, 3, model.ReportPost{Reason: "rude"})
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+
+	t.Run("error photo of the comment hidden", func(t *testing.T) {
+		commentMock := mocks.NewCommentRepository(t)
+		commentMock.On("GetCommentByID", context.Background(), uint64(3)).Return(model.Comment{ID: 3, PhotoID: 2}, nil)
+		photoMock := svcmocks.NewPhotoService(t)
+		photoMock.On("GetPhotoByID", context.Background(), uint64(1), uint64(2)).Return(model.Photo{}, ErrPhotoNotFound)
+
+		svc := reportServiceImpl{commentRepository: commentMock, photoService: photoMock}
+		err := svc.ReportComment(context.Background(), 1, 3, model.ReportPost{Reason: "rude"})
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+
+	t.Run("success report comment", func(t *testing.T) {
+		commentMock := mocks.NewCommentRepository(t)
+		commentMock.On("GetCommentByID", context.Background(), uint64(3)).Return(model.Comment{ID: 3, PhotoID: 2}, nil)
+		photoMock := svcmocks.NewPhotoService(t)
+		photoMock.On("GetPhotoByID", context.Background(), uint64(1), uint64(2)).Return(model.Photo{ID: 2}, nil)
+		reportMock := mocks.NewReportRepository(t)
+		reportMock.On("CreateReport", context.Background(), model.Report{ReporterID: 1, TargetType: model.ReportTargetComment, TargetID: 3, Reason: "rude"}).Return(nil)
+
+		svc := reportServiceImpl{reportRepository: reportMock, commentRepository: commentMock, photoService: photoMock}
+		err := svc.ReportComment(context.Background(), 1, 3, model.ReportPost{Reason: "rude"})
+		assert.Nil(t, err)
+	})
+}
@@ -2,18 +2,28 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"go-mygram/internal/model"
 	"go-mygram/internal/repository"
-	"time"
+
+	"gorm.io/gorm"
 )
 
 type SocialMediaService interface {
 	CreateSocialMedia(ctx context.Context, userID uint64, socialMediaPost model.SocialMediaPost) (model.SocialMedia, error)
 	GetSocialMediaByID(ctx context.Context, id uint64) (model.SocialMedia, error)
-	UpdateSocialMedia(ctx context.Context, id uint64, updatedSocialMedia model.SocialMediaPost) (model.SocialMedia, error)
-	DeleteSocialMediaByID(ctx context.Context, id uint64) error
+	GetSocialMedias(ctx context.Context, userID uint64, includeUser bool) ([]model.SocialMedia, error)
+	UpdateSocialMedia(ctx context.Context, userID uint64, id uint64, updatedSocialMedia model.SocialMediaPost) (model.SocialMedia, error)
+	DeleteSocialMedia(ctx context.Context, userID uint64, id uint64) error
 }
 
+var (
+	ErrSocialMediaNotFound = errors.New("social media not found")
+	ErrSocialMediaNotOwner = errors.New("social media does not belong to user")
+)
+
 type socialMediaServiceImpl struct {
 	socialMediaRepository repository.SocialMediaRepository
 }
@@ -37,11 +47,34 @@ func (s *socialMediaServiceImpl) CreateSocialMedia(ctx context.Context, userID u
 }
 
 func (s *socialMediaServiceImpl) GetSocialMediaByID(ctx context.Context, id uint64) (model.SocialMedia, error) {
-	return s.socialMediaRepository.GetSocialMediaByID(ctx, id)
+	socialMedia, err := s.socialMediaRepository.GetSocialMediaByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.SocialMedia{}, ErrSocialMediaNotFound
+		}
+		return model.SocialMedia{}, err
+	}
+	return socialMedia, nil
 }
 
-func (s *socialMediaServiceImpl) UpdateSocialMedia(ctx context.Context, id uint64, updatedSocialMedia model.SocialMediaPost) (model.SocialMedia, error) {
-	existingSocialMedia, err := s.socialMediaRepository.GetSocialMediaByID(ctx, id)
+func (s *socialMediaServiceImpl) GetSocialMedias(ctx context.Context, userID uint64, includeUser bool) ([]model.SocialMedia, error) {
+	return s.socialMediaRepository.GetSocialMediasByUserID(ctx, userID, includeUser)
+}
+
+// getOwnedSocialMedia loads a social media entry and makes sure it belongs to userID
+func (s *socialMediaServiceImpl) getOwnedSocialMedia(ctx context.Context, userID uint64, id uint64) (model.SocialMedia, error) {
+	socialMedia, err := s.GetSocialMediaByID(ctx, id)
+	if err != nil {
+		return model.SocialMedia{}, err
+	}
+	if socialMedia.UserID != userID {
+		return model.SocialMedia{}, ErrSocialMediaNotOwner
+	}
+	return socialMedia, nil
+}
+
+func (s *socialMediaServiceImpl) UpdateSocialMedia(ctx context.Context, userID uint64, id uint64, updatedSocialMedia model.SocialMediaPost) (model.SocialMedia, error) {
+	existingSocialMedia, err := s.getOwnedSocialMedia(ctx, userID, id)
 	if err != nil {
 		return model.SocialMedia{}, err
 	}
@@ -53,6 +86,9 @@ func (s *socialMediaServiceImpl) UpdateSocialMedia(ctx context.Context, id uint6
 	return s.socialMediaRepository.UpdateSocialMedia(ctx, existingSocialMedia)
 }
 
-func (s *socialMediaServiceImpl) DeleteSocialMediaByID(ctx context.Context, id uint64) error {
+func (s *socialMediaServiceImpl) DeleteSocialMedia(ctx context.Context, userID uint64, id uint64) error {
+	if _, err := s.getOwnedSocialMedia(ctx, userID, id); err != nil {
+		return err
+	}
 	return s.socialMediaRepository.DeleteSocialMediaByID(ctx, id)
 }
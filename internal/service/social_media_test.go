@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go-mygram/internal/model"
+	"go-mygram/internal/repository/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteSocialMedia(t *testing.T) {
+	t.Run("error social media owned by other user", func(t *testing.T) {
+		repoMock := mocks.NewSocialMediaRepository(t)
+		repoMock.On("GetSocialMediaByID", context.Background(), uint64(3)).Return(model.SocialMedia{ID: 3, UserID: 2}, nil)
+
+		svc := socialMediaServiceImpl{socialMediaRepository: repoMock}
+		err := svc.DeleteSocialMedia(context.Background(), 1, 3)
+		assert.ErrorIs(t, err, ErrSocialMediaNotOwner)
+	})
+
+	t.Run("success delete own social media", func(t *testing.T) {
+		repoMock := mocks.NewSocialMediaRepository(t)
+		repoMock.On("GetSocialMediaByID", context.Background(), uint64(3)).Return(model.SocialMedia{ID: 3, UserID: 1}, nil)
+		repoMock.On("DeleteSocialMediaByID", context.Background(), uint64(3)).Return(nil)
+
+		svc := socialMediaServiceImpl{socialMediaRepository: repoMock}
+		err := svc.DeleteSocialMedia(context.Background(), 1, 3)
+		assert.Nil(t, err)
+	})
+}
@@ -1,46 +1,173 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"go-mygram/internal/config"
 	"go-mygram/internal/model"
 	"go-mygram/internal/repository"
+	"go-mygram/pkg"
+	"go-mygram/pkg/cache"
 	"go-mygram/pkg/helper"
+	"go-mygram/pkg/mailer"
+	"go-mygram/pkg/storage"
+	"go-mygram/pkg/tokenstore"
+	"go-mygram/pkg/webhook"
 
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
-	GetUsers(ctx context.Context) ([]model.User, error)
+	GetUsers(ctx context.Context, params model.UserListParams) ([]model.User, int64, error)
+	GetUserCount(ctx context.Context, params model.UserCountParams) (int64, error)
 	GetUsersById(ctx context.Context, id uint64) (model.User, error)
+	GetUserByIDOrUsername(ctx context.Context, key string) (model.User, error)
+	GetUserStats(ctx context.Context, viewerID, id uint64) (model.UserStats, error)
+	GetUsersByIDs(ctx context.Context, ids []uint64) ([]model.User, error)
 	UpdateUserByID(ctx context.Context, id uint64, updateUser model.UserUpdate) (model.User, error)
+	ReplaceUser(ctx context.Context, id uint64, replaceUser model.UserReplace) (model.User, error)
 	DeleteUsersById(ctx context.Context, id uint64) (model.User, error)
+	HardDeleteUser(ctx context.Context, id uint64) error
+	IsUsernameAvailable(ctx context.Context, username string) (bool, error)
+	SearchUsernames(ctx context.Context, prefix string, limit int) ([]model.MentionedUser, error)
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int, error)
+	UpdateAvatar(ctx context.Context, id uint64, file io.Reader, contentType string) (model.User, error)
 
 	// activity
 	SignUp(ctx context.Context, userSignUp model.UserSignUp) (model.User, error)
+	SignUpWithTokens(ctx context.Context, userSignUp model.UserSignUp) (model.User, model.TokenPair, error)
 	SignIn(ctx context.Context, userSignIn model.UserSignIn) (model.User, error)
+	SignOut(ctx context.Context, jti string, expiresAt time.Time) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+	ChangePassword(ctx context.Context, id uint64, currentPassword, newPassword, refreshToken string) error
 
 	// misc
-	GenerateUserAccessToken(ctx context.Context, user model.User) (token string, err error)
+	GenerateUserAccessToken(ctx context.Context, user model.User) (model.AccessToken, error)
+	GenerateRefreshToken(ctx context.Context, user model.User) (token string, err error)
+	RefreshAccessToken(ctx context.Context, refreshToken string) (model.AccessToken, error)
 }
 
+// the sentinels carry a pkg error kind, so pkg.HTTPStatusFromError knows
+// how to report them without a case per error
+var (
+	ErrUserNotFound = pkg.NewError(pkg.ErrNotFound, "user not found")
+
+	ErrEmailAlreadyExists    = pkg.NewError(pkg.ErrConflict, "email already registered")
+	ErrUsernameAlreadyExists = pkg.NewError(pkg.ErrConflict, "username already taken")
+	ErrUserModified          = pkg.NewError(pkg.ErrConflict, "user was modified since it was fetched")
+
+	ErrInvalidRefreshToken = pkg.NewError(pkg.ErrUnauthorized, "invalid refresh token")
+	ErrRefreshTokenExpired = pkg.NewError(pkg.ErrUnauthorized, "refresh token expired")
+	ErrRefreshTokenRevoked = pkg.NewError(pkg.ErrUnauthorized, "refresh token revoked")
+
+	// the verification and reset tokens arrive as input, unlike the
+	// refresh token they don't authenticate the request
+	ErrInvalidVerificationToken = pkg.NewError(pkg.ErrValidation, "invalid verification token")
+	ErrVerificationTokenExpired = pkg.NewError(pkg.ErrValidation, "verification token expired")
+	ErrEmailNotVerified         = errors.New("verify your email before doing this")
+
+	ErrInvalidResetToken = pkg.NewError(pkg.ErrValidation, "invalid password reset token")
+	ErrResetTokenExpired = pkg.NewError(pkg.ErrValidation, "password reset token expired")
+
+	ErrInvalidCredentials = pkg.NewError(pkg.ErrUnauthorized, "invalid email or password")
+	// a 423, none of the kinds fit
+	ErrAccountLocked = errors.New("account is locked after too many failed sign ins, try again later")
+
+	ErrWrongCurrentPassword = pkg.NewError(pkg.ErrValidation, "current password is incorrect")
+	ErrPasswordUnchanged    = pkg.NewError(pkg.ErrValidation, "new password must be different from the current one")
+)
+
 type userServiceImpl struct {
-	repo repository.UserQuery
+	repo        repository.UserQuery
+	follows     repository.FollowRepository
+	tx          repository.Transactor
+	tokenRepo   repository.RefreshTokenRepository
+	tokenStore  tokenstore.Store
+	tokenCfg    config.TokenConfig
+	passwordCfg config.PasswordConfig
+	signInCfg   config.SignInConfig
+	jwt         helper.JWTManager
+	avatars     storage.Storage
+	verifyRepo  repository.EmailVerificationRepository
+	resetRepo   repository.PasswordResetRepository
+	emails      mailer.EmailSender
+	emailCfg    config.EmailConfig
+	listCache   cache.Cache
+	cacheCfg    config.CacheConfig
+	counts      *countCache
+	hooks       webhook.Webhook
 }
 
-func NewUserService(repo repository.UserQuery) UserService {
-	return &userServiceImpl{repo: repo}
+func NewUserService(repo repository.UserQuery, follows repository.FollowRepository, tx repository.Transactor, tokenRepo repository.RefreshTokenRepository, tokenStore tokenstore.Store, tokenCfg config.TokenConfig, passwordCfg config.PasswordConfig, signInCfg config.SignInConfig, jwt helper.JWTManager, avatars storage.Storage, verifyRepo repository.EmailVerificationRepository, resetRepo repository.PasswordResetRepository, emails mailer.EmailSender, emailCfg config.EmailConfig, listCache cache.Cache, cacheCfg config.CacheConfig, hooks webhook.Webhook) UserService {
+	return &userServiceImpl{
+		repo:        repo,
+		follows:     follows,
+		tx:          tx,
+		tokenRepo:   tokenRepo,
+		tokenStore:  tokenStore,
+		tokenCfg:    tokenCfg,
+		passwordCfg: passwordCfg,
+		signInCfg:   signInCfg,
+		jwt:         jwt,
+		avatars:     avatars,
+		verifyRepo:  verifyRepo,
+		resetRepo:   resetRepo,
+		emails:      emails,
+		emailCfg:    emailCfg,
+		listCache:   listCache,
+		cacheCfg:    cacheCfg,
+		counts:      newCountCache(userCountTTL),
+		hooks:       hooks,
+	}
 }
 
-func (u *userServiceImpl) GetUsers(ctx context.Context) ([]model.User, error) {
-	users, err := u.repo.GetUsers(ctx)
+// GetUsers serves the list from the cache when it is enabled. Writes through
+// this service drop the cached lists, sign ins and follows don't, so
+// last_login_at and the follow counts may be up to UserListTTL old
+func (u *userServiceImpl) GetUsers(ctx context.Context, params model.UserListParams) ([]model.User, int64, error) {
+	key := userListCacheKey(params)
+	if page, ok := u.cachedUserList(ctx, key); ok {
+		return page.Users, page.Total, nil
+	}
+
+	users, total, err := u.repo.GetUsers(ctx, params)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return users, err
+	if err := fillFollowCounts(ctx, u.follows, users); err != nil {
+		return nil, 0, err
+	}
+	u.cacheUserList(ctx, key, cachedUserPage{Users: users, Total: total})
+	return users, total, err
+}
+
+// GetUserCount counts the users matching params with a single COUNT, the
+// result is reused for userCountTTL so a dashboard polling it stays cheap
+func (u *userServiceImpl) GetUserCount(ctx context.Context, params model.UserCountParams) (int64, error) {
+	key := userCountKey(params)
+	if count, ok := u.counts.get(key); ok {
+		return count, nil
+	}
+	count, err := u.repo.CountUsers(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	u.counts.set(key, count)
+	return count, nil
 }
 
 func (u *userServiceImpl) GetUsersById(ctx context.Context, id uint64) (model.User, error) {
@@ -48,7 +175,71 @@ func (u *userServiceImpl) GetUsersById(ctx context.Context, id uint64) (model.Us
 	if err != nil {
 		return model.User{}, err
 	}
-	return user, err
+	if user.ID == 0 {
+		return model.User{}, ErrUserNotFound
+	}
+	return u.withFollowCounts(ctx, user)
+}
+
+// withFollowCounts fills the follow counts of a found user
+func (u *userServiceImpl) withFollowCounts(ctx context.Context, user model.User) (model.User, error) {
+	users := []model.User{user}
+	if err := fillFollowCounts(ctx, u.follows, users); err != nil {
+		return model.User{}, err
+	}
+	return users[0], nil
+}
+
+// GetUserByIDOrUsername resolves key as an id first, usernames may be all
+// digits so a numeric key that matches no id is tried as a username too.
+// An empty user means neither matched
+func (u *userServiceImpl) GetUserByIDOrUsername(ctx context.Context, key string) (model.User, error) {
+	if id, err := strconv.ParseUint(key, 10, 64); err == nil && id > 0 {
+		user, err := u.repo.GetUsersByID(ctx, id)
+		if err != nil {
+			return model.User{}, err
+		}
+		if user.ID != 0 {
+			return u.withFollowCounts(ctx, user)
+		}
+	}
+	user, err := u.repo.GetByUsername(ctx, key)
+	if err != nil {
+		return model.User{}, err
+	}
+	if user.ID == 0 {
+		return model.User{}, ErrUserNotFound
+	}
+	return u.withFollowCounts(ctx, user)
+}
+
+// GetUserStats returns the profile counts of user id as seen by viewerID
+func (u *userServiceImpl) GetUserStats(ctx context.Context, viewerID, id uint64) (model.UserStats, error) {
+	return u.repo.GetUserStats(ctx, viewerID, id)
+}
+
+// GetUsersByIDs returns the users in the order of ids, unknown and
+// repeated ids are skipped
+func (u *userServiceImpl) GetUsersByIDs(ctx context.Context, ids []uint64) ([]model.User, error) {
+	found, err := u.repo.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]model.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+	users := make([]model.User, 0, len(found))
+	for _, id := range ids {
+		if user, ok := byID[id]; ok {
+			users = append(users, user)
+			delete(byID, id)
+		}
+	}
+	if err := fillFollowCounts(ctx, u.follows, users); err != nil {
+		return nil, err
+	}
+	return users, nil
 }
 
 func (u *userServiceImpl) UpdateUserByID(ctx context.Context, id uint64, updateUser model.UserUpdate) (model.User, error) {
@@ -59,30 +250,135 @@ func (u *userServiceImpl) UpdateUserByID(ctx context.Context, id uint64, updateU
 	}
 	// If user doesn't exist, return error
 	if user.ID == 0 {
-		return model.User{}, errors.New("user not found")
+		return model.User{}, ErrUserNotFound
 	}
 
-	// Update user fields
-	user.Username = updateUser.Username
-	user.Email = updateUser.Email
+	// without a client version the update still guards against writes
+	// landing between the read above and the save below
+	version := user.Version
+	if updateUser.Version != nil {
+		if *updateUser.Version != user.Version {
+			return model.User{}, ErrUserModified
+		}
+		version = *updateUser.Version
+	}
+
+	// Update only the provided fields
+	if updateUser.Username != nil {
+		user.Username = *updateUser.Username
+	}
+	// an address the account never proved it owns must be verified again
+	emailChanged := false
+	if updateUser.Email != nil {
+		email := normalizeEmail(*updateUser.Email)
+		if email != user.Email {
+			emailChanged = true
+			user.EmailVerified = false
+		}
+		user.Email = email
+	}
+	// both are shown on the profile, markup is stripped like in captions
+	if updateUser.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(pkg.StripHTML(*updateUser.DisplayName))
+	}
+	if updateUser.Bio != nil {
+		user.Bio = strings.TrimSpace(pkg.StripHTML(*updateUser.Bio))
+	}
 
 	// Save updated user
-	updatedUser, err := u.repo.UpdateUser(ctx, user)
+	var (
+		updatedUser model.User
+		verifyToken string
+	)
+	save := func(ctx context.Context) error {
+		var err error
+		updatedUser, err = u.repo.UpdateUserIfVersion(ctx, user, version)
+		if err != nil || !emailChanged {
+			return err
+		}
+		// a link mailed to the old address must not verify the new one
+		if err := u.verifyRepo.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+		verifyToken, err = u.createVerificationToken(ctx, user.ID)
+		return err
+	}
+	if emailChanged {
+		err = u.tx.WithTx(ctx, save)
+	} else {
+		err = save(ctx)
+	}
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return model.User{}, ErrUserModified
+	}
 	if err != nil {
-		return model.User{}, err
+		return model.User{}, translateDuplicateError(err)
 	}
 
+	u.invalidateUsers(ctx)
+	if emailChanged {
+		// the account stays usable unverified, a lost email is not fatal
+		if err := u.sendVerificationEmail(ctx, updatedUser, verifyToken); err != nil {
+			log.Printf("failed to send verification email to user %d: %v", updatedUser.ID, err)
+		}
+	}
 	return updatedUser, nil
 }
 
+// ReplaceUser overwrites every editable field of the user, unlike
+// UpdateUserByID which only touches the ones given
+func (u *userServiceImpl) ReplaceUser(ctx context.Context, id uint64, replaceUser model.UserReplace) (model.User, error) {
+	return u.UpdateUserByID(ctx, id, model.UserUpdate{
+		Email:       &replaceUser.Email,
+		Username:    &replaceUser.Username,
+		DisplayName: &replaceUser.DisplayName,
+		Bio:         &replaceUser.Bio,
+		Version:     replaceUser.Version,
+	})
+}
+
+// UpdateAvatar stores file as the new avatar of the user and removes the
+// previous one
+func (u *userServiceImpl) UpdateAvatar(ctx context.Context, id uint64, file io.Reader, contentType string) (model.User, error) {
+	if _, ok := imageExtensions[contentType]; !ok {
+		return model.User{}, ErrUnsupportedImageType
+	}
+	user, err := u.repo.GetUsersByID(ctx, id)
+	if err != nil {
+		return model.User{}, err
+	}
+	if user.ID == 0 {
+		return model.User{}, ErrUserNotFound
+	}
+
+	key, url, err := putImage(ctx, u.avatars, fmt.Sprintf("avatars/%d", id), file, contentType)
+	if err != nil {
+		return model.User{}, err
+	}
+	if err := u.repo.UpdateAvatar(ctx, id, url); err != nil {
+		if delErr := u.avatars.Delete(ctx, key); delErr != nil {
+			log.Printf("failed to remove unused avatar %s: %v", key, delErr)
+		}
+		return model.User{}, err
+	}
+	u.invalidateUsers(ctx)
+
+	if oldKey, ok := u.avatars.KeyFromURL(user.AvatarURL); ok {
+		if err := u.avatars.Delete(ctx, oldKey); err != nil {
+			log.Printf("failed to remove old avatar %s: %v", oldKey, err)
+		}
+	}
+	user.AvatarURL = url
+	return user, nil
+}
+
 func (u *userServiceImpl) DeleteUsersById(ctx context.Context, id uint64) (model.User, error) {
 	user, err := u.repo.GetUsersByID(ctx, id)
 	if err != nil {
 		return model.User{}, err
 	}
-	// if user doesn't exist, return
 	if user.ID == 0 {
-		return model.User{}, nil
+		return model.User{}, ErrUserNotFound
 	}
 
 	// delete user by id
@@ -91,59 +387,395 @@ func (u *userServiceImpl) DeleteUsersById(ctx context.Context, id uint64) (model
 		return model.User{}, err
 	}
 
+	u.invalidateUsers(ctx)
 	return user, err
 }
 
+// HardDeleteUser permanently removes the user, soft deleted or not
+func (u *userServiceImpl) HardDeleteUser(ctx context.Context, id uint64) error {
+	if err := u.repo.HardDeleteUser(ctx, id); err != nil {
+		return err
+	}
+	u.invalidateUsers(ctx)
+	return nil
+}
+
+// PurgeDeletedUsers permanently removes users soft deleted before the given
+// time and returns how many were removed
+func (u *userServiceImpl) PurgeDeletedUsers(ctx context.Context, before time.Time) (int, error) {
+	ids, err := u.repo.GetDeletedUserIDs(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, id := range ids {
+		if err := u.repo.HardDeleteUser(ctx, id); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (u *userServiceImpl) IsUsernameAvailable(ctx context.Context, username string) (bool, error) {
+	if model.IsReservedUsername(username) {
+		return false, nil
+	}
+	taken, err := u.repo.ExistsByUsername(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	return !taken, nil
+}
+
+// SearchUsernames backs the @mention autocomplete, a leading @ is ignored
+func (u *userServiceImpl) SearchUsernames(ctx context.Context, prefix string, limit int) ([]model.MentionedUser, error) {
+	prefix = strings.TrimPrefix(strings.TrimSpace(prefix), "@")
+	if prefix == "" {
+		return []model.MentionedUser{}, nil
+	}
+	return u.repo.SearchUsernames(ctx, prefix, limit)
+}
+
 func (u *userServiceImpl) SignUp(ctx context.Context, userSignUp model.UserSignUp) (model.User, error) {
-	// assumption: semua user adalah user baru
+	email := normalizeEmail(userSignUp.Email)
+
 	user := model.User{
 		Username: userSignUp.Username,
-		Email:    userSignUp.Email,
+		Email:    email,
 		Age:      userSignUp.Age,
+		Role:     model.RoleUser,
 	}
 
-	pass, err := helper.GenerateHash(userSignUp.Password)
+	pass, err := helper.GenerateHashWithCost(userSignUp.Password, u.passwordCfg.BcryptCost)
 	if err != nil {
 		return model.User{}, err
 	}
 	user.Password = pass
 
+	// the unique indexes decide whether the email or username is taken, a
+	// lookup first would race with a concurrent sign up
 	res, err := u.repo.CreateUser(ctx, user)
 	if err != nil {
-		return model.User{}, err
+		return model.User{}, translateDuplicateError(err)
 	}
+	u.invalidateUsers(ctx)
 	return res, err
 }
 
+// SignUpWithTokens creates the account, signs it in and mails the email
+// verification link. Everything but the email runs in one transaction so a
+// failure while issuing the tokens doesn't leave an account behind that the
+// client never heard of
+func (u *userServiceImpl) SignUpWithTokens(ctx context.Context, userSignUp model.UserSignUp) (model.User, model.TokenPair, error) {
+	var (
+		user        model.User
+		tokens      model.TokenPair
+		verifyToken string
+	)
+	err := u.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = u.SignUp(ctx, userSignUp)
+		if err != nil {
+			return err
+		}
+		verifyToken, err = u.createVerificationToken(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		tokens.AccessToken, err = u.GenerateUserAccessToken(ctx, user)
+		if err != nil {
+			return err
+		}
+		tokens.RefreshToken, err = u.GenerateRefreshToken(ctx, user)
+		return err
+	})
+	if err != nil {
+		return model.User{}, model.TokenPair{}, err
+	}
+	// SignUp invalidated before the commit, a list read in between may have
+	// been cached without the new user
+	u.invalidateUsers(ctx)
+	// only here and not in SignUp, the seeder signs up through SignUp
+	if u.hooks != nil {
+		u.hooks.Dispatch(ctx, webhook.Event{Type: webhook.EventUserCreated, OccurredAt: time.Now(), Data: user.ToWebhook()})
+	}
+
+	// the account is usable without the email, a lost one is not fatal
+	if err := u.sendVerificationEmail(ctx, user, verifyToken); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+	}
+	return user, tokens, nil
+}
+
+// createVerificationToken stores the hash of a new token and returns the
+// raw token to mail
+func (u *userServiceImpl) createVerificationToken(ctx context.Context, userID uint64) (string, error) {
+	token, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	_, err = u.verifyRepo.CreateToken(ctx, model.EmailVerificationToken{
+		UserID:    userID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(u.emailCfg.VerificationTokenExpiry),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (u *userServiceImpl) sendVerificationEmail(ctx context.Context, user model.User, token string) error {
+	msg, err := mailer.VerifyEmailTemplate.Message(user.Email, mailer.LinkData{
+		Username: user.Username,
+		Link:     u.emailCfg.VerifyURL + "?token=" + url.QueryEscape(token),
+	})
+	if err != nil {
+		return err
+	}
+	return u.emails.Send(ctx, msg)
+}
+
+// VerifyEmail marks the owner of token as verified, the tokens of the user
+// are dropped so a link only works once
+func (u *userServiceImpl) VerifyEmail(ctx context.Context, token string) error {
+	stored, err := u.verifyRepo.FindByTokenHash(ctx, helper.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return ErrVerificationTokenExpired
+	}
+	err = u.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := u.repo.MarkEmailVerified(ctx, stored.UserID); err != nil {
+			return err
+		}
+		return u.verifyRepo.DeleteByUserID(ctx, stored.UserID)
+	})
+	if err != nil {
+		return err
+	}
+	u.invalidateUsers(ctx)
+	return nil
+}
+
+// RequestPasswordReset mails a reset link when email belongs to an account.
+// An unknown email is not an error, callers must not tell the two apart
+func (u *userServiceImpl) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := u.repo.FindByEmail(ctx, normalizeEmail(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := helper.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	_, err = u.resetRepo.CreateToken(ctx, model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(u.emailCfg.PasswordResetTokenExpiry),
+	})
+	if err != nil {
+		return err
+	}
+
+	msg, err := mailer.PasswordResetTemplate.Message(user.Email, mailer.LinkData{
+		Username: user.Username,
+		Link:     u.emailCfg.ResetURL + "?token=" + url.QueryEscape(token),
+	})
+	if err != nil {
+		return err
+	}
+	return u.emails.Send(ctx, msg)
+}
+
+// ResetPassword sets newPassword for the owner of token. The token is
+// consumed and every refresh token of the user revoked in the same
+// transaction as the password change, a password that fails validation
+// leaves it usable for another try
+func (u *userServiceImpl) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		stored, err := u.resetRepo.ConsumeToken(ctx, helper.HashToken(token))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		if err != nil {
+			return err
+		}
+		if time.Now().After(stored.ExpiresAt) {
+			return ErrResetTokenExpired
+		}
+
+		user, err := u.repo.GetUsersByID(ctx, stored.UserID)
+		if err != nil {
+			return err
+		}
+		if user.ID == 0 {
+			return ErrInvalidResetToken
+		}
+		if err := model.ValidatePassword(newPassword, user.Username, user.Email); err != nil {
+			return err
+		}
+
+		hash, err := helper.GenerateHashWithCost(newPassword, u.passwordCfg.BcryptCost)
+		if err != nil {
+			return err
+		}
+		if err := u.repo.UpdatePassword(ctx, user.ID, hash); err != nil {
+			return err
+		}
+		// whoever knew the old password must not stay signed in
+		if err := u.tokenRepo.RevokeUserRefreshTokens(ctx, user.ID, ""); err != nil {
+			return err
+		}
+		// older links of the same user die with this one
+		return u.resetRepo.DeleteByUserID(ctx, user.ID)
+	})
+}
+
+// ChangePassword replaces the password of user id after checking
+// currentPassword against the stored hash. Every session of the user but
+// the one of refreshToken is signed out in the same transaction
+func (u *userServiceImpl) ChangePassword(ctx context.Context, id uint64, currentPassword, newPassword, refreshToken string) error {
+	user, err := u.repo.GetUsersByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return ErrUserNotFound
+	}
+	if err := CompareHashAndPassword(user.Password, currentPassword); err != nil {
+		return ErrWrongCurrentPassword
+	}
+	if newPassword == currentPassword {
+		return ErrPasswordUnchanged
+	}
+	if err := model.ValidatePassword(newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	hash, err := helper.GenerateHashWithCost(newPassword, u.passwordCfg.BcryptCost)
+	if err != nil {
+		return err
+	}
+	var keepTokenHash string
+	if refreshToken != "" {
+		keepTokenHash = helper.HashToken(refreshToken)
+	}
+	return u.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := u.repo.UpdatePassword(ctx, user.ID, hash); err != nil {
+			return err
+		}
+		return u.tokenRepo.RevokeUserRefreshTokens(ctx, user.ID, keepTokenHash)
+	})
+}
+
 func (u *userServiceImpl) SignIn(ctx context.Context, userSignIn model.UserSignIn) (model.User, error) {
 	// Retrieve user by email
-	user, err := u.repo.FindByEmail(ctx, userSignIn.Email)
+	user, err := u.repo.FindByEmail(ctx, normalizeEmail(userSignIn.Email))
+	// an unknown email reads the same as a wrong password, and takes as long
+	// to answer
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_ = CompareHashAndPassword(dummyPasswordHash, userSignIn.Password)
+		return model.User{}, ErrInvalidCredentials
+	}
 	if err != nil {
 		return model.User{}, err
 	}
 
+	// a locked account is refused before the password is even checked, so
+	// guessing can't go on during the cooldown
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return model.User{}, ErrAccountLocked
+	}
+
 	// Verify password
 	if err := CompareHashAndPassword(user.Password, userSignIn.Password); err != nil {
-		return model.User{}, errors.New("invalid email or password")
+		if u.signInCfg.LockoutThreshold > 0 {
+			lockUntil := time.Now().Add(u.signInCfg.LockoutDuration)
+			if err := u.repo.RecordFailedLogin(ctx, user.ID, u.signInCfg.LockoutThreshold, lockUntil); err != nil {
+				log.Printf("failed to record failed sign in for user %d: %v", user.ID, err)
+			}
+		}
+		return model.User{}, ErrInvalidCredentials
+	}
+
+	// only bookkeeping, a failure must not block the sign in
+	now := time.Now()
+	if err := u.repo.RecordLogin(ctx, user.ID, now); err != nil {
+		log.Printf("failed to record sign in for user %d: %v", user.ID, err)
+	} else {
+		user.LastLoginAt = &now
+		user.FailedLoginAttempts = 0
+		user.LockedUntil = nil
+	}
+
+	// upgrade hashes made with an older, lower cost while we still have the
+	// plain password, a failure here must not block the sign in
+	if helper.NeedsRehash(user.Password, u.passwordCfg.BcryptCost) {
+		hash, err := helper.GenerateHashWithCost(userSignIn.Password, u.passwordCfg.BcryptCost)
+		if err == nil {
+			err = u.repo.UpdatePassword(ctx, user.ID, hash)
+		}
+		if err != nil {
+			log.Printf("failed to rehash password for user %d: %v", user.ID, err)
+		} else {
+			user.Password = hash
+		}
 	}
 
 	return user, nil
 }
 
+func (u *userServiceImpl) SignOut(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return errors.New("token has no jti")
+	}
+	return u.tokenStore.Revoke(ctx, jti, expiresAt)
+}
+
+// translateDuplicateError maps repository unique violations to service errors
+func translateDuplicateError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrDuplicateEmail):
+		return ErrEmailAlreadyExists
+	case errors.Is(err, repository.ErrDuplicateUsername):
+		return ErrUsernameAlreadyExists
+	}
+	return err
+}
+
+// normalizeEmail trims surrounding whitespace and lowercases the address so
+// lookups and stored values always agree
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// dummyPasswordHash is a bcrypt hash at the default cost that no password
+// is known for, SignIn checks an unknown email against it
+const dummyPasswordHash = "$2a$10$1pZ.uksSU5c3QVlm80yVJekdWs.QiG0Kfw8IIn6WqACTCrEdl/uhS"
+
 func CompareHashAndPassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
-func (u *userServiceImpl) GenerateUserAccessToken(ctx context.Context, user model.User) (token string, err error) {
+func (u *userServiceImpl) GenerateUserAccessToken(ctx context.Context, user model.User) (model.AccessToken, error) {
 	// generate claim
 	now := time.Now()
 
 	claim := model.StandardClaim{
+		// iss and aud are stamped by the jwt manager
 		Jti: fmt.Sprintf("%v", time.Now().UnixNano()),
-		Iss: "go-middleware",
-		Aud: "golang-006",
 		Sub: "access-token",
-		Exp: uint64(now.Add(time.Hour).Unix()),
+		Exp: uint64(now.Add(u.tokenCfg.AccessTokenExpiry).Unix()),
 		Iat: uint64(now.Unix()),
 		Nbf: uint64(now.Unix()),
 	}
@@ -152,8 +784,197 @@ func (u *userServiceImpl) GenerateUserAccessToken(ctx context.Context, user mode
 		StandardClaim: claim,
 		UserID:        user.ID,
 		Username:      user.Username,
+		Role:          user.Role,
+	}
+
+	token, err := u.jwt.GenerateToken(userClaim)
+	if err != nil {
+		return model.AccessToken{}, err
 	}
+	// derived from the signed claim rather than the config so they can't drift
+	return model.AccessToken{
+		Token:     token,
+		ExpiresIn: int64(claim.Exp - claim.Iat),
+		ExpiresAt: time.Unix(int64(claim.Exp), 0).UTC(),
+	}, nil
+}
 
-	token, err = helper.GenerateToken(userClaim)
-	return
+func (u *userServiceImpl) GenerateRefreshToken(ctx context.Context, user model.User) (token string, err error) {
+	token, err = helper.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	// only the hash is persisted, the raw token is handed to the client once
+	_, err = u.tokenRepo.CreateRefreshToken(ctx, model.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(u.tokenCfg.RefreshTokenExpiry),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (u *userServiceImpl) RefreshAccessToken(ctx context.Context, refreshToken string) (model.AccessToken, error) {
+	stored, err := u.tokenRepo.FindByTokenHash(ctx, helper.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.AccessToken{}, ErrInvalidRefreshToken
+		}
+		return model.AccessToken{}, err
+	}
+	if stored.RevokedAt != nil {
+		return model.AccessToken{}, ErrRefreshTokenRevoked
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return model.AccessToken{}, ErrRefreshTokenExpired
+	}
+
+	user, err := u.repo.GetUsersByID(ctx, stored.UserID)
+	if err != nil {
+		return model.AccessToken{}, err
+	}
+	// owner of the refresh token no longer exists
+	if user.ID == 0 {
+		return model.AccessToken{}, ErrInvalidRefreshToken
+	}
+
+	return u.GenerateUserAccessToken(ctx, user)
+}
+
+const userListCachePrefix = "users:list:"
+
+// cachedUserPage is what a cached GetUsers call holds, it is gob encoded as
+// the json tags of model.User hide columns the admin list shows
+type cachedUserPage struct {
+	Users []model.User
+	Total int64
+}
+
+func userListCacheKey(params model.UserListParams) string {
+	bound := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%s%d:%d:%s:%s:%s:%s:%q", userListCachePrefix, params.Offset(), params.Limit(), params.SortBy, params.Order,
+		bound(params.CreatedAfter), bound(params.CreatedBefore), params.Search)
+}
+
+// cachedUserList is a miss when the cache is off or fails, the list is then
+// read from the database as usual
+func (u *userServiceImpl) cachedUserList(ctx context.Context, key string) (cachedUserPage, bool) {
+	if !u.cacheCfg.Enabled || u.listCache == nil {
+		return cachedUserPage{}, false
+	}
+	raw, ok, err := u.listCache.Get(ctx, key)
+	if err != nil {
+		log.Printf("failed to read user list cache: %v", err)
+		return cachedUserPage{}, false
+	}
+	if !ok {
+		return cachedUserPage{}, false
+	}
+	var page cachedUserPage
+	if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&page); err != nil {
+		log.Printf("failed to decode cached user list: %v", err)
+		return cachedUserPage{}, false
+	}
+	return page, true
+}
+
+func (u *userServiceImpl) cacheUserList(ctx context.Context, key string, page cachedUserPage) {
+	if !u.cacheCfg.Enabled || u.listCache == nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(page); err != nil {
+		log.Printf("failed to encode user list: %v", err)
+		return
+	}
+	if err := u.listCache.Set(ctx, key, buf.Bytes(), u.cacheCfg.UserListTTL); err != nil {
+		log.Printf("failed to cache user list: %v", err)
+	}
+}
+
+// invalidateUsers drops the cached lists and counts after a user write
+func (u *userServiceImpl) invalidateUsers(ctx context.Context) {
+	u.counts.reset()
+	if !u.cacheCfg.Enabled || u.listCache == nil {
+		return
+	}
+	if err := u.listCache.DeletePrefix(ctx, userListCachePrefix); err != nil {
+		log.Printf("failed to invalidate user list cache: %v", err)
+	}
+}
+
+// userCountTTL is how stale GetUserCount may be
+const userCountTTL = 30 * time.Second
+
+func userCountKey(params model.UserCountParams) string {
+	bound := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return strings.ToLower(strings.TrimSpace(params.Search)) + "|" + bound(params.CreatedAfter) + "|" + bound(params.CreatedBefore)
+}
+
+type countEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// countCache keeps counts in memory for ttl, a nil cache never hits
+type countCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]countEntry
+}
+
+func newCountCache(ttl time.Duration) *countCache {
+	return &countCache{ttl: ttl, entries: map[string]countEntry{}}
+}
+
+func (c *countCache) get(key string) (int64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		delete(c.entries, key)
+		return 0, false
+	}
+	return e.count, true
+}
+
+func (c *countCache) reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+func (c *countCache) set(key string, count int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	// drop expired keys so arbitrary date filters can't grow the map forever
+	for k, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = countEntry{count: count, expiresAt: now.Add(c.ttl)}
 }
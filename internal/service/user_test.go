@@ -3,12 +3,29 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"go-mygram/internal/config"
 	"go-mygram/internal/model"
+	"go-mygram/internal/repository"
 	"go-mygram/internal/repository/mocks"
+	"go-mygram/pkg"
+	"go-mygram/pkg/cache"
+	"go-mygram/pkg/helper"
+	"go-mygram/pkg/mailer"
+	mailermocks "go-mygram/pkg/mailer/mocks"
+	"go-mygram/pkg/storage"
+	"go-mygram/pkg/webhook"
+	webhookmocks "go-mygram/pkg/webhook/mocks"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 func TestGetUsers(t *testing.T) {
@@ -19,25 +36,137 @@ func TestGetUsers(t *testing.T) {
 		svc := userServiceImpl{
 			repo: repoMock,
 		}
-		repoMock.On("GetUsers", context.Background()).Return([]model.User{}, errors.New("some error"))
+		params := model.UserListParams{Pagination: pkg.NewPagination(1, 20)}
+		repoMock.On("GetUsers", context.Background(), params).Return([]model.User{}, int64(0), errors.New("some error"))
 
 		// call method
-		usr, err := svc.GetUsers(context.Background())
+		usr, _, err := svc.GetUsers(context.Background(), params)
 		assert.NotNil(t, err)
 		assert.Equal(t, 0, len(usr))
 	})
 	t.Run("success call repo get users", func(t *testing.T) {
 		repoMock := mocks.NewUserQuery(t)
+		followMock := mocks.NewFollowRepository(t)
 
 		svc := userServiceImpl{
-			repo: repoMock,
+			repo:    repoMock,
+			follows: followMock,
 		}
-		repoMock.On("GetUsers", context.Background()).Return([]model.User{{ID: 1, Username: "user1"}}, nil)
+		params := model.UserListParams{Pagination: pkg.NewPagination(1, 20)}
+		repoMock.On("GetUsers", context.Background(), params).Return([]model.User{{ID: 1, Username: "user1"}}, int64(1), nil)
+		followMock.On("GetFollowCounts", context.Background(), []uint64{1}).Return(map[uint64]model.FollowCounts{1: {UserID: 1, FollowerCount: 2, FollowingCount: 5}}, nil)
 
 		// call method
-		usr, err := svc.GetUsers(context.Background())
+		usr, total, err := svc.GetUsers(context.Background(), params)
 		assert.Nil(t, err)
 		assert.Equal(t, 1, len(usr))
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, int64(2), usr[0].FollowerCount)
+		assert.Equal(t, int64(5), usr[0].FollowingCount)
+	})
+}
+
+func TestGetUsersCache(t *testing.T) {
+	lastLogin := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	params := model.UserListParams{Pagination: pkg.NewPagination(1, 20), SortBy: "id", Order: "asc"}
+
+	t.Run("success list is served from the cache", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		followMock := mocks.NewFollowRepository(t)
+		repoMock.On("GetUsers", context.Background(), params).Return([]model.User{{ID: 1, LastLoginAt: &lastLogin}}, int64(1), nil).Once()
+		followMock.On("GetFollowCounts", context.Background(), []uint64{1}).Return(map[uint64]model.FollowCounts{1: {UserID: 1, FollowerCount: 3}}, nil).Once()
+
+		svc := userServiceImpl{repo: repoMock, follows: followMock, listCache: cache.NewMemoryCache(), cacheCfg: config.CacheConfig{Enabled: true, UserListTTL: time.Minute}}
+		for i := 0; i < 2; i++ {
+			users, total, err := svc.GetUsers(context.Background(), params)
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), total)
+			assert.Equal(t, 1, len(users))
+			// columns hidden from json survive the cache
+			assert.Equal(t, lastLogin, *users[0].LastLoginAt)
+			assert.Equal(t, int64(3), users[0].FollowerCount)
+		}
+	})
+	t.Run("success other params miss the cache", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		other := params
+		other.Search = "bob"
+		repoMock.On("GetUsers", context.Background(), params).Return([]model.User{}, int64(0), nil).Once()
+		repoMock.On("GetUsers", context.Background(), other).Return([]model.User{}, int64(0), nil).Once()
+
+		svc := userServiceImpl{repo: repoMock, listCache: cache.NewMemoryCache(), cacheCfg: config.CacheConfig{Enabled: true, UserListTTL: time.Minute}}
+		for _, p := range []model.UserListParams{params, other, params, other} {
+			_, _, err := svc.GetUsers(context.Background(), p)
+			assert.Nil(t, err)
+		}
+	})
+	t.Run("success disabled cache always reads the database", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsers", context.Background(), params).Return([]model.User{}, int64(0), nil).Twice()
+
+		svc := userServiceImpl{repo: repoMock, listCache: cache.NewMemoryCache(), cacheCfg: config.CacheConfig{Enabled: false, UserListTTL: time.Minute}}
+		for i := 0; i < 2; i++ {
+			_, _, err := svc.GetUsers(context.Background(), params)
+			assert.Nil(t, err)
+		}
+	})
+	t.Run("success update drops the cached list", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		username := "renamed"
+		repoMock.On("GetUsers", context.Background(), params).Return([]model.User{}, int64(0), nil).Twice()
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(model.User{ID: 1, Username: "old", Version: 1}, nil)
+		repoMock.On("UpdateUserIfVersion", context.Background(), model.User{ID: 1, Username: username, Version: 1}, int64(1)).Return(model.User{ID: 1, Username: username, Version: 2}, nil)
+
+		svc := userServiceImpl{repo: repoMock, listCache: cache.NewMemoryCache(), cacheCfg: config.CacheConfig{Enabled: true, UserListTTL: time.Minute}}
+		_, _, err := svc.GetUsers(context.Background(), params)
+		assert.Nil(t, err)
+		_, err = svc.UpdateUserByID(context.Background(), 1, model.UserUpdate{Username: &username})
+		assert.Nil(t, err)
+		_, _, err = svc.GetUsers(context.Background(), params)
+		assert.Nil(t, err)
+	})
+}
+
+func TestGetUserCount(t *testing.T) {
+	t.Run("error call repo count users", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("CountUsers", context.Background(), model.UserCountParams{}).Return(int64(0), errors.New("some error"))
+
+		svc := userServiceImpl{repo: repoMock, counts: newCountCache(time.Minute)}
+		_, err := svc.GetUserCount(context.Background(), model.UserCountParams{})
+		assert.NotNil(t, err)
+
+		// failures are not cached
+		repoMock.On("CountUsers", context.Background(), model.UserCountParams{Search: "x"}).Return(int64(3), nil)
+		count, err := svc.GetUserCount(context.Background(), model.UserCountParams{Search: "x"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+	t.Run("success count is cached per filter", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		repoMock.On("CountUsers", context.Background(), model.UserCountParams{}).Return(int64(10), nil).Once()
+		repoMock.On("CountUsers", context.Background(), model.UserCountParams{CreatedAfter: &after}).Return(int64(4), nil).Once()
+
+		svc := userServiceImpl{repo: repoMock, counts: newCountCache(time.Minute)}
+		for i := 0; i < 3; i++ {
+			count, err := svc.GetUserCount(context.Background(), model.UserCountParams{})
+			assert.Nil(t, err)
+			assert.Equal(t, int64(10), count)
+		}
+		count, err := svc.GetUserCount(context.Background(), model.UserCountParams{CreatedAfter: &after})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), count)
+	})
+	t.Run("success expired count is refetched", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("CountUsers", context.Background(), model.UserCountParams{}).Return(int64(10), nil).Twice()
+
+		svc := userServiceImpl{repo: repoMock, counts: newCountCache(-time.Second)}
+		for i := 0; i < 2; i++ {
+			_, err := svc.GetUserCount(context.Background(), model.UserCountParams{})
+			assert.Nil(t, err)
+		}
 	})
 }
 
@@ -56,7 +185,7 @@ func TestGetUserById(t *testing.T) {
 		desc   string
 		in     input
 		out    output
-		doMock func() *mocks.UserQuery
+		doMock func() (*mocks.UserQuery, *mocks.FollowRepository)
 	}{
 		{
 			desc: "error get user by id repo",
@@ -68,10 +197,26 @@ func TestGetUserById(t *testing.T) {
 				err:  errors.New("some error"),
 				user: model.User{},
 			},
-			doMock: func() *mocks.UserQuery {
+			doMock: func() (*mocks.UserQuery, *mocks.FollowRepository) {
 				repoMock := mocks.NewUserQuery(t)
 				repoMock.On("GetUsersByID", context.Background(), uint64(100)).Return(model.User{}, errors.New("some error"))
-				return repoMock
+				return repoMock, mocks.NewFollowRepository(t)
+			},
+		},
+		{
+			desc: "error user not found",
+			in: input{
+				ctx: context.Background(),
+				id:  100,
+			},
+			out: output{
+				err:  ErrUserNotFound,
+				user: model.User{},
+			},
+			doMock: func() (*mocks.UserQuery, *mocks.FollowRepository) {
+				repoMock := mocks.NewUserQuery(t)
+				repoMock.On("GetUsersByID", context.Background(), uint64(100)).Return(model.User{}, nil)
+				return repoMock, mocks.NewFollowRepository(t)
 			},
 		},
 		{
@@ -82,19 +227,21 @@ func TestGetUserById(t *testing.T) {
 			},
 			out: output{
 				err:  nil,
-				user: model.User{ID: 1, Username: "user1"},
+				user: model.User{ID: 1, Username: "user1", FollowerCount: 4},
 			},
-			doMock: func() *mocks.UserQuery {
+			doMock: func() (*mocks.UserQuery, *mocks.FollowRepository) {
 				repoMock := mocks.NewUserQuery(t)
 				repoMock.On("GetUsersByID", context.Background(), uint64(100)).Return(model.User{ID: 1, Username: "user1"}, nil)
-				return repoMock
+				followMock := mocks.NewFollowRepository(t)
+				followMock.On("GetFollowCounts", context.Background(), []uint64{1}).Return(map[uint64]model.FollowCounts{1: {UserID: 1, FollowerCount: 4}}, nil)
+				return repoMock, followMock
 			},
 		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
-			repoMock := tC.doMock()
-			svc := userServiceImpl{repo: repoMock}
+			repoMock, followMock := tC.doMock()
+			svc := userServiceImpl{repo: repoMock, follows: followMock}
 			usr, err := svc.GetUsersById(tC.in.ctx, tC.in.id)
 			if tC.out.err != nil {
 				assert.EqualError(t, err, tC.out.err.Error())
@@ -105,3 +252,1033 @@ func TestGetUserById(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUserByIDOrUsername(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		key    string
+		user   model.User
+		err    error
+		doMock func(repoMock *mocks.UserQuery)
+	}{
+		{
+			desc: "success numeric key found by id",
+			key:  "7",
+			user: model.User{ID: 7},
+			doMock: func(repoMock *mocks.UserQuery) {
+				repoMock.On("GetUsersByID", context.Background(), uint64(7)).Return(model.User{ID: 7}, nil)
+			},
+		},
+		{
+			desc: "success numeric key falls back to username",
+			key:  "2024",
+			user: model.User{ID: 9, Username: "2024"},
+			doMock: func(repoMock *mocks.UserQuery) {
+				repoMock.On("GetUsersByID", context.Background(), uint64(2024)).Return(model.User{}, nil)
+				repoMock.On("GetByUsername", context.Background(), "2024").Return(model.User{ID: 9, Username: "2024"}, nil)
+			},
+		},
+		{
+			desc: "success username key skips id lookup",
+			key:  "alice",
+			user: model.User{ID: 3, Username: "alice"},
+			doMock: func(repoMock *mocks.UserQuery) {
+				repoMock.On("GetByUsername", context.Background(), "alice").Return(model.User{ID: 3, Username: "alice"}, nil)
+			},
+		},
+		{
+			desc: "error neither matches",
+			key:  "ghost",
+			err:  ErrUserNotFound,
+			doMock: func(repoMock *mocks.UserQuery) {
+				repoMock.On("GetByUsername", context.Background(), "ghost").Return(model.User{}, nil)
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			repoMock := mocks.NewUserQuery(t)
+			tC.doMock(repoMock)
+			followMock := mocks.NewFollowRepository(t)
+			if tC.user.ID != 0 {
+				followMock.On("GetFollowCounts", context.Background(), []uint64{tC.user.ID}).Return(map[uint64]model.FollowCounts{}, nil)
+			}
+			svc := userServiceImpl{repo: repoMock, follows: followMock}
+			user, err := svc.GetUserByIDOrUsername(context.Background(), tC.key)
+			assert.ErrorIs(t, err, tC.err)
+			assert.Equal(t, tC.user, user)
+		})
+	}
+
+	t.Run("error id lookup", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(7)).Return(model.User{}, errors.New("some error"))
+		svc := userServiceImpl{repo: repoMock}
+		_, err := svc.GetUserByIDOrUsername(context.Background(), "7")
+		assert.EqualError(t, err, "some error")
+	})
+}
+
+func TestGetUsersByIDs(t *testing.T) {
+	repoMock := mocks.NewUserQuery(t)
+	repoMock.On("GetUsersByIDs", context.Background(), []uint64{3, 9, 1, 3}).
+		Return([]model.User{{ID: 1}, {ID: 3}}, nil)
+	followMock := mocks.NewFollowRepository(t)
+	followMock.On("GetFollowCounts", context.Background(), []uint64{3, 1}).Return(map[uint64]model.FollowCounts{}, nil)
+
+	svc := userServiceImpl{repo: repoMock, follows: followMock}
+	users, err := svc.GetUsersByIDs(context.Background(), []uint64{3, 9, 1, 3})
+	assert.Nil(t, err)
+	// request order, without the unknown id 9 or the repeated 3
+	assert.Equal(t, []model.User{{ID: 3}, {ID: 1}}, users)
+}
+
+func TestRefreshAccessToken(t *testing.T) {
+	refreshToken := "refresh-token"
+	tokenHash := helper.HashToken(refreshToken)
+	revokedAt := time.Now().Add(-time.Minute)
+
+	testCases := []struct {
+		desc   string
+		err    error
+		doMock func() (*mocks.UserQuery, *mocks.RefreshTokenRepository)
+	}{
+		{
+			desc: "error unknown refresh token",
+			err:  ErrInvalidRefreshToken,
+			doMock: func() (*mocks.UserQuery, *mocks.RefreshTokenRepository) {
+				tokenRepoMock := mocks.NewRefreshTokenRepository(t)
+				tokenRepoMock.On("FindByTokenHash", context.Background(), tokenHash).Return(model.RefreshToken{}, gorm.ErrRecordNotFound)
+				return mocks.NewUserQuery(t), tokenRepoMock
+			},
+		},
+		{
+			desc: "error revoked refresh token",
+			err:  ErrRefreshTokenRevoked,
+			doMock: func() (*mocks.UserQuery, *mocks.RefreshTokenRepository) {
+				tokenRepoMock := mocks.NewRefreshTokenRepository(t)
+				tokenRepoMock.On("FindByTokenHash", context.Background(), tokenHash).Return(model.RefreshToken{
+					ID:        1,
+					UserID:    1,
+					ExpiresAt: time.Now().Add(time.Hour),
+					RevokedAt: &revokedAt,
+				}, nil)
+				return mocks.NewUserQuery(t), tokenRepoMock
+			},
+		},
+		{
+			desc: "error expired refresh token",
+			err:  ErrRefreshTokenExpired,
+			doMock: func() (*mocks.UserQuery, *mocks.RefreshTokenRepository) {
+				tokenRepoMock := mocks.NewRefreshTokenRepository(t)
+				tokenRepoMock.On("FindByTokenHash", context.Background(), tokenHash).Return(model.RefreshToken{
+					ID:        1,
+					UserID:    1,
+					ExpiresAt: time.Now().Add(-time.Hour),
+				}, nil)
+				return mocks.NewUserQuery(t), tokenRepoMock
+			},
+		},
+		{
+			desc: "success refresh access token",
+			err:  nil,
+			doMock: func() (*mocks.UserQuery, *mocks.RefreshTokenRepository) {
+				tokenRepoMock := mocks.NewRefreshTokenRepository(t)
+				tokenRepoMock.On("FindByTokenHash", context.Background(), tokenHash).Return(model.RefreshToken{
+					ID:        1,
+					UserID:    1,
+					ExpiresAt: time.Now().Add(time.Hour),
+				}, nil)
+				repoMock := mocks.NewUserQuery(t)
+				repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(model.User{ID: 1, Username: "user1"}, nil)
+				return repoMock, tokenRepoMock
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			repoMock, tokenRepoMock := tC.doMock()
+			jwtManager, err := helper.NewJWTManager(helper.JWTConfig{Algorithm: "HS256", Secret: "test-secret"})
+			assert.Nil(t, err)
+			svc := userServiceImpl{
+				repo:      repoMock,
+				tokenRepo: tokenRepoMock,
+				tokenCfg:  config.TokenConfig{AccessTokenExpiry: time.Hour},
+				jwt:       jwtManager,
+			}
+			token, err := svc.RefreshAccessToken(context.Background(), refreshToken)
+			if tC.err != nil {
+				assert.ErrorIs(t, err, tC.err)
+				assert.Equal(t, model.AccessToken{}, token)
+			} else {
+				assert.Nil(t, err)
+				assert.NotEqual(t, "", token.Token)
+				// the expiry reported to the client is the one signed into the token
+				claims, err := jwtManager.ValidateToken(token.Token)
+				assert.Nil(t, err)
+				assert.Equal(t, claims["exp"], float64(token.ExpiresAt.Unix()))
+				assert.Equal(t, int64(3600), token.ExpiresIn)
+			}
+		})
+	}
+}
+
+func TestSignUpNormalizeEmail(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		email string
+	}{
+		{desc: "mixed case email", email: "Foo@Example.com"},
+		{desc: "padded email", email: "  foo@example.com "},
+		{desc: "mixed case padded email", email: " FOO@example.COM\t"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			repoMock := mocks.NewUserQuery(t)
+			repoMock.
+				On("CreateUser", context.Background(), mock.MatchedBy(func(user model.User) bool {
+					return user.Email == "foo@example.com"
+				})).
+				Return(model.User{ID: 1, Email: "foo@example.com"}, nil)
+
+			svc := userServiceImpl{repo: repoMock}
+			usr, err := svc.SignUp(context.Background(), model.UserSignUp{
+				Username: "foo",
+				Password: "abc12345",
+				Email:    tC.email,
+				Age:      20,
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, "foo@example.com", usr.Email)
+		})
+	}
+
+	t.Run("error email registered with different case", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.
+			On("CreateUser", context.Background(), mock.MatchedBy(func(user model.User) bool {
+				return user.Email == "foo@example.com"
+			})).
+			Return(model.User{}, repository.ErrDuplicateEmail)
+
+		svc := userServiceImpl{repo: repoMock}
+		_, err := svc.SignUp(context.Background(), model.UserSignUp{
+			Username: "foo",
+			Password: "abc12345",
+			Email:    " Foo@Example.com ",
+			Age:      20,
+		})
+		assert.ErrorIs(t, err, ErrEmailAlreadyExists)
+	})
+}
+
+func TestSignUpDuplicate(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		repoErr error
+		err     error
+	}{
+		{desc: "error duplicate email", repoErr: repository.ErrDuplicateEmail, err: ErrEmailAlreadyExists},
+		{desc: "error duplicate username", repoErr: repository.ErrDuplicateUsername, err: ErrUsernameAlreadyExists},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			repoMock := mocks.NewUserQuery(t)
+			repoMock.On("CreateUser", context.Background(), mock.Anything).Return(model.User{}, tC.repoErr)
+
+			svc := userServiceImpl{repo: repoMock}
+			_, err := svc.SignUp(context.Background(), model.UserSignUp{
+				Username: "foo",
+				Password: "abc12345",
+				Email:    "foo@example.com",
+				Age:      20,
+			})
+			assert.ErrorIs(t, err, tC.err)
+		})
+	}
+}
+
+// failingJWT can't sign tokens
+type failingJWT struct {
+	helper.JWTManager
+}
+
+func (failingJWT) GenerateToken(claim any) (string, error) {
+	return "", errors.New("signing failed")
+}
+
+func TestSignUpWithTokens(t *testing.T) {
+	signUp := model.UserSignUp{Username: "foo", Password: "abc12345", Email: "foo@example.com", Age: 20}
+	emailCfg := config.EmailConfig{VerifyURL: "https://app.example/verify", VerificationTokenExpiry: time.Hour}
+	newMocks := func(t *testing.T) (*mocks.UserQuery, *mocks.Transactor, *mocks.EmailVerificationRepository) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("CreateUser", mock.Anything, mock.Anything).Return(model.User{ID: 1, Username: "foo", Email: "foo@example.com"}, nil)
+
+		// run fn inline, keeping the error WithTx would roll back on
+		txMock := mocks.NewTransactor(t)
+		txMock.On("WithTx", context.Background(), mock.Anything).
+			Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+
+		verifyMock := mocks.NewEmailVerificationRepository(t)
+		verifyMock.On("CreateToken", mock.Anything, mock.MatchedBy(func(token model.EmailVerificationToken) bool {
+			return token.UserID == 1 && len(token.TokenHash) == 64 && token.ExpiresAt.After(time.Now())
+		})).Return(model.EmailVerificationToken{ID: 1}, nil)
+		return repoMock, txMock, verifyMock
+	}
+
+	t.Run("success issue both tokens and mail the link", func(t *testing.T) {
+		repoMock, txMock, verifyMock := newMocks(t)
+		tokenRepoMock := mocks.NewRefreshTokenRepository(t)
+		tokenRepoMock.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(model.RefreshToken{ID: 1}, nil)
+		jwtManager, err := helper.NewJWTManager(helper.JWTConfig{Algorithm: "HS256", Secret: "test-secret"})
+		assert.Nil(t, err)
+		senderMock := mailermocks.NewEmailSender(t)
+		senderMock.On("Send", context.Background(), mock.MatchedBy(func(msg mailer.Message) bool {
+			return msg.To == "foo@example.com" && strings.Contains(msg.Body, "https://app.example/verify?token=")
+		})).Return(nil)
+		hookMock := webhookmocks.NewWebhook(t)
+		hookMock.On("Dispatch", context.Background(), mock.MatchedBy(func(event webhook.Event) bool {
+			return event.Type == webhook.EventUserCreated && event.Data == model.UserWebhook{ID: 1, Username: "foo", Email: "foo@example.com"}
+		})).Return()
+
+		svc := userServiceImpl{repo: repoMock, tx: txMock, tokenRepo: tokenRepoMock, jwt: jwtManager, verifyRepo: verifyMock, emails: senderMock, emailCfg: emailCfg, hooks: hookMock}
+		user, tokens, err := svc.SignUpWithTokens(context.Background(), signUp)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), user.ID)
+		assert.NotEmpty(t, tokens.Token)
+		assert.NotEmpty(t, tokens.RefreshToken)
+	})
+
+	t.Run("success email failure keeps the account", func(t *testing.T) {
+		repoMock, txMock, verifyMock := newMocks(t)
+		tokenRepoMock := mocks.NewRefreshTokenRepository(t)
+		tokenRepoMock.On("CreateRefreshToken", mock.Anything, mock.Anything).Return(model.RefreshToken{ID: 1}, nil)
+		jwtManager, err := helper.NewJWTManager(helper.JWTConfig{Algorithm: "HS256", Secret: "test-secret"})
+		assert.Nil(t, err)
+		senderMock := mailermocks.NewEmailSender(t)
+		senderMock.On("Send", context.Background(), mock.Anything).Return(errors.New("smtp down"))
+
+		svc := userServiceImpl{repo: repoMock, tx: txMock, tokenRepo: tokenRepoMock, jwt: jwtManager, verifyRepo: verifyMock, emails: senderMock, emailCfg: emailCfg}
+		user, _, err := svc.SignUpWithTokens(context.Background(), signUp)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), user.ID)
+	})
+
+	t.Run("error access token fails inside the transaction", func(t *testing.T) {
+		repoMock, txMock, verifyMock := newMocks(t)
+
+		// no email or webhook goes out for an account that was rolled back
+		svc := userServiceImpl{repo: repoMock, tx: txMock, jwt: failingJWT{}, verifyRepo: verifyMock, emails: mailermocks.NewEmailSender(t), emailCfg: emailCfg, hooks: webhookmocks.NewWebhook(t)}
+		user, tokens, err := svc.SignUpWithTokens(context.Background(), signUp)
+		assert.EqualError(t, err, "signing failed")
+		assert.Equal(t, model.User{}, user)
+		assert.Equal(t, model.TokenPair{}, tokens)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	token := "raw-token"
+
+	t.Run("error unknown token", func(t *testing.T) {
+		verifyMock := mocks.NewEmailVerificationRepository(t)
+		verifyMock.On("FindByTokenHash", context.Background(), helper.HashToken(token)).Return(model.EmailVerificationToken{}, gorm.ErrRecordNotFound)
+
+		svc := userServiceImpl{verifyRepo: verifyMock}
+		assert.ErrorIs(t, svc.VerifyEmail(context.Background(), token), ErrInvalidVerificationToken)
+	})
+
+	t.Run("error expired token", func(t *testing.T) {
+		verifyMock := mocks.NewEmailVerificationRepository(t)
+		verifyMock.On("FindByTokenHash", context.Background(), helper.HashToken(token)).
+			Return(model.EmailVerificationToken{UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		svc := userServiceImpl{verifyRepo: verifyMock}
+		assert.ErrorIs(t, svc.VerifyEmail(context.Background(), token), ErrVerificationTokenExpired)
+	})
+
+	t.Run("success mark verified and drop the tokens", func(t *testing.T) {
+		verifyMock := mocks.NewEmailVerificationRepository(t)
+		verifyMock.On("FindByTokenHash", context.Background(), helper.HashToken(token)).
+			Return(model.EmailVerificationToken{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		verifyMock.On("DeleteByUserID", context.Background(), uint64(1)).Return(nil)
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("MarkEmailVerified", context.Background(), uint64(1)).Return(nil)
+		txMock := mocks.NewTransactor(t)
+		txMock.On("WithTx", context.Background(), mock.Anything).
+			Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+
+		svc := userServiceImpl{repo: repoMock, tx: txMock, verifyRepo: verifyMock}
+		assert.Nil(t, svc.VerifyEmail(context.Background(), token))
+	})
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	emailCfg := config.EmailConfig{ResetURL: "https://app.example/reset", PasswordResetTokenExpiry: time.Hour}
+
+	t.Run("success unknown email does nothing", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "nobody@example.com").Return(model.User{}, gorm.ErrRecordNotFound)
+
+		svc := userServiceImpl{repo: repoMock, resetRepo: mocks.NewPasswordResetRepository(t), emails: mailermocks.NewEmailSender(t), emailCfg: emailCfg}
+		assert.Nil(t, svc.RequestPasswordReset(context.Background(), " Nobody@Example.com"))
+	})
+
+	t.Run("success mail a link for a known email", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").Return(model.User{ID: 1, Email: "foo@example.com"}, nil)
+		resetMock := mocks.NewPasswordResetRepository(t)
+		resetMock.On("CreateToken", context.Background(), mock.MatchedBy(func(token model.PasswordResetToken) bool {
+			return token.UserID == 1 && len(token.TokenHash) == 64 && token.ExpiresAt.After(time.Now())
+		})).Return(model.PasswordResetToken{ID: 1}, nil)
+		senderMock := mailermocks.NewEmailSender(t)
+		senderMock.On("Send", context.Background(), mock.MatchedBy(func(msg mailer.Message) bool {
+			return msg.To == "foo@example.com" && strings.Contains(msg.Body, "https://app.example/reset?token=")
+		})).Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, resetRepo: resetMock, emails: senderMock, emailCfg: emailCfg}
+		assert.Nil(t, svc.RequestPasswordReset(context.Background(), "foo@example.com"))
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	token := "raw-token"
+	inlineTx := func(t *testing.T) *mocks.Transactor {
+		txMock := mocks.NewTransactor(t)
+		txMock.On("WithTx", context.Background(), mock.Anything).
+			Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+		return txMock
+	}
+
+	t.Run("error unknown or used token", func(t *testing.T) {
+		resetMock := mocks.NewPasswordResetRepository(t)
+		resetMock.On("ConsumeToken", context.Background(), helper.HashToken(token)).Return(model.PasswordResetToken{}, gorm.ErrRecordNotFound)
+
+		svc := userServiceImpl{tx: inlineTx(t), resetRepo: resetMock}
+		assert.ErrorIs(t, svc.ResetPassword(context.Background(), token, "newpass123"), ErrInvalidResetToken)
+	})
+
+	t.Run("error expired token", func(t *testing.T) {
+		resetMock := mocks.NewPasswordResetRepository(t)
+		resetMock.On("ConsumeToken", context.Background(), helper.HashToken(token)).
+			Return(model.PasswordResetToken{UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		svc := userServiceImpl{tx: inlineTx(t), resetRepo: resetMock}
+		assert.ErrorIs(t, svc.ResetPassword(context.Background(), token, "newpass123"), ErrResetTokenExpired)
+	})
+
+	t.Run("error password same as username", func(t *testing.T) {
+		resetMock := mocks.NewPasswordResetRepository(t)
+		resetMock.On("ConsumeToken", context.Background(), helper.HashToken(token)).
+			Return(model.PasswordResetToken{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(model.User{ID: 1, Username: "foo12345"}, nil)
+
+		svc := userServiceImpl{repo: repoMock, tx: inlineTx(t), resetRepo: resetMock}
+		var verrs pkg.ValidationErrors
+		assert.ErrorAs(t, svc.ResetPassword(context.Background(), token, "FOO12345"), &verrs)
+	})
+
+	t.Run("success update hash and drop the tokens", func(t *testing.T) {
+		resetMock := mocks.NewPasswordResetRepository(t)
+		resetMock.On("ConsumeToken", context.Background(), helper.HashToken(token)).
+			Return(model.PasswordResetToken{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		resetMock.On("DeleteByUserID", context.Background(), uint64(1)).Return(nil)
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(model.User{ID: 1, Username: "foo"}, nil)
+		repoMock.On("UpdatePassword", context.Background(), uint64(1), mock.MatchedBy(func(hash string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hash), []byte("newpass123")) == nil
+		})).Return(nil)
+		tokenMock := mocks.NewRefreshTokenRepository(t)
+		tokenMock.On("RevokeUserRefreshTokens", context.Background(), uint64(1), "").Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, tx: inlineTx(t), tokenRepo: tokenMock, resetRepo: resetMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+		assert.Nil(t, svc.ResetPassword(context.Background(), token, "newpass123"))
+	})
+
+	t.Run("error revoking the sessions fails the reset", func(t *testing.T) {
+		resetMock := mocks.NewPasswordResetRepository(t)
+		resetMock.On("ConsumeToken", context.Background(), helper.HashToken(token)).
+			Return(model.PasswordResetToken{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(model.User{ID: 1, Username: "foo"}, nil)
+		repoMock.On("UpdatePassword", context.Background(), uint64(1), mock.Anything).Return(nil)
+		tokenMock := mocks.NewRefreshTokenRepository(t)
+		tokenMock.On("RevokeUserRefreshTokens", context.Background(), uint64(1), "").Return(errors.New("db down"))
+
+		svc := userServiceImpl{repo: repoMock, tx: inlineTx(t), tokenRepo: tokenMock, resetRepo: resetMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+		assert.NotNil(t, svc.ResetPassword(context.Background(), token, "newpass123"))
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	currentHash, err := helper.GenerateHashWithCost("oldpass123", bcrypt.MinCost)
+	assert.Nil(t, err)
+	user := model.User{ID: 1, Username: "foo", Email: "foo@example.com", Password: currentHash}
+	inlineTx := func(t *testing.T) *mocks.Transactor {
+		txMock := mocks.NewTransactor(t)
+		txMock.On("WithTx", context.Background(), mock.Anything).
+			Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+		return txMock
+	}
+
+	t.Run("error wrong current password", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(user, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		assert.ErrorIs(t, svc.ChangePassword(context.Background(), 1, "wrongpass1", "newpass123", ""), ErrWrongCurrentPassword)
+	})
+
+	t.Run("error new password equals current", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(user, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		assert.ErrorIs(t, svc.ChangePassword(context.Background(), 1, "oldpass123", "oldpass123", ""), ErrPasswordUnchanged)
+	})
+
+	t.Run("error password same as email", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(model.User{ID: 1, Email: "foo12345@example.com", Password: currentHash}, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		var verrs pkg.ValidationErrors
+		assert.ErrorAs(t, svc.ChangePassword(context.Background(), 1, "oldpass123", "foo12345", ""), &verrs)
+	})
+
+	t.Run("success update hash", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(user, nil)
+		repoMock.On("UpdatePassword", context.Background(), uint64(1), mock.MatchedBy(func(hash string) bool {
+			return bcrypt.CompareHashAndPassword([]byte(hash), []byte("newpass123")) == nil
+		})).Return(nil)
+
+		tokenMock := mocks.NewRefreshTokenRepository(t)
+		tokenMock.On("RevokeUserRefreshTokens", context.Background(), uint64(1), "").Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, tx: inlineTx(t), tokenRepo: tokenMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+		assert.Nil(t, svc.ChangePassword(context.Background(), 1, "oldpass123", "newpass123", ""))
+	})
+
+	t.Run("success keep the current session", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(user, nil)
+		repoMock.On("UpdatePassword", context.Background(), uint64(1), mock.Anything).Return(nil)
+		tokenMock := mocks.NewRefreshTokenRepository(t)
+		tokenMock.On("RevokeUserRefreshTokens", context.Background(), uint64(1), helper.HashToken("current-refresh")).Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, tx: inlineTx(t), tokenRepo: tokenMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+		assert.Nil(t, svc.ChangePassword(context.Background(), 1, "oldpass123", "newpass123", "current-refresh"))
+	})
+}
+
+func TestSignUpConcurrent(t *testing.T) {
+	// stands in for the unique index, only the first insert of an email wins
+	var (
+		mu    sync.Mutex
+		taken = map[string]bool{}
+	)
+	repoMock := mocks.NewUserQuery(t)
+	repoMock.On("CreateUser", context.Background(), mock.Anything).
+		Return(func(ctx context.Context, user model.User) (model.User, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if taken[user.Email] {
+				return model.User{}, repository.ErrDuplicateEmail
+			}
+			taken[user.Email] = true
+			user.ID = 1
+			return user, nil
+		})
+
+	svc := userServiceImpl{repo: repoMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+	const signUps = 10
+	errs := make(chan error, signUps)
+	var wg sync.WaitGroup
+	for i := 0; i < signUps; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.SignUp(context.Background(), model.UserSignUp{
+				Username: fmt.Sprintf("foo%d", i),
+				Password: "abc12345",
+				Email:    "Foo@Example.com",
+				Age:      20,
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrEmailAlreadyExists)
+	}
+	assert.Equal(t, 1, created)
+}
+
+func TestSearchUsernames(t *testing.T) {
+	t.Run("success skip query for a bare @", func(t *testing.T) {
+		svc := userServiceImpl{}
+		users, err := svc.SearchUsernames(context.Background(), " @ ", 5)
+		assert.Nil(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("success strip the @", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("SearchUsernames", context.Background(), "jo", 5).Return([]model.MentionedUser{{ID: 1, Username: "jo"}}, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		users, err := svc.SearchUsernames(context.Background(), "@jo", 5)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.MentionedUser{{ID: 1, Username: "jo"}}, users)
+	})
+}
+
+func TestIsUsernameAvailable(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		taken     bool
+		available bool
+	}{
+		{desc: "success username free", taken: false, available: true},
+		{desc: "success username taken", taken: true, available: false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			repoMock := mocks.NewUserQuery(t)
+			repoMock.On("ExistsByUsername", context.Background(), "foo").Return(tC.taken, nil)
+
+			svc := userServiceImpl{repo: repoMock}
+			available, err := svc.IsUsernameAvailable(context.Background(), "foo")
+			assert.Nil(t, err)
+			assert.Equal(t, tC.available, available)
+		})
+	}
+
+	t.Run("success reserved username never available", func(t *testing.T) {
+		svc := userServiceImpl{repo: mocks.NewUserQuery(t)}
+		available, err := svc.IsUsernameAvailable(context.Background(), "Me")
+		assert.Nil(t, err)
+		assert.False(t, available)
+	})
+}
+
+func TestSignInNormalizeEmail(t *testing.T) {
+	hash, err := helper.GenerateHash("abc12345")
+	assert.Nil(t, err)
+
+	for _, email := range []string{"Foo@Example.com", " foo@example.com  "} {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").Return(model.User{ID: 1, Email: "foo@example.com", Password: hash}, nil)
+		repoMock.On("RecordLogin", context.Background(), uint64(1), mock.Anything).Return(nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		usr, err := svc.SignIn(context.Background(), model.UserSignIn{Email: email, Password: "abc12345"})
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), usr.ID)
+	}
+}
+
+func TestSignInUnknownEmail(t *testing.T) {
+	repoMock := mocks.NewUserQuery(t)
+	repoMock.On("FindByEmail", context.Background(), "nobody@example.com").Return(model.User{}, gorm.ErrRecordNotFound)
+
+	svc := userServiceImpl{repo: repoMock}
+	_, err := svc.SignIn(context.Background(), model.UserSignIn{Email: "nobody@example.com", Password: "abc12345"})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// the dummy compare only evens out the timing if it is a real hash at
+	// the cost of the accounts
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	assert.Nil(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestSignInLockout(t *testing.T) {
+	hash, err := helper.GenerateHashWithCost("abc12345", bcrypt.MinCost)
+	assert.Nil(t, err)
+	signInCfg := config.SignInConfig{LockoutThreshold: 3, LockoutDuration: 15 * time.Minute}
+	wrong := model.UserSignIn{Email: "foo@example.com", Password: "wrongpass"}
+	right := model.UserSignIn{Email: "foo@example.com", Password: "abc12345"}
+
+	// newRepo keeps the lockout columns the way the sql in
+	// RecordFailedLogin and RecordLogin updates them
+	newRepo := func(t *testing.T, user *model.User) *mocks.UserQuery {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").
+			Return(func(context.Context, string) (model.User, error) { return *user, nil })
+		repoMock.On("RecordFailedLogin", context.Background(), uint64(1), 3, mock.Anything).
+			Return(func(_ context.Context, _ uint64, threshold int, lockUntil time.Time) error {
+				user.FailedLoginAttempts++
+				if user.FailedLoginAttempts >= threshold {
+					user.FailedLoginAttempts = 0
+					user.LockedUntil = &lockUntil
+				}
+				return nil
+			}).Maybe()
+		repoMock.On("RecordLogin", context.Background(), uint64(1), mock.Anything).
+			Return(func(context.Context, uint64, time.Time) error {
+				user.FailedLoginAttempts = 0
+				user.LockedUntil = nil
+				return nil
+			}).Maybe()
+		return repoMock
+	}
+
+	t.Run("error locked after threshold even with the right password", func(t *testing.T) {
+		user := &model.User{ID: 1, Email: "foo@example.com", Password: hash}
+		svc := userServiceImpl{repo: newRepo(t, user), passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}, signInCfg: signInCfg}
+
+		for i := 0; i < 3; i++ {
+			_, err := svc.SignIn(context.Background(), wrong)
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		}
+		assert.NotNil(t, user.LockedUntil)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), *user.LockedUntil, time.Minute)
+
+		_, err := svc.SignIn(context.Background(), right)
+		assert.ErrorIs(t, err, ErrAccountLocked)
+	})
+
+	t.Run("success unlocked once the cooldown passed", func(t *testing.T) {
+		expired := time.Now().Add(-time.Second)
+		user := &model.User{ID: 1, Email: "foo@example.com", Password: hash, LockedUntil: &expired}
+		svc := userServiceImpl{repo: newRepo(t, user), passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}, signInCfg: signInCfg}
+
+		usr, err := svc.SignIn(context.Background(), right)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), usr.ID)
+		assert.Nil(t, user.LockedUntil)
+	})
+
+	t.Run("success sign in resets the count", func(t *testing.T) {
+		user := &model.User{ID: 1, Email: "foo@example.com", Password: hash}
+		svc := userServiceImpl{repo: newRepo(t, user), passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}, signInCfg: signInCfg}
+
+		for i := 0; i < 2; i++ {
+			_, _ = svc.SignIn(context.Background(), wrong)
+		}
+		_, err := svc.SignIn(context.Background(), right)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, user.FailedLoginAttempts)
+
+		// the two failures before the sign in no longer count
+		_, err = svc.SignIn(context.Background(), wrong)
+		assert.NotErrorIs(t, err, ErrAccountLocked)
+		assert.Nil(t, user.LockedUntil)
+	})
+
+	t.Run("success lockout disabled", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").Return(model.User{ID: 1, Email: "foo@example.com", Password: hash}, nil)
+
+		svc := userServiceImpl{repo: repoMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+		_, err := svc.SignIn(context.Background(), wrong)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSignInLastLogin(t *testing.T) {
+	hash, err := helper.GenerateHashWithCost("abc12345", bcrypt.MinCost)
+	assert.Nil(t, err)
+	previous := time.Now().Add(-24 * time.Hour)
+
+	t.Run("success timestamp advances", func(t *testing.T) {
+		var recorded time.Time
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").Return(model.User{ID: 1, Email: "foo@example.com", Password: hash, LastLoginAt: &previous}, nil)
+		repoMock.On("RecordLogin", context.Background(), uint64(1), mock.Anything).
+			Run(func(args mock.Arguments) { recorded = args.Get(2).(time.Time) }).
+			Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+		usr, err := svc.SignIn(context.Background(), model.UserSignIn{Email: "foo@example.com", Password: "abc12345"})
+		assert.Nil(t, err)
+		assert.True(t, recorded.After(previous))
+		assert.Equal(t, recorded, *usr.LastLoginAt)
+	})
+
+	t.Run("success sign in survives failed record", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").Return(model.User{ID: 1, Email: "foo@example.com", Password: hash, LastLoginAt: &previous}, nil)
+		repoMock.On("RecordLogin", context.Background(), uint64(1), mock.Anything).Return(errors.New("some error"))
+
+		svc := userServiceImpl{repo: repoMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+		usr, err := svc.SignIn(context.Background(), model.UserSignIn{Email: "foo@example.com", Password: "abc12345"})
+		assert.Nil(t, err)
+		assert.Equal(t, previous, *usr.LastLoginAt)
+	})
+}
+
+func TestSignInRehash(t *testing.T) {
+	lowHash, err := helper.GenerateHashWithCost("abc12345", bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	t.Run("success low cost hash is upgraded", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").Return(model.User{ID: 1, Email: "foo@example.com", Password: lowHash}, nil)
+		repoMock.On("RecordLogin", context.Background(), uint64(1), mock.Anything).Return(nil)
+		repoMock.
+			On("UpdatePassword", context.Background(), uint64(1), mock.MatchedBy(func(hash string) bool {
+				cost, err := bcrypt.Cost([]byte(hash))
+				return err == nil && cost == bcrypt.MinCost+1 && bcrypt.CompareHashAndPassword([]byte(hash), []byte("abc12345")) == nil
+			})).
+			Return(nil).Once()
+
+		svc := userServiceImpl{repo: repoMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost + 1}}
+		usr, err := svc.SignIn(context.Background(), model.UserSignIn{Email: "foo@example.com", Password: "abc12345"})
+		assert.Nil(t, err)
+		assert.NotEqual(t, lowHash, usr.Password)
+	})
+
+	t.Run("success hash at current cost is kept", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").Return(model.User{ID: 1, Email: "foo@example.com", Password: lowHash}, nil)
+		repoMock.On("RecordLogin", context.Background(), uint64(1), mock.Anything).Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost}}
+		usr, err := svc.SignIn(context.Background(), model.UserSignIn{Email: "foo@example.com", Password: "abc12345"})
+		assert.Nil(t, err)
+		assert.Equal(t, lowHash, usr.Password)
+	})
+
+	t.Run("success sign in survives failed rehash", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("FindByEmail", context.Background(), "foo@example.com").Return(model.User{ID: 1, Email: "foo@example.com", Password: lowHash}, nil)
+		repoMock.On("RecordLogin", context.Background(), uint64(1), mock.Anything).Return(nil)
+		repoMock.On("UpdatePassword", context.Background(), uint64(1), mock.Anything).Return(errors.New("some error")).Once()
+
+		svc := userServiceImpl{repo: repoMock, passwordCfg: config.PasswordConfig{BcryptCost: bcrypt.MinCost + 1}}
+		_, err := svc.SignIn(context.Background(), model.UserSignIn{Email: "foo@example.com", Password: "abc12345"})
+		assert.Nil(t, err)
+	})
+}
+
+func TestUpdateUserByID(t *testing.T) {
+	existing := model.User{ID: 1, Username: "user1", Email: "user1@mail.com", Age: 20, Version: 3}
+
+	t.Run("success update username only keeps email", func(t *testing.T) {
+		newUsername := "user1-renamed"
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(existing, nil)
+		repoMock.
+			On("UpdateUserIfVersion", context.Background(), model.User{ID: 1, Username: "user1-renamed", Email: "user1@mail.com", Age: 20, Version: 3}, int64(3)).
+			Return(model.User{ID: 1, Username: "user1-renamed", Email: "user1@mail.com", Age: 20, Version: 4}, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		usr, err := svc.UpdateUserByID(context.Background(), 1, model.UserUpdate{Username: &newUsername})
+		assert.Nil(t, err)
+		assert.Equal(t, "user1-renamed", usr.Username)
+		assert.Equal(t, "user1@mail.com", usr.Email)
+		assert.Equal(t, int64(4), usr.Version)
+	})
+
+	t.Run("success update email only keeps username", func(t *testing.T) {
+		newEmail := " New@Mail.com"
+		verified := existing
+		verified.EmailVerified = true
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(verified, nil)
+		// a new address has to be verified again
+		repoMock.
+			On("UpdateUserIfVersion", context.Background(), model.User{ID: 1, Username: "user1", Email: "new@mail.com", Age: 20, Version: 3}, int64(3)).
+			Return(model.User{ID: 1, Username: "user1", Email: "new@mail.com", Age: 20, Version: 4}, nil)
+		txMock := mocks.NewTransactor(t)
+		txMock.On("WithTx", context.Background(), mock.Anything).
+			Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+		verifyMock := mocks.NewEmailVerificationRepository(t)
+		verifyMock.On("DeleteByUserID", context.Background(), uint64(1)).Return(nil)
+		verifyMock.On("CreateToken", context.Background(), mock.MatchedBy(func(token model.EmailVerificationToken) bool {
+			return token.UserID == 1 && len(token.TokenHash) == 64
+		})).Return(model.EmailVerificationToken{ID: 1}, nil)
+		senderMock := mailermocks.NewEmailSender(t)
+		senderMock.On("Send", context.Background(), mock.MatchedBy(func(msg mailer.Message) bool {
+			return msg.To == "new@mail.com" && strings.Contains(msg.Body, "https://app.example/verify?token=")
+		})).Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, tx: txMock, verifyRepo: verifyMock, emails: senderMock, emailCfg: config.EmailConfig{VerifyURL: "https://app.example/verify", VerificationTokenExpiry: time.Hour}}
+		usr, err := svc.UpdateUserByID(context.Background(), 1, model.UserUpdate{Email: &newEmail})
+		assert.Nil(t, err)
+		assert.Equal(t, "user1", usr.Username)
+	})
+
+	t.Run("success same email in another case keeps the verification", func(t *testing.T) {
+		sameEmail := "User1@Mail.com"
+		verified := existing
+		verified.EmailVerified = true
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(verified, nil)
+		repoMock.
+			On("UpdateUserIfVersion", context.Background(), model.User{ID: 1, Username: "user1", Email: "user1@mail.com", EmailVerified: true, Age: 20, Version: 3}, int64(3)).
+			Return(model.User{ID: 1, Username: "user1", Email: "user1@mail.com", EmailVerified: true, Age: 20, Version: 4}, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		usr, err := svc.UpdateUserByID(context.Background(), 1, model.UserUpdate{Email: &sameEmail})
+		assert.Nil(t, err)
+		assert.True(t, usr.EmailVerified)
+	})
+
+	t.Run("error new verification token rolls the email back", func(t *testing.T) {
+		newEmail := "new@mail.com"
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(existing, nil)
+		repoMock.On("UpdateUserIfVersion", context.Background(), mock.Anything, int64(3)).Return(model.User{ID: 1, Version: 4}, nil)
+		txMock := mocks.NewTransactor(t)
+		txMock.On("WithTx", context.Background(), mock.Anything).
+			Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+		verifyMock := mocks.NewEmailVerificationRepository(t)
+		verifyMock.On("DeleteByUserID", context.Background(), uint64(1)).Return(errors.New("some error"))
+
+		svc := userServiceImpl{repo: repoMock, tx: txMock, verifyRepo: verifyMock}
+		_, err := svc.UpdateUserByID(context.Background(), 1, model.UserUpdate{Email: &newEmail})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("success strip markup from the profile", func(t *testing.T) {
+		displayName, bio := " <b>User One</b> ", `hello<script>alert("x")</script> world`
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(existing, nil)
+		repoMock.
+			On("UpdateUserIfVersion", context.Background(), model.User{ID: 1, Username: "user1", Email: "user1@mail.com", Age: 20, DisplayName: "User One", Bio: "hello world", Version: 3}, int64(3)).
+			Return(model.User{ID: 1, Username: "user1", DisplayName: "User One", Bio: "hello world", Version: 4}, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		usr, err := svc.UpdateUserByID(context.Background(), 1, model.UserUpdate{DisplayName: &displayName, Bio: &bio})
+		assert.Nil(t, err)
+		assert.Equal(t, "hello world", usr.Bio)
+	})
+
+	t.Run("error client version is stale", func(t *testing.T) {
+		newUsername := "user1-renamed"
+		stale := int64(2)
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(existing, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		_, err := svc.UpdateUserByID(context.Background(), 1, model.UserUpdate{Username: &newUsername, Version: &stale})
+		assert.ErrorIs(t, err, ErrUserModified)
+	})
+
+	t.Run("error concurrent update wins the race", func(t *testing.T) {
+		newUsername := "user1-renamed"
+		version := int64(3)
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(existing, nil)
+		repoMock.On("UpdateUserIfVersion", context.Background(), mock.Anything, int64(3)).Return(model.User{}, repository.ErrVersionConflict)
+
+		svc := userServiceImpl{repo: repoMock}
+		_, err := svc.UpdateUserByID(context.Background(), 1, model.UserUpdate{Username: &newUsername, Version: &version})
+		assert.ErrorIs(t, err, ErrUserModified)
+	})
+}
+
+func TestReplaceUser(t *testing.T) {
+	existing := model.User{ID: 1, Username: "user1", Email: "user1@mail.com", Age: 20, Version: 3}
+
+	t.Run("success overwrites username and email", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(existing, nil)
+		repoMock.
+			On("UpdateUserIfVersion", context.Background(), model.User{ID: 1, Username: "user2", Email: "user2@mail.com", Age: 20, Version: 3}, int64(3)).
+			Return(model.User{ID: 1, Username: "user2", Email: "user2@mail.com", Age: 20, Version: 4}, nil)
+		txMock := mocks.NewTransactor(t)
+		txMock.On("WithTx", context.Background(), mock.Anything).
+			Return(func(ctx context.Context, fn func(context.Context) error) error { return fn(ctx) })
+		verifyMock := mocks.NewEmailVerificationRepository(t)
+		verifyMock.On("DeleteByUserID", context.Background(), uint64(1)).Return(nil)
+		verifyMock.On("CreateToken", context.Background(), mock.Anything).Return(model.EmailVerificationToken{ID: 1}, nil)
+		senderMock := mailermocks.NewEmailSender(t)
+		senderMock.On("Send", context.Background(), mock.MatchedBy(func(msg mailer.Message) bool {
+			return msg.To == "user2@mail.com"
+		})).Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, tx: txMock, verifyRepo: verifyMock, emails: senderMock, emailCfg: config.EmailConfig{VerifyURL: "https://app.example/verify", VerificationTokenExpiry: time.Hour}}
+		usr, err := svc.ReplaceUser(context.Background(), 1, model.UserReplace{Username: "user2", Email: "User2@mail.com "})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), usr.Version)
+	})
+
+	t.Run("error client version is stale", func(t *testing.T) {
+		stale := int64(2)
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", context.Background(), uint64(1)).Return(existing, nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		_, err := svc.ReplaceUser(context.Background(), 1, model.UserReplace{Username: "user2", Email: "user2@mail.com", Version: &stale})
+		assert.ErrorIs(t, err, ErrUserModified)
+	})
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	before := time.Now()
+
+	t.Run("success purge every expired user", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetDeletedUserIDs", context.Background(), before).Return([]uint64{3, 7}, nil)
+		repoMock.On("HardDeleteUser", context.Background(), uint64(3)).Return(nil)
+		repoMock.On("HardDeleteUser", context.Background(), uint64(7)).Return(nil)
+
+		svc := userServiceImpl{repo: repoMock}
+		purged, err := svc.PurgeDeletedUsers(context.Background(), before)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, purged)
+	})
+
+	t.Run("error stop on first failed delete", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetDeletedUserIDs", context.Background(), before).Return([]uint64{3, 7}, nil)
+		repoMock.On("HardDeleteUser", context.Background(), uint64(3)).Return(errors.New("some error"))
+
+		svc := userServiceImpl{repo: repoMock}
+		purged, err := svc.PurgeDeletedUsers(context.Background(), before)
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, purged)
+	})
+}
+
+func TestUpdateAvatar(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("error unsupported content type", func(t *testing.T) {
+		svc := userServiceImpl{}
+		_, err := svc.UpdateAvatar(ctx, 1, strings.NewReader("GIF89a"), "image/gif")
+		assert.ErrorIs(t, err, ErrUnsupportedImageType)
+	})
+
+	t.Run("error update fails removes the new file", func(t *testing.T) {
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", ctx, uint64(1)).Return(model.User{ID: 1}, nil)
+		repoMock.On("UpdateAvatar", ctx, uint64(1), mock.Anything).Return(errors.New("some error"))
+		files := storage.NewFake()
+
+		svc := userServiceImpl{repo: repoMock, avatars: files}
+		_, err := svc.UpdateAvatar(ctx, 1, strings.NewReader("png"), "image/png")
+		assert.NotNil(t, err)
+		assert.Empty(t, files.Keys())
+	})
+
+	t.Run("success replace previous avatar", func(t *testing.T) {
+		files := storage.NewFake()
+		oldURL, _ := files.Put(ctx, "avatars/1-old.jpg", strings.NewReader("old"), "image/jpeg")
+		repoMock := mocks.NewUserQuery(t)
+		repoMock.On("GetUsersByID", ctx, uint64(1)).Return(model.User{ID: 1, AvatarURL: oldURL}, nil)
+		repoMock.On("UpdateAvatar", ctx, uint64(1), mock.Anything).Return(nil)
+
+		svc := userServiceImpl{repo: repoMock, avatars: files}
+		user, err := svc.UpdateAvatar(ctx, 1, strings.NewReader("jpeg"), "image/jpeg")
+		assert.Nil(t, err)
+
+		key, ok := files.KeyFromURL(user.AvatarURL)
+		assert.True(t, ok)
+		assert.True(t, strings.HasPrefix(key, "avatars/1-") && strings.HasSuffix(key, ".jpg"))
+		assert.Equal(t, []string{key}, files.Keys())
+		repoMock.AssertCalled(t, "UpdateAvatar", ctx, uint64(1), user.AvatarURL)
+	})
+}
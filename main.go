@@ -1,22 +1,43 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"go-mygram/docs"
+	"go-mygram/internal/config"
 	"go-mygram/internal/handler"
 	"go-mygram/internal/infrastructure"
+	"go-mygram/internal/middleware"
+	"go-mygram/internal/migration"
 	"go-mygram/internal/model"
 	"go-mygram/internal/repository"
 	"go-mygram/internal/router"
+	"go-mygram/internal/seed"
 	"go-mygram/internal/service"
 	"go-mygram/pkg"
+	"go-mygram/pkg/cache"
 	"go-mygram/pkg/helper"
+	"go-mygram/pkg/idempotency"
+	"go-mygram/pkg/logger"
+	"go-mygram/pkg/mailer"
+	"go-mygram/pkg/metrics"
+	"go-mygram/pkg/ratelimit"
+	"go-mygram/pkg/storage"
+	"go-mygram/pkg/tokenstore"
+	"go-mygram/pkg/webhook"
 
 	"github.com/gin-gonic/gin"
-
-	_ "go-mygram/docs"
+	"github.com/redis/go-redis/v9"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -32,15 +53,147 @@ import (
 // @license.name	Apache 2.0
 // @license.url	http://www.apache.org/licenses/LICENSE-2.0.html
 // @host			localhost:3000
-// @BasePath		/
+// @BasePath		/api/v1
 // @schemes		http
+//
+// @securityDefinitions.apikey	BearerAuth
+// @in							header
+// @name						Authorization
+// @description				"Bearer " followed by the access token
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		// `go-mygram migrate` applies pending migrations and exits
+		case "migrate":
+			db := infrastructure.NewGormPostgres(config.Load().Database)
+			defer db.Close()
+			if err := migrate(context.Background(), db); err != nil {
+				log.Fatalf("failed to migrate: %v", err)
+			}
+			return
+		// `go-mygram seed` fills a local database with sample data
+		case "seed":
+			if err := runSeed(context.Background()); err != nil {
+				log.Fatalf("failed to seed: %v", err)
+			}
+			return
+		}
+	}
 	server()
 }
 
+func runSeed(ctx context.Context) error {
+	cfg := config.Load()
+	model.MinPasswordLength = cfg.Password.MinLength
+	model.AllowedEmailDomains = cfg.Account.AllowedEmailDomains
+	jwtManager, err := helper.NewJWTManager(cfg.JWT)
+	if err != nil {
+		return err
+	}
+
+	db := infrastructure.NewGormPostgres(cfg.Database)
+	defer db.Close()
+
+	photoRepo := repository.NewPhotoRepository(db)
+	userSvc := service.NewUserService(repository.NewUserQuery(db), repository.NewFollowRepository(db), repository.NewTransactor(db), repository.NewRefreshTokenRepository(db), tokenstore.NewMemoryStore(), cfg.Token, cfg.Password, cfg.SignIn, jwtManager, newStorage(cfg.Storage), repository.NewEmailVerificationRepository(db), repository.NewPasswordResetRepository(db), mailer.NewLogSender(slog.Default()), cfg.Email, cache.NewMemoryCache(), cfg.Cache, webhook.NewNopWebhook())
+	photoSvc := service.NewPhotoService(photoRepo, repository.NewUserQuery(db), repository.NewLikeRepository(db), repository.NewFollowRepository(db), newStorage(cfg.Storage), cfg.Photo)
+	commentSvc := service.NewCommentService(repository.NewCommentRepository(db), photoRepo, repository.NewFollowRepository(db), cfg.Comment)
+
+	return seed.NewSeeder(cfg.Env, userSvc, photoSvc, commentSvc).Seed(ctx)
+}
+
+// newStorage returns the backend picked by cfg.Driver, Validate already
+// rejected unknown drivers
+func newStorage(cfg config.StorageConfig) storage.Storage {
+	return storage.NewLocalStorage(cfg.LocalDir, cfg.BaseURL)
+}
+
+func newTokenStore(cfg config.TokenStoreConfig) (tokenstore.Store, error) {
+	if cfg.Driver != config.TokenStoreRedis {
+		return tokenstore.NewMemoryStore(), nil
+	}
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	return tokenstore.NewRedisStore(redis.NewClient(opts), cfg.KeyPrefix), nil
+}
+
+func newEmailSender(cfg config.EmailConfig, logger *slog.Logger) mailer.EmailSender {
+	if cfg.Driver != config.EmailDriverSMTP {
+		return mailer.NewLogSender(logger)
+	}
+	return mailer.NewSMTPSender(mailer.SMTPConfig{
+		Host:     cfg.SMTP.Host,
+		Port:     cfg.SMTP.Port,
+		Username: cfg.SMTP.Username,
+		Password: cfg.SMTP.Password,
+		From:     cfg.From,
+		Timeout:  cfg.SMTP.Timeout,
+	})
+}
+
+func migrate(ctx context.Context, db infrastructure.GormPostgres) error {
+	// a migration may take longer than a request is allowed to
+	applied, err := migration.NewMigrator(db.GetConnection()).Up(infrastructure.WithoutQueryTimeout(ctx))
+	if err != nil {
+		return err
+	}
+	log.Printf("%d migration(s) applied", len(applied))
+	return nil
+}
+
 func server() {
-	g := gin.Default()
-	g.Use(gin.Recovery())
+	// SIGINT/SIGTERM cancel ctx, which stops background jobs and starts the
+	// graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid config: %v", err)
+	}
+	model.MinPasswordLength = cfg.Password.MinLength
+	model.AllowedEmailDomains = cfg.Account.AllowedEmailDomains
+
+	jwtManager, err := helper.NewJWTManager(cfg.JWT)
+	if err != nil {
+		log.Fatalf("failed to set up jwt: %v", err)
+	}
+
+	appLogger := logger.New(os.Stdout, cfg.LogLevel)
+	// the standard log package now goes through the same json handler
+	slog.SetDefault(appLogger)
+
+	// gin's debug mode and server errors showing their cause are both opt in
+	if cfg.VerboseErrors {
+		gin.SetMode(gin.DebugMode)
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	pkg.VerboseErrors = cfg.VerboseErrors
+
+	g := gin.New()
+	// handlers pass *gin.Context down as the context, this makes it report
+	// the request deadline and cancellation to the database calls
+	g.ContextWithFallback = true
+	if err := middleware.TrustProxies(g, cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+	g.Use(middleware.RequestID())
+	g.Use(middleware.RequestLogger(appLogger))
+	// inside the logger so a recovered panic is logged as the 500 it became
+	g.Use(middleware.Recovery(appLogger))
+	g.Use(middleware.BodyLimit(cfg.Server.MaxBodyBytes))
+	if cfg.Metrics.Enabled {
+		g.Use(middleware.Metrics())
+		g.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+	// before any route group so preflight requests never reach auth
+	g.Use(middleware.CORS(cfg.CORS))
+	if cfg.Compress.Enabled {
+		g.Use(middleware.Compress(cfg.Compress))
+	}
 
 	// /public => generate JWT public
 	g.GET("/public", func(ctx *gin.Context) {
@@ -48,56 +201,186 @@ func server() {
 
 		claim := model.StandardClaim{
 			Jti: fmt.Sprintf("%v", time.Now().UnixNano()),
-			Iss: "go-middleware",
-			Aud: "golang-006",
 			Sub: "public-token",
 			Exp: uint64(now.Add(time.Hour).Unix()),
 			Iat: uint64(now.Unix()),
 			Nbf: uint64(now.Unix()),
 		}
-		token, err := helper.GenerateToken(claim)
+		token, err := jwtManager.GenerateToken(claim)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, pkg.ErrorResponse{
-				Message: "error generating public token",
-				Errors:  []string{err.Error()},
-			})
+			pkg.WriteError(ctx, http.StatusInternalServerError, "error generating public token", err.Error())
 			return
 		}
 		ctx.JSON(http.StatusOK, map[string]any{"token": token})
 	})
 
-	usersGroup := g.Group("/api")
+	api := g.Group(cfg.Server.BasePath)
+	if cfg.CSRF.Enabled {
+		// guards every unsafe api route, bearer token requests pass through
+		api.Use(middleware.CSRF(cfg.CSRF))
+	}
 
-	gorm := infrastructure.NewGormPostgres()
+	// revoked access tokens, expired entries are purged periodically
+	tokenStore, err := newTokenStore(cfg.TokenStore)
+	if err != nil {
+		log.Fatalf("failed to set up token store: %v", err)
+	}
+	tokenstore.StartCleanup(ctx, tokenStore, 10*time.Minute)
+
+	gorm := infrastructure.NewGormPostgres(cfg.Database)
+	if cfg.Database.AutoMigrate {
+		if err := migrate(ctx, gorm); err != nil {
+			log.Fatalf("failed to migrate: %v", err)
+		}
+	}
 	userRepo := repository.NewUserQuery(gorm)
-	userSvc := service.NewUserService(userRepo)
-	userHdl := handler.NewUserHandler(userSvc)
-	userRouter := router.NewUserRouter(usersGroup, userHdl)
+	followRepo := repository.NewFollowRepository(gorm)
+	authMdw := middleware.NewAuthMiddleware(jwtManager, tokenStore, userRepo)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(gorm)
+	fileStorage := newStorage(cfg.Storage)
+	// only read when CACHE_ENABLED is set
+	userListCache := cache.NewMemoryCache()
+	userListCache.StartCleanup(ctx, 10*time.Minute)
+	var signUpHook webhook.Webhook = webhook.NewNopWebhook()
+	if cfg.Webhook.SignUpURL != "" {
+		httpHook := webhook.NewHTTPWebhook(webhook.Config{
+			URL:          cfg.Webhook.SignUpURL,
+			Timeout:      cfg.Webhook.Timeout,
+			MaxRetries:   cfg.Webhook.MaxRetries,
+			RetryBackoff: cfg.Webhook.RetryBackoff,
+		}, appLogger)
+		// let deliveries still retrying finish once the server stopped
+		defer httpHook.Wait()
+		signUpHook = httpHook
+	}
+	// neither the sign up nor the reset request waits for the provider, the
+	// reset would otherwise answer slower for registered emails
+	emailSender := mailer.NewAsyncSender(newEmailSender(cfg.Email, appLogger), appLogger)
+	defer emailSender.Wait()
+	userSvc := service.NewUserService(userRepo, followRepo, repository.NewTransactor(gorm), refreshTokenRepo, tokenStore, cfg.Token, cfg.Password, cfg.SignIn, jwtManager, fileStorage, repository.NewEmailVerificationRepository(gorm), repository.NewPasswordResetRepository(gorm), emailSender, cfg.Email, userListCache, cfg.Cache, signUpHook)
+	userHdl := handler.NewUserHandler(userSvc, cfg.Avatar.MaxBytes)
+	signInLimiter := ratelimit.NewMemoryLimiter(cfg.SignIn.RateLimitAttempts, cfg.SignIn.RateLimitWindow)
+	usernameCheckLimiter := ratelimit.NewMemoryLimiter(cfg.SignIn.UsernameCheckAttempts, cfg.SignIn.UsernameCheckWindow)
+	userSearchLimiter := ratelimit.NewMemoryLimiter(cfg.SignIn.UserSearchAttempts, cfg.SignIn.UserSearchWindow)
+	for _, limiter := range []*ratelimit.MemoryLimiter{signInLimiter, usernameCheckLimiter, userSearchLimiter} {
+		limiter.StartCleanup(ctx, 10*time.Minute)
+	}
+	userRouter := router.NewUserRouter(api, userHdl, authMdw, signInLimiter, usernameCheckLimiter, userSearchLimiter, middleware.BodyLimit(handler.UploadBodyLimit(cfg.Avatar.MaxBytes)))
+
+	// soft deleted accounts are purged once the retention period has passed
+	go purgeDeletedUsers(ctx, userSvc, cfg.Account.DeletedRetention, time.Hour)
 
 	userRouter.Mount()
 
+	followSvc := service.NewFollowService(followRepo, userRepo)
+	followHdl := handler.NewFollowHandler(followSvc)
+	followRouter := router.NewFollowRouter(api, followHdl, authMdw)
+
+	followRouter.Mount()
+
 	photoRepo := repository.NewPhotoRepository(gorm)
-	photoSvc := service.NewPhotoService(photoRepo)
-	photoHdl := handler.NewPhotoHandler(photoSvc)
-	photoRouter := router.NewPhotoRouter(usersGroup, photoHdl)
+	likeRepo := repository.NewLikeRepository(gorm)
+	photoSvc := service.NewPhotoService(photoRepo, userRepo, likeRepo, followRepo, fileStorage, cfg.Photo)
+	photoHdl := handler.NewPhotoHandler(photoSvc, cfg.Photo.MaxUploadBytes)
+	// retried creates replay their first response instead of adding a copy
+	idempotencyStore := idempotency.NewMemoryStore()
+	idempotencyStore.StartCleanup(ctx, 10*time.Minute)
+	idempotent := middleware.Idempotency(idempotencyStore, cfg.IdempotencyKeyTTL, cfg.IdempotencyLockTTL)
+
+	photoRouter := router.NewPhotoRouter(api, photoHdl, authMdw, idempotent, middleware.BodyLimit(handler.UploadBodyLimit(cfg.Photo.MaxUploadBytes)))
 
 	photoRouter.Mount()
 
-	messageRepo := repository.NewMessageRepository(gorm)
-	messageSvc := service.NewMessageService(messageRepo)
-	messageHdl := handler.NewMessageHandler(messageSvc)
-	messageRouter := router.NewMessageRouter(usersGroup, messageHdl)
+	commentRepo := repository.NewCommentRepository(gorm)
+	commentSvc := service.NewCommentService(commentRepo, photoRepo, followRepo, cfg.Comment)
+	commentHdl := handler.NewCommentHandler(commentSvc)
+	commentRouter := router.NewCommentRouter(api, commentHdl, authMdw, idempotent)
 
-	messageRouter.Mount()
+	commentRouter.Mount()
+
+	feedSvc := service.NewFeedService(photoRepo, userRepo, commentRepo, likeRepo)
+	feedHdl := handler.NewFeedHandler(feedSvc)
+	feedRouter := router.NewFeedRouter(api, feedHdl, authMdw)
+
+	feedRouter.Mount()
+
+	reportSvc := service.NewReportService(repository.NewReportRepository(gorm), commentRepo, photoSvc)
+	reportHdl := handler.NewReportHandler(reportSvc)
+	reportRouter := router.NewReportRouter(api, reportHdl, authMdw)
+
+	reportRouter.Mount()
 
 	sosmedRepo := repository.NewSocialMediaRepository(gorm)
 	sosmedSvc := service.NewSocialMediaService(sosmedRepo)
 	sosmedHdl := handler.NewSocialMediaHandler(sosmedSvc)
-	sosmedRouter := router.NewSocialMediaRouter(usersGroup, sosmedHdl)
+	sosmedRouter := router.NewSocialMediaRouter(api, sosmedHdl, authMdw)
 
 	sosmedRouter.Mount()
 
+	healthHdl := handler.NewHealthHandler(gorm, cfg.Health.DBTimeout)
+	// under the prefix like every other route, so the docs list them where
+	// they are served
+	healthRouter := router.NewHealthRouter(api, healthHdl, cfg.Health.DebugEnabled)
+
+	healthRouter.Mount()
+
+	// the annotations assume the default prefix
+	docs.SwaggerInfo.BasePath = cfg.Server.BasePath
+	if docs.SwaggerInfo.BasePath == "" {
+		docs.SwaggerInfo.BasePath = "/"
+	}
 	g.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// uploaded files are public like any profile picture url, an absolute
+	// base url means something else serves them
+	if strings.HasPrefix(cfg.Storage.BaseURL, "/") {
+		g.Static(cfg.Storage.BaseURL, cfg.Storage.LocalDir)
+	}
 
-	g.Run(":3000")
+	srv := &http.Server{Addr: cfg.Server.Addr, Handler: g}
+	if err := serve(ctx, srv, cfg.Server.ShutdownTimeout); err != nil {
+		log.Printf("server stopped with error: %v", err)
+	}
+
+	// only close the pool once no request can still be using it
+	if err := gorm.Close(); err != nil {
+		log.Printf("failed to close database: %v", err)
+	}
+}
+
+// serve runs srv until ctx is cancelled, then waits up to timeout for
+// in-flight requests to finish
+func serve(ctx context.Context, srv *http.Server, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+func purgeDeletedUsers(ctx context.Context, svc service.UserService, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := svc.PurgeDeletedUsers(ctx, time.Now().Add(-retention)); err != nil {
+			log.Printf("failed to purge deleted users: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeWaitsForInFlightRequests(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	started := make(chan struct{})
+	mux := http.NewServeMux()
+	// stands in for a slow sign in, e.g. comparing bcrypt hashes
+	mux.HandleFunc("/users/login", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, "signed in")
+	})
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, srv, 5*time.Second) }()
+
+	// wait for the listener before firing the request
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	respCh := make(chan string, 1)
+	go func() {
+		resp, err := http.Post("http://"+addr+"/users/login", "application/json", nil)
+		if err != nil {
+			respCh <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respCh <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, "signed in", <-respCh)
+	assert.Nil(t, <-done)
+}
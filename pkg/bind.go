@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// report the json name of a field, not the go one
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return f.Name
+			}
+			return name
+		})
+	}
+}
+
+// Validatable is a request body with rules beyond its binding tags
+type Validatable interface {
+	Validate() error
+}
+
+// BindJSON binds the json body into obj and checks its binding tags, on
+// failure the 400 is written and false returned. The body is kept on the
+// context so a later call can bind it again
+func BindJSON(ctx *gin.Context, obj any) bool {
+	if err := ctx.ShouldBindBodyWith(obj, binding.JSON); err != nil {
+		WriteBindError(ctx, err)
+		return false
+	}
+	return true
+}
+
+// BindAndValidate is BindJSON followed by the Validate of the body, the
+// handler goes on only when it returns true
+func BindAndValidate[T Validatable](ctx *gin.Context, obj *T) bool {
+	if !BindJSON(ctx, obj) {
+		return false
+	}
+	if err := (*obj).Validate(); err != nil {
+		WriteValidationError(ctx, err)
+		return false
+	}
+	return true
+}
+
+// StructErrors checks the binding tags of obj the way a bind does, for
+// values that don't come from a request body
+func StructErrors(obj any) ValidationErrors {
+	err := binding.Validator.ValidateStruct(obj)
+	if err == nil {
+		return nil
+	}
+	if verrs, ok := tagErrors(err); ok {
+		return verrs
+	}
+	return ValidationErrors{{Message: err.Error()}}
+}
+
+// tagErrors converts the errors of the binding tags to one FieldError per
+// broken rule
+func tagErrors(err error) (ValidationErrors, bool) {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil, false
+	}
+	verrs := make(ValidationErrors, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		verrs.Add(fe.Field(), tagMessage(fe))
+	}
+	return verrs, true
+}
+
+func tagMessage(fe validator.FieldError) string {
+	field, param := fe.Field(), fe.Param()
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	}
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return "invalid " + field
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", field, param, unit)
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", field, param, unit)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.ReplaceAll(param, " ", ", "))
+	default:
+		return "invalid " + field
+	}
+}
@@ -0,0 +1,62 @@
+package pkg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WriteBindError writes a 400 for a failed ShouldBindJSON, type mismatches
+// and broken binding tags are reported per field instead of leaking the
+// decoder message. A body cut off by middleware.BodyLimit is a 413
+func WriteBindError(ctx *gin.Context, err error) {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var maxErr *http.MaxBytesError
+	if verrs, ok := tagErrors(err); ok {
+		WriteValidationError(ctx, verrs)
+		return
+	}
+	switch {
+	case errors.As(err, &maxErr):
+		WriteError(ctx, http.StatusRequestEntityTooLarge, "request body is too large", fmt.Sprintf("must be at most %d bytes", maxErr.Limit))
+	case errors.As(err, &typeErr):
+		var verrs ValidationErrors
+		verrs.Add(typeErr.Field, fmt.Sprintf("field %s expected type %s", typeErr.Field, jsonTypeName(typeErr.Type)))
+		WriteValidationError(ctx, verrs)
+	case errors.As(err, &syntaxErr):
+		WriteError(ctx, http.StatusBadRequest, "malformed json body", fmt.Sprintf("syntax error at offset %d", syntaxErr.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		WriteError(ctx, http.StatusBadRequest, "malformed json body", "unexpected end of input")
+	case errors.Is(err, io.EOF):
+		WriteError(ctx, http.StatusBadRequest, "request body is empty")
+	default:
+		WriteError(ctx, http.StatusBadRequest, err.Error())
+	}
+}
+
+// jsonTypeName names a go type the way an api consumer knows it
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
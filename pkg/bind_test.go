@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindBody struct {
+	Name    string `json:"name" binding:"required"`
+	Confirm string `json:"confirm"`
+}
+
+func (b bindBody) Validate() error {
+	var verrs ValidationErrors
+	if b.Confirm != b.Name {
+		verrs.Add("confirm", "confirm must match name")
+	}
+	return verrs.Err()
+}
+
+func TestBindAndValidate(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		body  string
+		ok    bool
+		field string
+	}{
+		{desc: "success", body: `{"name":"bob","confirm":"bob"}`, ok: true},
+		{desc: "error binding tag", body: `{"confirm":"bob"}`, field: "name"},
+		{desc: "error validate", body: `{"name":"bob","confirm":"alice"}`, field: "confirm"},
+		{desc: "error type mismatch", body: `{"name":1}`, field: "name"},
+		{desc: "error empty body", body: ``},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tC.body))
+
+			var body bindBody
+			ok := BindAndValidate(g, &body)
+
+			assert.Equal(t, tC.ok, ok)
+			if tC.ok {
+				assert.Equal(t, bindBody{Name: "bob", Confirm: "bob"}, body)
+				return
+			}
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			if tC.field != "" {
+				var resp ValidationErrorResponse
+				assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tC.field, resp.Errors[0].Field)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go-mygram/pkg"
+)
+
+// Cache keeps encoded values for a while. MemoryCache is the only
+// implementation for now, a shared one such as Redis only has to satisfy
+// the same methods
+type Cache interface {
+	// Get returns the value of key, ok is false when it is missing or expired
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeletePrefix drops every key starting with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache is a Cache local to the process, instances behind a load
+// balancer don't see each other's invalidations
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]entry{}}
+}
+
+func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = entry{value: value, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *MemoryCache) DeletePrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+// Cleanup drops expired keys
+func (m *MemoryCache) Cleanup() {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, e := range m.entries {
+		if now.After(e.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// StartCleanup runs Cleanup every interval until ctx is done
+func (m *MemoryCache) StartCleanup(ctx context.Context, interval time.Duration) {
+	pkg.RunEvery(ctx, interval, m.Cleanup)
+}
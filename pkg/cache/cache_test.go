@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	assert.Nil(t, c.Set(ctx, "users:1", []byte("a"), time.Minute))
+	assert.Nil(t, c.Set(ctx, "users:2", []byte("b"), time.Minute))
+	assert.Nil(t, c.Set(ctx, "photos:1", []byte("c"), time.Minute))
+	assert.Nil(t, c.Set(ctx, "stale", []byte("d"), -time.Second))
+
+	value, ok, err := c.Get(ctx, "users:1")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), value)
+
+	_, ok, _ = c.Get(ctx, "stale")
+	assert.False(t, ok)
+
+	assert.Nil(t, c.DeletePrefix(ctx, "users:"))
+	_, ok, _ = c.Get(ctx, "users:2")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "photos:1")
+	assert.True(t, ok)
+}
@@ -0,0 +1,60 @@
+package pkg
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the last item of a page. Lists using it are ordered by
+// created_at then id, so items inserted after the first page was read never
+// shift the following pages the way an offset does. The id breaks the ties
+// of items created at the same instant, repositories must compare the
+// whole (created_at, id) tuple so no item is skipped or repeated
+type Cursor struct {
+	// lists ranked by a count such as likes put it first, it is 0 otherwise
+	Score     int64     `json:"score,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ID        uint64    `json:"id"`
+}
+
+// Encode returns the opaque form handed to clients
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == 0 || c.CreatedAt.IsZero() {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+type CursorPage[T any] struct {
+	Data []T `json:"data"`
+	// empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewCursorPage expects items to be fetched with limit+1, the extra item
+// is dropped and only tells that another page follows
+func NewCursorPage[T any](items []T, limit int, cursorOf func(T) Cursor) CursorPage[T] {
+	page := CursorPage[T]{Data: items}
+	if len(items) > limit {
+		page.Data = items[:limit]
+		page.NextCursor = cursorOf(page.Data[limit-1]).Encode()
+	}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	return page
+}
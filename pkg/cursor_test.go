@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("success round trip", func(t *testing.T) {
+		c := Cursor{CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 123456000, time.UTC), ID: 42}
+		decoded, err := DecodeCursor(c.Encode())
+		assert.Nil(t, err)
+		assert.True(t, c.CreatedAt.Equal(decoded.CreatedAt))
+		assert.Equal(t, c.ID, decoded.ID)
+	})
+
+	t.Run("error invalid cursor", func(t *testing.T) {
+		for _, s := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+			_, err := DecodeCursor(s)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		}
+	})
+}
+
+func TestNewCursorPage(t *testing.T) {
+	cursorOf := func(id uint64) Cursor {
+		return Cursor{CreatedAt: time.Unix(int64(id), 0), ID: id}
+	}
+
+	t.Run("success last page", func(t *testing.T) {
+		page := NewCursorPage([]uint64{3, 2}, 2, cursorOf)
+		assert.Equal(t, []uint64{3, 2}, page.Data)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("success empty page", func(t *testing.T) {
+		page := NewCursorPage[uint64](nil, 2, cursorOf)
+		assert.Equal(t, []uint64{}, page.Data)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("success more pages", func(t *testing.T) {
+		page := NewCursorPage([]uint64{5, 4, 3}, 2, cursorOf)
+		assert.Equal(t, []uint64{5, 4}, page.Data)
+		next, err := DecodeCursor(page.NextCursor)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(4), next.ID)
+	})
+}
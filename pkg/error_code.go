@@ -0,0 +1,79 @@
+package pkg
+
+import "net/http"
+
+// error codes clients can branch on, Message stays free to change. The
+// generic ones follow the status, the rest name a specific failure
+const (
+	CodeBadRequest           = "BAD_REQUEST"
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeConflict             = "CONFLICT"
+	CodePreconditionFailed   = "PRECONDITION_FAILED"
+	CodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeLocked               = "LOCKED"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodeInternal             = "INTERNAL_ERROR"
+	CodeUnavailable          = "SERVICE_UNAVAILABLE"
+	CodeTimeout              = "TIMEOUT"
+
+	CodeTokenExpired        = "TOKEN_EXPIRED"
+	CodeTokenRevoked        = "TOKEN_REVOKED"
+	CodeInvalidToken        = "INVALID_TOKEN"
+	CodeInvalidCredentials  = "INVALID_CREDENTIALS"
+	CodeAccountLocked       = "ACCOUNT_LOCKED"
+	CodeEmailNotVerified    = "EMAIL_NOT_VERIFIED"
+	CodeWrongPassword       = "WRONG_PASSWORD"
+	CodePasswordUnchanged   = "PASSWORD_UNCHANGED"
+	CodeUserNotFound        = "USER_NOT_FOUND"
+	CodeEmailTaken          = "EMAIL_TAKEN"
+	CodeUsernameTaken       = "USERNAME_TAKEN"
+	CodeVersionConflict     = "VERSION_CONFLICT"
+	CodeFollowSelf          = "FOLLOW_SELF"
+	CodePhotoNotFound       = "PHOTO_NOT_FOUND"
+	CodePhotoNotVisible     = "PHOTO_NOT_VISIBLE"
+	CodePhotoLimitReached   = "PHOTO_LIMIT_REACHED"
+	CodeCommentNotFound     = "COMMENT_NOT_FOUND"
+	CodeEditWindowExpired   = "EDIT_WINDOW_EXPIRED"
+	CodeSocialMediaNotFound = "SOCIAL_MEDIA_NOT_FOUND"
+	CodeNotOwner            = "NOT_OWNER"
+	CodeUnsupportedImage    = "UNSUPPORTED_IMAGE_TYPE"
+)
+
+// CodeForStatus is the code of an error nothing more specific is known
+// about
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusLocked:
+		return CodeLocked
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
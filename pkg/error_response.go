@@ -1,6 +1,44 @@
 package pkg
 
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// VerboseErrors sends the cause of a server error to the client together
+// with a stack trace. It is only set at startup when VERBOSE_ERRORS or
+// ENV=development asks for it, otherwise the client only gets a generic
+// message and the cause is left to the logs
+var VerboseErrors = false
+
 type ErrorResponse struct {
-	Message string   `json:"message"`
-	Errors  []string `json:"errors,omitempty"`
+	// Code is one of the Code constants, Message is meant for humans
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	Errors    []string `json:"errors,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+	// only filled when VerboseErrors is set
+	Debug *ErrorDebug `json:"debug,omitempty"`
+}
+
+// ErrorDebug exposes the internals behind a server error
+type ErrorDebug struct {
+	Error string   `json:"error"`
+	Stack []string `json:"stack,omitempty"`
+}
+
+// NewErrorDebug describes cause with the stack of the caller, it returns
+// nil unless VerboseErrors is set
+func NewErrorDebug(cause string) *ErrorDebug {
+	if !VerboseErrors {
+		return nil
+	}
+	lines := strings.Split(string(debug.Stack()), "\n")
+	stack := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			stack = append(stack, line)
+		}
+	}
+	return &ErrorDebug{Error: cause, Stack: stack}
 }
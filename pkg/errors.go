@@ -0,0 +1,48 @@
+package pkg
+
+import (
+	"errors"
+	"net/http"
+)
+
+// kinds of failure a service error can belong to, the specific sentinels of
+// the services wrap one of them with NewError so callers can translate them
+// without knowing each one
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+)
+
+type kindError struct {
+	kind    error
+	message string
+}
+
+func (e *kindError) Error() string { return e.message }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// NewError returns an error reading message that matches kind with
+// errors.Is, e.g. NewError(ErrNotFound, "user not found")
+func NewError(kind error, message string) error {
+	return &kindError{kind: kind, message: message}
+}
+
+// HTTPStatusFromError is the status err is reported with, an error of no
+// kind is a 500
+func HTTPStatusFromError(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
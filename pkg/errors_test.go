@@ -0,0 +1,35 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPStatusFromError(t *testing.T) {
+	var verrs ValidationErrors
+	verrs.Add("email", "invalid email")
+
+	testCases := []struct {
+		desc   string
+		err    error
+		status int
+	}{
+		{desc: "not found", err: NewError(ErrNotFound, "user not found"), status: http.StatusNotFound},
+		{desc: "unauthorized", err: NewError(ErrUnauthorized, "invalid email or password"), status: http.StatusUnauthorized},
+		{desc: "wrapped conflict", err: fmt.Errorf("sign up: %w", NewError(ErrConflict, "email already registered")), status: http.StatusConflict},
+		{desc: "validation errors", err: verrs, status: http.StatusBadRequest},
+		{desc: "no kind", err: errors.New("connection refused"), status: http.StatusInternalServerError},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.status, HTTPStatusFromError(tC.err))
+		})
+	}
+
+	// the kind doesn't leak into the message
+	assert.Equal(t, "user not found", NewError(ErrNotFound, "user not found").Error())
+}
@@ -0,0 +1,54 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WriteJSONWithETag writes body as json together with an ETag of the
+// serialized body. The tag covers every field, so it also changes when
+// computed fields such as like counts do. A request whose If-None-Match
+// matches only gets a 304
+func WriteJSONWithETag(ctx *gin.Context, status int, body any) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		WriteError(ctx, http.StatusInternalServerError, "failed to encode response")
+		return
+	}
+	etag := ETag(raw)
+	ctx.Header("ETag", etag)
+	if etagMatches(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.Status(http.StatusNotModified)
+		ctx.Writer.WriteHeaderNow()
+		return
+	}
+	ctx.Data(status, "application/json; charset=utf-8", raw)
+}
+
+// WriteSuccessWithETag is WriteSuccess with ETag support
+func WriteSuccessWithETag(ctx *gin.Context, status int, data any) {
+	WriteJSONWithETag(ctx, status, SuccessResponse{Data: data})
+}
+
+// ETag returns a strong entity tag for data
+func ETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches implements the If-None-Match comparison, which is weak so a
+// W/ prefix sent back by a proxy still matches
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
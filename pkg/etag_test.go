@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJSONWithETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := map[string]any{"id": 1, "updated_at": "2024-05-01T00:00:00Z"}
+
+	r := gin.New()
+	r.GET("/thing", func(ctx *gin.Context) {
+		WriteJSONWithETag(ctx, http.StatusOK, body)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/thing", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	testCases := []struct {
+		desc        string
+		ifNoneMatch string
+		code        int
+	}{
+		{desc: "matching tag", ifNoneMatch: etag, code: http.StatusNotModified},
+		{desc: "weak matching tag in a list", ifNoneMatch: `"other", W/` + etag, code: http.StatusNotModified},
+		{desc: "wildcard", ifNoneMatch: "*", code: http.StatusNotModified},
+		{desc: "stale tag", ifNoneMatch: `"stale"`, code: http.StatusOK},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/thing", nil)
+			req.Header.Set("If-None-Match", tC.ifNoneMatch)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tC.code, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			if tC.code == http.StatusNotModified {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ParseFields reads ?fields, a comma separated list of the json keys the
+// client wants back. It returns nil when the param is missing, meaning
+// every field, and an error naming the first key allowed doesn't list
+func ParseFields(ctx *gin.Context, allowed []string) ([]string, error) {
+	raw := ctx.Query("fields")
+	if raw == "" {
+		return nil, nil
+	}
+	fields := []string{}
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(allowed, field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must not be empty")
+	}
+	return fields, nil
+}
+
+// JSONFields lists the json keys of the struct v, fields of embedded
+// structs included
+func JSONFields(v any) []string {
+	return jsonFields(reflect.TypeOf(v))
+}
+
+func jsonFields(t reflect.Type) []string {
+	fields := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(f.Type)...)
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// SelectFields serializes item and keeps only fields. The values are left
+// as raw json so ids don't go through a float64
+func SelectFields[T any](item T, fields []string) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	selected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected, nil
+}
+
+// SelectFieldsAll is SelectFields for every item
+func SelectFieldsAll[T any](items []T, fields []string) ([]map[string]json.RawMessage, error) {
+	selected := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		s, err := SelectFields(item, fields)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, s)
+	}
+	return selected, nil
+}
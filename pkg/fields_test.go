@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldsBase struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+type fieldsItem struct {
+	fieldsBase
+	Secret string `json:"-"`
+	Note   string `json:"note,omitempty"`
+}
+
+func TestJSONFields(t *testing.T) {
+	assert.Equal(t, []string{"id", "name", "note"}, JSONFields(fieldsItem{}))
+}
+
+func TestParseFields(t *testing.T) {
+	allowed := JSONFields(fieldsItem{})
+	testCases := []struct {
+		desc   string
+		query  string
+		fields []string
+		err    string
+	}{
+		{desc: "success missing param selects everything", query: ""},
+		{desc: "success trims and drops duplicates", query: "?fields=id,%20name,id", fields: []string{"id", "name"}},
+		{desc: "error unknown field", query: "?fields=id,password", err: `unknown field "password"`},
+		{desc: "error hidden field", query: "?fields=Secret", err: `unknown field "Secret"`},
+		{desc: "error only commas", query: "?fields=,,", err: "fields must not be empty"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			g, _ := gin.CreateTestContext(httptest.NewRecorder())
+			g.Request = httptest.NewRequest("GET", "/users"+tC.query, nil)
+
+			fields, err := ParseFields(g, allowed)
+			if tC.err != "" {
+				assert.EqualError(t, err, tC.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tC.fields, fields)
+		})
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	items := []fieldsItem{{fieldsBase: fieldsBase{ID: 1<<60 + 1, Name: "alice"}, Note: "hi"}}
+
+	selected, err := SelectFieldsAll(items, []string{"id", "note"})
+	assert.Nil(t, err)
+	raw, _ := json.Marshal(selected)
+	assert.JSONEq(t, `[{"id":1152921504606846977,"note":"hi"}]`, string(raw))
+}
@@ -1,67 +1,64 @@
 package helper
 
 import (
-	"encoding/json"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"log"
 
-	"github.com/dgrijalva/jwt-go"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const (
-	SECRET_JWT = "mysecretjwtdontsharethistoanyoneelse"
-)
+func GenerateHash(in string) (out string, err error) {
+	return GenerateHashWithCost(in, bcrypt.DefaultCost)
+}
 
-func GenerateToken(claim any) (token string, err error) {
-	jwtClaim := jwt.MapClaims{}
-	b, err := json.Marshal(claim)
-	if err != nil {
-		log.Println("cannot marshal claim payload")
-		return
-	}
-	err = json.Unmarshal(b, &jwtClaim)
+// GenerateHashWithCost hashes with the given bcrypt cost, costs below the
+// bcrypt minimum fall back to the default
+func GenerateHashWithCost(in string, cost int) (out string, err error) {
+	outByte, err := bcrypt.GenerateFromPassword([]byte(in), cost)
 	if err != nil {
-		log.Println("cannot mapping claim to jwt claim")
-		return
-	}
-	// prepare
-	parseToken := jwt.NewWithClaims(jwt.SigningMethodHS512, jwtClaim)
-	// generate token
-	token, err = parseToken.SignedString([]byte(SECRET_JWT))
-	if err != nil {
-		log.Println("cannot generate token", err.Error())
+		log.Println("error generate hash password", err.Error())
 		return
 	}
-	return
+	return string(outByte), err
 }
 
-func ValidateToken(token string) (claim jwt.MapClaims, err error) {
-	jwtToken, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-
-		return []byte(SECRET_JWT), nil
-	})
+// NeedsRehash reports whether hash was made with a lower cost than cost
+func NeedsRehash(hash string, cost int) bool {
+	current, err := bcrypt.Cost([]byte(hash))
 	if err != nil {
-		log.Println("error validating jwt token", err.Error())
-		return
+		return false
 	}
+	return current < cost
+}
 
-	// translate claim
-	claim, ok := jwtToken.Claims.(jwt.MapClaims)
-	if !ok {
-		log.Println("error translate claim")
+// GenerateRandomToken returns a hex encoded random string built from n bytes
+func GenerateRandomToken(n int) (token string, err error) {
+	b := make([]byte, n)
+	if _, err = rand.Read(b); err != nil {
+		log.Println("error generate random token", err.Error())
 		return
 	}
-	return
+	return hex.EncodeToString(b), nil
 }
 
-func GenerateHash(in string) (out string, err error) {
-	outByte, err := bcrypt.GenerateFromPassword([]byte(in), bcrypt.DefaultCost)
-	if err != nil {
-		log.Println("error generate hash password", err.Error())
+// HashToken returns the sha256 hex digest of an opaque token, so only
+// the digest needs to be persisted
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateUUID returns a random (version 4) UUID string
+func GenerateUUID() (id string, err error) {
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		log.Println("error generate uuid", err.Error())
 		return
 	}
-	return string(outByte), err
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
 }
@@ -0,0 +1,119 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	ErrInvalidTokenClaims = errors.New("invalid token claims")
+	ErrTokenExpired       = errors.New("token has expired")
+)
+
+type JWTConfig struct {
+	// Algorithm is the signing algorithm, e.g. HS256
+	Algorithm string
+	Secret    string
+	Issuer    string
+	Audience  string
+}
+
+type JWTManager interface {
+	GenerateToken(claim any) (token string, err error)
+	ValidateToken(token string) (claim jwt.MapClaims, err error)
+}
+
+type jwtManagerImpl struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+	issuer    string
+	audience  string
+}
+
+// NewJWTManager builds a manager for the configured algorithm, asymmetric
+// algorithms such as RS256 would load their key pair here
+func NewJWTManager(cfg JWTConfig) (JWTManager, error) {
+	m := &jwtManagerImpl{issuer: cfg.Issuer, audience: cfg.Audience}
+	switch cfg.Algorithm {
+	case "HS256", "HS384", "HS512":
+		if cfg.Secret == "" {
+			return nil, errors.New("jwt secret is empty")
+		}
+		m.method = jwt.GetSigningMethod(cfg.Algorithm)
+		m.signKey = []byte(cfg.Secret)
+		m.verifyKey = []byte(cfg.Secret)
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+	return m, nil
+}
+
+// GenerateToken signs claim, the configured issuer and audience always
+// override whatever the claim carries
+func (m *jwtManagerImpl) GenerateToken(claim any) (token string, err error) {
+	jwtClaim := jwt.MapClaims{}
+	b, err := json.Marshal(claim)
+	if err != nil {
+		log.Println("cannot marshal claim payload")
+		return
+	}
+	err = json.Unmarshal(b, &jwtClaim)
+	if err != nil {
+		log.Println("cannot mapping claim to jwt claim")
+		return
+	}
+	if m.issuer != "" {
+		jwtClaim["iss"] = m.issuer
+	}
+	if m.audience != "" {
+		jwtClaim["aud"] = m.audience
+	}
+	// prepare
+	parseToken := jwt.NewWithClaims(m.method, jwtClaim)
+	// generate token
+	token, err = parseToken.SignedString(m.signKey)
+	if err != nil {
+		log.Println("cannot generate token", err.Error())
+		return
+	}
+	return
+}
+
+// ValidateToken checks the signature, expiry, issuer and audience
+func (m *jwtManagerImpl) ValidateToken(token string) (claim jwt.MapClaims, err error) {
+	jwtToken, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		// only accept the configured algorithm, never the one named by the token
+		if t.Method.Alg() != m.method.Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+
+		return m.verifyKey, nil
+	})
+	if err != nil {
+		log.Println("error validating jwt token", err.Error())
+		var ve *jwt.ValidationError
+		if errors.As(err, &ve) && ve.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, ErrTokenExpired
+		}
+		return
+	}
+
+	// translate claim
+	claim, ok := jwtToken.Claims.(jwt.MapClaims)
+	if !ok {
+		log.Println("error translate claim")
+		return nil, ErrInvalidTokenClaims
+	}
+	if m.issuer != "" && !claim.VerifyIssuer(m.issuer, true) {
+		return nil, ErrInvalidTokenClaims
+	}
+	if m.audience != "" && !claim.VerifyAudience(m.audience, true) {
+		return nil, ErrInvalidTokenClaims
+	}
+	return
+}
@@ -0,0 +1,72 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJWTManager(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		cfg     JWTConfig
+		wantErr bool
+	}{
+		{desc: "success hs256", cfg: JWTConfig{Algorithm: "HS256", Secret: "secret"}},
+		{desc: "error empty secret", cfg: JWTConfig{Algorithm: "HS256"}, wantErr: true},
+		{desc: "error unsupported algorithm", cfg: JWTConfig{Algorithm: "none", Secret: "secret"}, wantErr: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := NewJWTManager(tC.cfg)
+			assert.Equal(t, tC.wantErr, err != nil)
+		})
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	cfg := JWTConfig{Algorithm: "HS256", Secret: "secret", Issuer: "go-mygram", Audience: "go-mygram-api"}
+	manager, err := NewJWTManager(cfg)
+	assert.Nil(t, err)
+	claim := map[string]any{"user_id": 1, "exp": time.Now().Add(time.Hour).Unix()}
+
+	t.Run("success matching issuer and audience", func(t *testing.T) {
+		token, err := manager.GenerateToken(claim)
+		assert.Nil(t, err)
+
+		claims, err := manager.ValidateToken(token)
+		assert.Nil(t, err)
+		assert.Equal(t, "go-mygram", claims["iss"])
+	})
+
+	t.Run("error other issuer", func(t *testing.T) {
+		other, err := NewJWTManager(JWTConfig{Algorithm: "HS256", Secret: "secret", Issuer: "someone-else", Audience: cfg.Audience})
+		assert.Nil(t, err)
+		token, err := other.GenerateToken(claim)
+		assert.Nil(t, err)
+
+		_, err = manager.ValidateToken(token)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error other audience", func(t *testing.T) {
+		other, err := NewJWTManager(JWTConfig{Algorithm: "HS256", Secret: "secret", Issuer: cfg.Issuer, Audience: "another-api"})
+		assert.Nil(t, err)
+		token, err := other.GenerateToken(claim)
+		assert.Nil(t, err)
+
+		_, err = manager.ValidateToken(token)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("error other algorithm", func(t *testing.T) {
+		other, err := NewJWTManager(JWTConfig{Algorithm: "HS512", Secret: "secret", Issuer: cfg.Issuer, Audience: cfg.Audience})
+		assert.Nil(t, err)
+		token, err := other.GenerateToken(claim)
+		assert.Nil(t, err)
+
+		_, err = manager.ValidateToken(token)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,105 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-mygram/pkg"
+)
+
+var (
+	// ErrInProgress is returned while the first request with a key is
+	// still being handled
+	ErrInProgress = errors.New("a request with this idempotency key is in progress")
+	// ErrFingerprintMismatch is returned when a key is reused for a
+	// different request body
+	ErrFingerprintMismatch = errors.New("idempotency key was used for a different request")
+)
+
+// Response is what gets replayed for a repeated key
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store remembers the response of each idempotency key for a while
+type Store interface {
+	// Start reserves key for a request identified by fingerprint for up to
+	// lockTTL, so a crashed request doesn't hold the key for long. It
+	// returns the saved response when the key was already used
+	Start(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Response, error)
+	// Finish saves the response of a reserved key
+	Finish(ctx context.Context, key string, resp Response, ttl time.Duration) error
+	// Release drops a reserved key so the request can be tried again
+	Release(ctx context.Context, key string) error
+}
+
+type entry struct {
+	fingerprint string
+	resp        *Response
+	expiresAt   time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]entry{}}
+}
+
+func (m *MemoryStore) Start(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (*Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		m.entries[key] = entry{fingerprint: fingerprint, expiresAt: time.Now().Add(lockTTL)}
+		return nil, nil
+	}
+	if e.fingerprint != fingerprint {
+		return nil, ErrFingerprintMismatch
+	}
+	if e.resp == nil {
+		return nil, ErrInProgress
+	}
+	return e.resp, nil
+}
+
+func (m *MemoryStore) Finish(ctx context.Context, key string, resp Response, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entries[key]
+	e.resp = &resp
+	e.expiresAt = time.Now().Add(ttl)
+	m.entries[key] = e
+	return nil
+}
+
+func (m *MemoryStore) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// Cleanup drops expired keys
+func (m *MemoryStore) Cleanup() {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, e := range m.entries {
+		if now.After(e.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// StartCleanup runs Cleanup every interval until ctx is done
+func (m *MemoryStore) StartCleanup(ctx context.Context, interval time.Duration) {
+	pkg.RunEvery(ctx, interval, m.Cleanup)
+}
@@ -0,0 +1,90 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	resp := Response{Status: http.StatusCreated, Header: http.Header{"Location": {"/photos/1"}}, Body: []byte(`{"id":1}`)}
+
+	t.Run("success first start reserves the key", func(t *testing.T) {
+		store := NewMemoryStore()
+		saved, err := store.Start(ctx, "k", "f", time.Minute)
+		assert.Nil(t, err)
+		assert.Nil(t, saved)
+	})
+
+	t.Run("error start while in progress", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, _ = store.Start(ctx, "k", "f", time.Minute)
+
+		_, err := store.Start(ctx, "k", "f", time.Minute)
+		assert.ErrorIs(t, err, ErrInProgress)
+	})
+
+	t.Run("error start with another fingerprint", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, _ = store.Start(ctx, "k", "f", time.Minute)
+
+		_, err := store.Start(ctx, "k", "other", time.Minute)
+		assert.ErrorIs(t, err, ErrFingerprintMismatch)
+	})
+
+	t.Run("success finished key returns the response", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, _ = store.Start(ctx, "k", "f", time.Minute)
+		assert.Nil(t, store.Finish(ctx, "k", resp, time.Hour))
+
+		saved, err := store.Start(ctx, "k", "f", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, &resp, saved)
+	})
+
+	t.Run("success expired lock can be taken again", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, _ = store.Start(ctx, "k", "f", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		saved, err := store.Start(ctx, "k", "f", time.Minute)
+		assert.Nil(t, err)
+		assert.Nil(t, saved)
+	})
+
+	t.Run("success finish outlives the lock", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, _ = store.Start(ctx, "k", "f", time.Millisecond)
+		assert.Nil(t, store.Finish(ctx, "k", resp, time.Hour))
+		time.Sleep(5 * time.Millisecond)
+
+		saved, err := store.Start(ctx, "k", "f", time.Minute)
+		assert.Nil(t, err)
+		assert.Equal(t, &resp, saved)
+	})
+
+	t.Run("success released key can be retried", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, _ = store.Start(ctx, "k", "f", time.Minute)
+		assert.Nil(t, store.Release(ctx, "k"))
+
+		saved, err := store.Start(ctx, "k", "other", time.Minute)
+		assert.Nil(t, err)
+		assert.Nil(t, saved)
+	})
+
+	t.Run("success cleanup drops expired keys only", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, _ = store.Start(ctx, "old", "f", time.Millisecond)
+		_, _ = store.Start(ctx, "new", "f", time.Minute)
+		time.Sleep(5 * time.Millisecond)
+
+		store.Cleanup()
+		assert.Len(t, store.entries, 1)
+		assert.Contains(t, store.entries, "new")
+	})
+}
@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// AsyncSender hands messages to another sender in the background so the
+// caller doesn't wait on the provider. Send always succeeds, a failed
+// delivery is only logged
+type AsyncSender struct {
+	sender EmailSender
+	logger *slog.Logger
+	wg     sync.WaitGroup
+}
+
+func NewAsyncSender(sender EmailSender, logger *slog.Logger) *AsyncSender {
+	return &AsyncSender{sender: sender, logger: logger}
+}
+
+func (a *AsyncSender) Send(ctx context.Context, msg Message) error {
+	// the delivery outlives the request that triggered it
+	ctx = context.WithoutCancel(ctx)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.sender.Send(ctx, msg); err != nil {
+			a.logger.ErrorContext(ctx, "failed to send email", slog.String("subject", msg.Subject), slog.Any("error", err))
+		}
+	}()
+	return nil
+}
+
+// Wait blocks until every queued message is sent or failed
+func (a *AsyncSender) Wait() {
+	a.wg.Wait()
+}
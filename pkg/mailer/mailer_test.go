@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogSender(t *testing.T) {
+	msg := Message{To: "foo@example.com", Subject: "Reset your password", Body: "https://mygram.example/reset?token=secret"}
+
+	t.Run("success info leaves the body out", func(t *testing.T) {
+		var buf bytes.Buffer
+		sender := NewLogSender(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+		assert.Nil(t, sender.Send(context.Background(), msg))
+		assert.Contains(t, buf.String(), "foo@example.com")
+		assert.NotContains(t, buf.String(), "token=secret")
+	})
+
+	t.Run("success debug logs the body", func(t *testing.T) {
+		var buf bytes.Buffer
+		sender := NewLogSender(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+		assert.Nil(t, sender.Send(context.Background(), msg))
+		assert.Contains(t, buf.String(), "token=secret")
+	})
+}
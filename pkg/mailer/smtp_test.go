@@ -0,0 +1,125 @@
+package mailer
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTP accepts one session on a local port and records what the
+// client sent
+type fakeSMTP struct {
+	listener net.Listener
+	done     chan struct{}
+	auth     string
+	from     string
+	to       string
+	data     string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	f := &fakeSMTP{listener: listener, done: make(chan struct{})}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+	return f
+}
+
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (f *fakeSMTP) serve() {
+	defer close(f.done)
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			f.auth = arg
+			tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			f.from = arg
+			tp.PrintfLine("250 ok")
+		case "RCPT":
+			f.to = arg
+			tp.PrintfLine("250 ok")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			lines, _ := tp.ReadDotLines()
+			f.data = strings.Join(lines, "\n")
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	t.Run("success send with auth", func(t *testing.T) {
+		srv := newFakeSMTP(t)
+		sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: srv.port(), Username: "mailer", Password: "secret", From: "no-reply@mygram.example", Timeout: time.Second})
+
+		msg, err := VerifyEmailTemplate.Message("foo@example.com", LinkData{Username: "foo", Link: "https://app.example/verify?token=abc"})
+		assert.Nil(t, err)
+		assert.Nil(t, sender.Send(context.Background(), msg))
+		<-srv.done
+
+		assert.Equal(t, "PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00mailer\x00secret")), srv.auth)
+		assert.Equal(t, "FROM:<no-reply@mygram.example>", srv.from)
+		assert.Equal(t, "TO:<foo@example.com>", srv.to)
+		assert.Contains(t, srv.data, "To: foo@example.com\n")
+		assert.Contains(t, srv.data, "Subject: Verify your email\n")
+		assert.Contains(t, srv.data, "Hi foo,\n\nopen this link to verify your email:\nhttps://app.example/verify?token=abc")
+	})
+
+	t.Run("error header injection", func(t *testing.T) {
+		sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: 1, From: "no-reply@mygram.example"})
+
+		err := sender.Send(context.Background(), Message{To: "foo@example.com\r\nBcc: bar@example.com", Subject: "hi"})
+		assert.EqualError(t, err, "mailer: line break in recipient or subject")
+	})
+
+	t.Run("error server unreachable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: port, From: "no-reply@mygram.example", Timeout: time.Second})
+		assert.NotNil(t, sender.Send(context.Background(), Message{To: "foo@example.com", Subject: "hi"}))
+	})
+}
+
+func TestPasswordResetTemplate(t *testing.T) {
+	msg, err := PasswordResetTemplate.Message("foo@example.com", LinkData{Username: "foo", Link: "https://app.example/reset?token=abc"})
+	assert.Nil(t, err)
+	assert.Equal(t, Message{
+		To:      "foo@example.com",
+		Subject: "Reset your password",
+		Body:    "Hi foo,\n\nopen this link to choose a new password:\nhttps://app.example/reset?token=abc\n\nIgnore this email if you didn't ask for it.\n",
+	}, msg)
+}
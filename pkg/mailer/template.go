@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"strings"
+	"text/template"
+)
+
+// Template renders a message from text/template sources
+type Template struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// MustTemplate parses subject and body, it panics on a malformed template
+// so it is meant for package level vars
+func MustTemplate(subject, body string) Template {
+	return Template{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+// Message renders the template with data into a message for to
+func (t Template) Message(to string, data any) (Message, error) {
+	var subject, body strings.Builder
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	return Message{To: to, Subject: subject.String(), Body: body.String()}, nil
+}
+
+// LinkData fills the templates of the emails that carry a one time link
+type LinkData struct {
+	Username string
+	Link     string
+}
+
+var (
+	VerifyEmailTemplate = MustTemplate("Verify your email",
+		"Hi {{.Username}},\n\nopen this link to verify your email:\n{{.Link}}\n")
+	PasswordResetTemplate = MustTemplate("Reset your password",
+		"Hi {{.Username}},\n\nopen this link to choose a new password:\n{{.Link}}\n\nIgnore this email if you didn't ask for it.\n")
+)
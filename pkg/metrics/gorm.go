@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// RegisterGormCallbacks counts failed statements in DBErrors. A missing
+// record is an expected outcome and is not counted
+func RegisterGormCallbacks(db *gorm.DB) error {
+	record := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				DBErrors.WithLabelValues(operation).Inc()
+			}
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("metrics:create", record("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:query", record("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:update", record("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:delete", record("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:row", record("row")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:raw", record("raw"))
+}
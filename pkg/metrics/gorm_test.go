@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestRegisterGormCallbacks(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.Nil(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	assert.Nil(t, err)
+	assert.Nil(t, RegisterGormCallbacks(db))
+
+	type row struct{ ID uint64 }
+	mock.ExpectQuery(`SELECT (.+) FROM "rows"`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectQuery(`SELECT (.+) FROM "rows"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	before := testutil.ToFloat64(DBErrors.WithLabelValues("query"))
+	assert.NotNil(t, db.First(&row{}).Error)
+	// not found is not a database failure
+	assert.ErrorIs(t, db.First(&row{}).Error, gorm.ErrRecordNotFound)
+	assert.Equal(t, before+1, testutil.ToFloat64(DBErrors.WithLabelValues("query")))
+}
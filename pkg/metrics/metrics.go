@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every collector of the app, it is kept apart from the
+// prometheus default registry so tests and libraries can't leak into it
+var Registry = prometheus.NewRegistry()
+
+var (
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of handled http requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of http requests by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	RequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of http requests currently being served.",
+	})
+
+	FailedLogins = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "auth_failed_logins_total",
+		Help: "Number of sign in attempts rejected because of bad credentials.",
+	})
+
+	DBErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_errors_total",
+		Help: "Number of failed database statements by operation.",
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RequestsTotal,
+		RequestDuration,
+		RequestsInFlight,
+		FailedLogins,
+		DBErrors,
+	)
+}
+
+// Handler serves the registry in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaults for list endpoints, deployments may change them at startup
+var (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var (
+	ErrInvalidPage   = errors.New("invalid page param")
+	ErrInvalidLimit  = errors.New("invalid limit param")
+	ErrInvalidOffset = errors.New("invalid offset param")
+)
+
+// Pagination is the window of an offset based list, build it with
+// ParsePagination or NewPagination so the limit is always clamped
+type Pagination struct {
+	offset int
+	limit  int
+}
+
+// NewPagination returns the window of page, counted from 1
+func NewPagination(page, limit int) Pagination {
+	limit = clampLimit(limit)
+	return Pagination{offset: (page - 1) * limit, limit: limit}
+}
+
+// ParsePagination reads ?page or ?offset together with ?limit. A missing
+// limit falls back to DefaultPageLimit and a larger one than MaxPageLimit
+// is clamped
+func ParsePagination(ctx *gin.Context) (Pagination, error) {
+	limit, err := ParseLimit(ctx)
+	if err != nil {
+		return Pagination{}, err
+	}
+
+	rawPage, rawOffset := ctx.Query("page"), ctx.Query("offset")
+	if rawPage != "" && rawOffset != "" {
+		return Pagination{}, errors.New("use either page or offset, not both")
+	}
+	if rawOffset != "" {
+		offset, err := strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			return Pagination{}, ErrInvalidOffset
+		}
+		return Pagination{offset: offset, limit: limit}, nil
+	}
+
+	page := 1
+	if rawPage != "" {
+		page, err = strconv.Atoi(rawPage)
+		// a page whose offset doesn't fit an int would wrap around
+		if err != nil || page < 1 || page > math.MaxInt/limit {
+			return Pagination{}, ErrInvalidPage
+		}
+	}
+	return NewPagination(page, limit), nil
+}
+
+// ParseLimit reads ?limit on its own, for lists paged by cursor
+func ParseLimit(ctx *gin.Context) (int, error) {
+	raw := ctx.Query("limit")
+	if raw == "" {
+		return DefaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, ErrInvalidLimit
+	}
+	return clampLimit(limit), nil
+}
+
+func clampLimit(limit int) int {
+	if limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return limit
+}
+
+func (p Pagination) Offset() int {
+	return p.offset
+}
+
+func (p Pagination) Limit() int {
+	return p.limit
+}
+
+// Page is the 1 based page holding Offset, an offset that falls in the
+// middle of a page reports that page
+func (p Pagination) Page() int {
+	if p.limit == 0 {
+		return 1
+	}
+	return p.offset/p.limit + 1
+}
+
+type Paginated[T any] struct {
+	Data       []T   `json:"data"`
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+func NewPaginated[T any](data []T, page, limit int, total int64) Paginated[T] {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+	if data == nil {
+		data = []T{}
+	}
+	return Paginated[T]{
+		Data:       data,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
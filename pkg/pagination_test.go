@@ -0,0 +1,51 @@
+package pkg
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePagination(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		query  string
+		offset int
+		limit  int
+		page   int
+		err    bool
+	}{
+		{desc: "success defaults", query: "", offset: 0, limit: 20, page: 1},
+		{desc: "success page", query: "?page=3&limit=10", offset: 20, limit: 10, page: 3},
+		{desc: "success offset", query: "?offset=15&limit=10", offset: 15, limit: 10, page: 2},
+		{desc: "success limit clamped", query: "?limit=1000", offset: 0, limit: 100, page: 1},
+		{desc: "error page zero", query: "?page=0", err: true},
+		{desc: "error page offset overflows", query: "?page=" + strconv.Itoa(math.MaxInt/10+1) + "&limit=10", err: true},
+		{desc: "success last page that fits", query: "?page=" + strconv.Itoa(math.MaxInt/100) + "&limit=100", offset: (math.MaxInt/100 - 1) * 100, limit: 100, page: math.MaxInt / 100},
+		{desc: "error limit not a number", query: "?limit=abc", err: true},
+		{desc: "error negative offset", query: "?offset=-1", err: true},
+		{desc: "error page and offset", query: "?page=2&offset=10", err: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			g, _ := gin.CreateTestContext(httptest.NewRecorder())
+			g.Request = httptest.NewRequest(http.MethodGet, "/users"+tC.query, nil)
+
+			p, err := ParsePagination(g)
+			if tC.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tC.offset, p.Offset())
+			assert.Equal(t, tC.limit, p.Limit())
+			assert.Equal(t, tC.page, p.Page())
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"go-mygram/pkg"
+)
+
+// Limiter decides whether another attempt identified by key is allowed,
+// retryAfter tells the caller how long to wait when it is not
+type Limiter interface {
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+	Reset(ctx context.Context, key string) error
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryLimiter is a token bucket per key holding up to limit tokens,
+// refilled evenly over window
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	limit   float64
+	rate    float64 // tokens per second
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryLimiter(limit int, window time.Duration) *MemoryLimiter {
+	return &MemoryLimiter{
+		limit:   float64(limit),
+		rate:    float64(limit) / window.Seconds(),
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (m *MemoryLimiter) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: m.limit, last: now}
+		m.buckets[key] = b
+	}
+
+	// refill what was earned since the last attempt
+	b.tokens = math.Min(m.limit, b.tokens+now.Sub(b.last).Seconds()*m.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+	wait := time.Duration((1 - b.tokens) / m.rate * float64(time.Second))
+	return false, wait, nil
+}
+
+func (m *MemoryLimiter) Reset(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.buckets, key)
+	return nil
+}
+
+// Cleanup drops the buckets that refilled completely, a key coming back
+// starts with a full bucket anyway
+func (m *MemoryLimiter) Cleanup() {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, b := range m.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*m.rate >= m.limit {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// StartCleanup runs Cleanup every interval until ctx is done
+func (m *MemoryLimiter) StartCleanup(ctx context.Context, interval time.Duration) {
+	pkg.RunEvery(ctx, interval, m.Cleanup)
+}
@@ -0,0 +1,25 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLimiterCleanup(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	_, _, _ = limiter.Allow(context.Background(), "old")
+	now = now.Add(45 * time.Second)
+	_, _, _ = limiter.Allow(context.Background(), "new")
+	now = now.Add(15 * time.Second)
+
+	// old earned its token back over the window, new is still one short
+	limiter.Cleanup()
+	assert.NotContains(t, limiter.buckets, "old")
+	assert.Contains(t, limiter.buckets, "new")
+}
@@ -0,0 +1,29 @@
+package pkg
+
+import "context"
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the gin context key holding the request id
+	RequestIDKey = "request_id"
+)
+
+type requestIDCtxKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request id, so services
+// and repositories can read it without depending on gin
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+// RequestID returns the request id stored in ctx, it accepts both a plain
+// context and a *gin.Context
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDCtxKey{}).(string); ok {
+		return id
+	}
+	if id, ok := ctx.Value(RequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
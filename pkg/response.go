@@ -0,0 +1,107 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuccessResponse wraps every successful payload so clients always read
+// the result from "data"
+type SuccessResponse struct {
+	Data    any    `json:"data,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Warnings flag input that was accepted but looks off
+	Warnings []FieldError `json:"warnings,omitempty"`
+}
+
+// ServerErrorMessage is all a client learns about a 500 in production
+const ServerErrorMessage = "internal server error"
+
+// WriteSuccess writes data inside a SuccessResponse envelope
+func WriteSuccess(ctx *gin.Context, status int, data any) {
+	ctx.JSON(status, SuccessResponse{Data: data})
+}
+
+// WriteSuccessWithWarnings is WriteSuccess that also reports warnings,
+// the field is left out when there are none
+func WriteSuccessWithWarnings(ctx *gin.Context, status int, data any, warnings []FieldError) {
+	ctx.JSON(status, SuccessResponse{Data: data, Warnings: warnings})
+}
+
+// SetLocation points the Location header of a 201 at the created
+// resource. ref is resolved against the request path like a link would be,
+// so handlers don't need to know where they are mounted: on POST
+// /api/v1/photos, "photos/5" becomes /api/v1/photos/5
+func SetLocation(ctx *gin.Context, ref string) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return
+	}
+	ctx.Header("Location", ctx.Request.URL.ResolveReference(u).String())
+}
+
+// WriteMessage writes a SuccessResponse that only carries a message
+func WriteMessage(ctx *gin.Context, status int, message string) {
+	ctx.JSON(status, SuccessResponse{Message: message})
+}
+
+// WriteError writes an ErrorResponse tagged with the request id and the
+// code of status, errs carries optional details
+func WriteError(ctx *gin.Context, status int, message string, errs ...string) {
+	WriteErrorCode(ctx, status, CodeForStatus(status), message, errs...)
+}
+
+// WriteErrorCode is WriteError with a code more specific than the one of
+// status
+func WriteErrorCode(ctx *gin.Context, status int, code, message string, errs ...string) {
+	ctx.JSON(status, newErrorResponse(ctx, code, message, errs))
+}
+
+// WriteServerError writes the response for an unexpected err and records
+// it for the logs. A database call that ran out of time is a 504 and a
+// request abandoned by the client a 503, anything else is a 500. The 500
+// carries message and the cause only when VerboseErrors is set, message is
+// often the raw error of the service
+func WriteServerError(ctx *gin.Context, err error, message string) {
+	_ = ctx.Error(err)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		WriteError(ctx, http.StatusGatewayTimeout, "request timed out")
+	case errors.Is(err, context.Canceled):
+		WriteError(ctx, http.StatusServiceUnavailable, "request cancelled")
+	case VerboseErrors:
+		resp := newErrorResponse(ctx, CodeInternal, message, nil)
+		resp.Debug = NewErrorDebug(err.Error())
+		ctx.JSON(http.StatusInternalServerError, resp)
+	default:
+		WriteError(ctx, http.StatusInternalServerError, ServerErrorMessage)
+	}
+}
+
+// AbortWithError is WriteError for middlewares, the remaining handlers in
+// the chain are skipped
+func AbortWithError(ctx *gin.Context, status int, message string, errs ...string) {
+	AbortWithErrorCode(ctx, status, CodeForStatus(status), message, errs...)
+}
+
+// AbortWithErrorCode is WriteErrorCode for middlewares
+func AbortWithErrorCode(ctx *gin.Context, status int, code, message string, errs ...string) {
+	ctx.AbortWithStatusJSON(status, newErrorResponse(ctx, code, message, errs))
+}
+
+// WriteValidationError writes a 400 listing every invalid field
+func WriteValidationError(ctx *gin.Context, err error) {
+	resp := NewValidationErrorResponse(err)
+	resp.RequestID = RequestID(ctx)
+	ctx.JSON(http.StatusBadRequest, resp)
+}
+
+func newErrorResponse(ctx *gin.Context, code, message string, errs []string) ErrorResponse {
+	// recorded so the request logger can report what the client was told
+	_ = ctx.Error(errors.New(message))
+	return ErrorResponse{Code: code, Message: message, Errors: errs, RequestID: RequestID(ctx)}
+}
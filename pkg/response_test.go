@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteServerError(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		err       error
+		code      int
+		errorCode string
+	}{
+		{desc: "query timeout", err: fmt.Errorf("%w: canceling query", context.DeadlineExceeded), code: http.StatusGatewayTimeout, errorCode: CodeTimeout},
+		{desc: "request cancelled", err: context.Canceled, code: http.StatusServiceUnavailable, errorCode: CodeUnavailable},
+		{desc: "other error", err: errors.New("boom"), code: http.StatusInternalServerError, errorCode: CodeInternal},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			WriteServerError(g, tC.err, "failed")
+
+			assert.Equal(t, tC.code, rec.Code)
+			assert.ErrorIs(t, g.Errors[0], tC.err)
+			var resp ErrorResponse
+			assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tC.errorCode, resp.Code)
+		})
+	}
+}
+
+func TestWriteServerErrorVerbosity(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		verbose bool
+		message string
+	}{
+		{desc: "success cause hidden in production", verbose: false, message: ServerErrorMessage},
+		{desc: "success cause shown in development", verbose: true, message: "failed"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			VerboseErrors = tC.verbose
+			defer func() { VerboseErrors = false }()
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			WriteServerError(g, errors.New("pq: relation \"users\" does not exist"), "failed")
+
+			var resp ErrorResponse
+			assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tC.message, resp.Message)
+			if !tC.verbose {
+				assert.Nil(t, resp.Debug)
+				assert.NotContains(t, rec.Body.String(), "pq:")
+				return
+			}
+			assert.Equal(t, `pq: relation "users" does not exist`, resp.Debug.Error)
+			assert.NotEmpty(t, resp.Debug.Stack)
+		})
+	}
+}
+
+func TestWriteErrorCode(t *testing.T) {
+	newContext := func() (*gin.Context, *httptest.ResponseRecorder) {
+		gin.SetMode(gin.TestMode)
+		rec := httptest.NewRecorder()
+		g, _ := gin.CreateTestContext(rec)
+		g.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		return g, rec
+	}
+
+	t.Run("success code follows the status", func(t *testing.T) {
+		g, rec := newContext()
+		WriteError(g, http.StatusTooManyRequests, "slow down")
+
+		assert.JSONEq(t, `{"code":"RATE_LIMITED","message":"slow down"}`, rec.Body.String())
+	})
+	t.Run("success specific code", func(t *testing.T) {
+		g, rec := newContext()
+		WriteErrorCode(g, http.StatusNotFound, CodeUserNotFound, "user not found")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"code":"USER_NOT_FOUND","message":"user not found"}`, rec.Body.String())
+	})
+	t.Run("success validation errors", func(t *testing.T) {
+		g, rec := newContext()
+		var verrs ValidationErrors
+		verrs.Add("email", "invalid email")
+		WriteValidationError(g, verrs)
+
+		assert.JSONEq(t, `{"code":"VALIDATION_FAILED","message":"invalid email","errors":[{"field":"email","message":"invalid email"}]}`, rec.Body.String())
+	})
+}
+
+func TestSetLocation(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		path     string
+		ref      string
+		location string
+	}{
+		{desc: "collection", path: "/api/photos", ref: "photos/5", location: "/api/photos/5"},
+		{desc: "sibling of the action", path: "/api/users/register", ref: "7", location: "/api/users/7"},
+		{desc: "nested route", path: "/api/photos/5/comments", ref: "../../comments/9", location: "/api/comments/9"},
+		{desc: "absolute url", path: "/api/photos/images", ref: "https://cdn.example.com/a.png", location: "https://cdn.example.com/a.png"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			g, _ := gin.CreateTestContext(rec)
+			g.Request = httptest.NewRequest(http.MethodPost, tC.path, nil)
+
+			SetLocation(g, tC.ref)
+			assert.Equal(t, tC.location, rec.Header().Get("Location"))
+		})
+	}
+}
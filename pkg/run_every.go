@@ -0,0 +1,23 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+// RunEvery calls fn every interval in the background until ctx is done, the
+// first call is one interval away
+func RunEvery(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+}
@@ -0,0 +1,25 @@
+package pkg
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int64
+	RunEvery(ctx, time.Millisecond, func() { calls.Add(1) })
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	// a tick already taken may still finish
+	time.Sleep(5 * time.Millisecond)
+	stopped := calls.Load()
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, stopped, calls.Load())
+}
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// rawTextElements are read by the tokenizer as raw text or RCDATA, their
+// body is markup a browser may still parse once it is stored as text
+var rawTextElements = map[atom.Atom]bool{
+	atom.Iframe:    true,
+	atom.Noembed:   true,
+	atom.Noframes:  true,
+	atom.Noscript:  true,
+	atom.Plaintext: true,
+	atom.Script:    true,
+	atom.Style:     true,
+	atom.Textarea:  true,
+	atom.Title:     true,
+	atom.Xmp:       true,
+}
+
+// StripHTML removes the markup from s so stored text can't inject script
+// once rendered. Tags and comments are dropped, the content of raw text
+// elements such as script, style and textarea too. Entities are kept as written, an escaped tag stays
+// escaped. A tag left open at the end, as in "a<b c", is kept as text with
+// its < escaped, so it can't be closed by whatever is rendered after it
+func StripHTML(s string) string {
+	if !strings.ContainsAny(s, "<>") {
+		return s
+	}
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	// the tokenizer reads the body of raw text elements as text
+	skipping := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			// io.EOF, the reader can't fail otherwise. Raw holds the tag
+			// that was still open
+			if !skipping {
+				b.WriteString(strings.ReplaceAll(string(z.Raw()), "<", "&lt;"))
+			}
+			return b.String()
+		case html.TextToken:
+			if !skipping {
+				b.Write(z.Raw())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			// the tokenizer treats <textarea/> as a start tag too
+			name, _ := z.TagName()
+			if rawTextElements[atom.Lookup(name)] {
+				skipping = true
+			}
+		case html.EndTagToken:
+			skipping = false
+		}
+	}
+}
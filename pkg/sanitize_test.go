@@ -0,0 +1,44 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripHTML(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		out  string
+	}{
+		{desc: "plain text is untouched", in: "Tom & Jerry, 3 > 2", out: "Tom & Jerry, 3 > 2"},
+		{desc: "script tag and body removed", in: `hi <script>alert("xss")</script>there`, out: "hi there"},
+		{desc: "uppercase script tag", in: `<SCRIPT SRC=//evil.example/x.js></SCRIPT>sunset`, out: "sunset"},
+		{desc: "event handler attribute", in: `<img src=x onerror="alert(1)">beach`, out: "beach"},
+		{desc: "formatting tags keep their text", in: "<b>bold</b> and <i>italic</i>", out: "bold and italic"},
+		{desc: "style body removed", in: "<style>body{display:none}</style>ok", out: "ok"},
+		{desc: "comment removed", in: "a<!-- <script>x</script> -->b", out: "ab"},
+		{desc: "escaped tag stays escaped", in: "&lt;script&gt;alert(1)&lt;/script&gt;", out: "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{desc: "only markup becomes empty", in: "<script>alert(1)</script>", out: ""},
+		{desc: "mentions survive", in: "<b>@alice</b> look", out: "@alice look"},
+		{desc: "unterminated tag kept escaped", in: "a<b c", out: "a&lt;b c"},
+		{desc: "unterminated event handler kept escaped", in: "hi <img src=x onerror=alert(1) ", out: "hi &lt;img src=x onerror=alert(1) "},
+		{desc: "less than without a tag", in: "x <3 y", out: "x <3 y"},
+		{desc: "unterminated script body dropped", in: "ok<script>alert(1)", out: "ok"},
+		{desc: "iframe body removed", in: "a<iframe><img src=x onerror=alert(1)></iframe>b", out: "ab"},
+		{desc: "noembed body removed", in: "a<noembed><img src=x onerror=alert(1)></noembed>b", out: "ab"},
+		{desc: "noframes body removed", in: "a<noframes><img src=x onerror=alert(1)></noframes>b", out: "ab"},
+		{desc: "noscript body removed", in: "a<noscript><img src=x onerror=alert(1)></noscript>b", out: "ab"},
+		{desc: "plaintext body removed", in: "a<plaintext><img src=x onerror=alert(1)>", out: "a"},
+		{desc: "textarea body removed", in: "a<textarea><img src=x onerror=alert(1)></textarea>b", out: "ab"},
+		{desc: "title body removed", in: "a<title><img src=x onerror=alert(1)></title>b", out: "ab"},
+		{desc: "xmp body removed", in: "a<xmp><img src=x onerror=alert(1)></xmp>b", out: "ab"},
+		{desc: "self closing textarea body removed", in: "a<textarea/><img src=x onerror=alert(1)></textarea>b", out: "ab"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.out, StripHTML(tC.in))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// fakeURLPrefix marks urls issued by Fake
+const fakeURLPrefix = "fake://"
+
+// Fake keeps files in memory, it is meant for tests
+type Fake struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	// PutErr, when set, is returned by every Put
+	PutErr error
+}
+
+func NewFake() *Fake {
+	return &Fake{files: map[string][]byte{}}
+}
+
+func (f *Fake) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	if f.PutErr != nil {
+		return "", f.PutErr
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return "", err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files[key] = buf.Bytes()
+	return fakeURLPrefix + key, nil
+}
+
+func (f *Fake) Delete(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.files, key)
+	return nil
+}
+
+func (f *Fake) KeyFromURL(url string) (string, bool) {
+	return strings.CutPrefix(url, fakeURLPrefix)
+}
+
+// File returns the content stored under key
+func (f *Fake) File(key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.files[key]
+	return data, ok
+}
+
+// Keys returns the keys currently stored
+func (f *Fake) Keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.files))
+	for key := range f.files {
+		keys = append(keys, key)
+	}
+	return keys
+}
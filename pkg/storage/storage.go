@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps uploaded files. Keys are slash separated paths such as
+// "avatars/7-abc.png", an implementation for a cloud bucket only has to map
+// them onto object names and return the public url
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (url string, err error)
+	Delete(ctx context.Context, key string) error
+	// KeyFromURL reverses Put, false means the url was not issued by
+	// this storage
+	KeyFromURL(url string) (string, bool)
+}
+
+// LocalStorage writes files below dir, they are expected to be served
+// under baseURL
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (l *LocalStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// write next to the target and rename, readers never see half a file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return l.baseURL + "/" + key, nil
+}
+
+// Delete succeeds when the file is already gone
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *LocalStorage) KeyFromURL(url string) (string, bool) {
+	return strings.CutPrefix(url, l.baseURL+"/")
+}
+
+// path maps key inside dir, keys escaping it are rejected
+func (l *LocalStorage) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
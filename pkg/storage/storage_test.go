@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(dir, "/uploads/")
+
+	t.Run("success save and delete", func(t *testing.T) {
+		url, err := s.Put(context.Background(), "avatars/7-abc.png", strings.NewReader("png"), "image/png")
+		assert.Nil(t, err)
+		assert.Equal(t, "/uploads/avatars/7-abc.png", url)
+
+		data, err := os.ReadFile(filepath.Join(dir, "avatars", "7-abc.png"))
+		assert.Nil(t, err)
+		assert.Equal(t, "png", string(data))
+
+		key, ok := s.KeyFromURL(url)
+		assert.True(t, ok)
+		assert.Nil(t, s.Delete(context.Background(), key))
+		_, err = os.Stat(filepath.Join(dir, "avatars", "7-abc.png"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+
+		// deleting twice is fine
+		assert.Nil(t, s.Delete(context.Background(), key))
+	})
+
+	t.Run("error key escaping the directory", func(t *testing.T) {
+		for _, key := range []string{"", "../secret", "/etc/passwd", "avatars/../../x"} {
+			_, err := s.Put(context.Background(), key, strings.NewReader("x"), "image/png")
+			assert.ErrorIs(t, err, ErrInvalidKey, key)
+		}
+	})
+
+	t.Run("success foreign url has no key", func(t *testing.T) {
+		_, ok := s.KeyFromURL("https://cdn.example.com/a.png")
+		assert.False(t, ok)
+	})
+}
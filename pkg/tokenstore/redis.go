@@ -0,0 +1,43 @@
+package tokenstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore keeps revoked jtis as keys that expire together with the
+// token, so redis does the cleanup itself
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore stores the jtis under prefix, instances sharing a redis
+// must use the same prefix to see each other's sign outs
+func NewRedisStore(client redis.UniversalClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (r *RedisStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	// an expired token is rejected anyway
+	if ttl <= 0 {
+		return nil
+	}
+	return r.client.Set(ctx, r.prefix+jti, 1, ttl).Err()
+}
+
+func (r *RedisStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := r.client.Exists(ctx, r.prefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Cleanup has nothing to do, the keys expire on their own
+func (r *RedisStore) Cleanup(ctx context.Context) error {
+	return nil
+}
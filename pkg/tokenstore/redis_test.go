@@ -0,0 +1,55 @@
+package tokenstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisStore(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer client.Close()
+	store := NewRedisStore(client, "revoked:")
+	ctx := context.Background()
+
+	t.Run("success revoked until the token expires", func(t *testing.T) {
+		assert.Nil(t, store.Revoke(ctx, "jti-1", time.Now().Add(time.Hour)))
+
+		revoked, err := store.IsRevoked(ctx, "jti-1")
+		assert.Nil(t, err)
+		assert.True(t, revoked)
+		assert.True(t, srv.Exists("revoked:jti-1"))
+
+		srv.FastForward(2 * time.Hour)
+		revoked, err = store.IsRevoked(ctx, "jti-1")
+		assert.Nil(t, err)
+		assert.False(t, revoked)
+	})
+
+	t.Run("success expired token is not stored", func(t *testing.T) {
+		assert.Nil(t, store.Revoke(ctx, "jti-2", time.Now().Add(-time.Minute)))
+		assert.False(t, srv.Exists("revoked:jti-2"))
+	})
+
+	t.Run("success shared between stores", func(t *testing.T) {
+		other := NewRedisStore(redis.NewClient(&redis.Options{Addr: srv.Addr()}), "revoked:")
+		assert.Nil(t, other.Revoke(ctx, "jti-3", time.Now().Add(time.Hour)))
+
+		revoked, err := store.IsRevoked(ctx, "jti-3")
+		assert.Nil(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("error redis unavailable", func(t *testing.T) {
+		srv.SetError("LOADING")
+		defer srv.SetError("")
+
+		_, err := store.IsRevoked(ctx, "jti-1")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,66 @@
+package tokenstore
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go-mygram/pkg"
+)
+
+// Store keeps track of access tokens (by jti) that were revoked before
+// their natural expiry, e.g. on sign out. MemoryStore only serves a single
+// instance, RedisStore is shared by every instance pointing at the same redis
+type Store interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// Cleanup drops entries whose token already expired
+	Cleanup(ctx context.Context) error
+}
+
+type MemoryStore struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{revoked: map[string]time.Time{}}
+}
+
+func (m *MemoryStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revoked[jti] = expiresAt
+	return nil
+}
+
+func (m *MemoryStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.revoked[jti]
+	return ok, nil
+}
+
+// Cleanup drops entries whose token already expired, those tokens are
+// rejected by the expiry check anyway
+func (m *MemoryStore) Cleanup(ctx context.Context) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for jti, exp := range m.revoked {
+		if now.After(exp) {
+			delete(m.revoked, jti)
+		}
+	}
+	return nil
+}
+
+// StartCleanup runs store.Cleanup every interval until ctx is done
+func StartCleanup(ctx context.Context, store Store, interval time.Duration) {
+	pkg.RunEvery(ctx, interval, func() {
+		if err := store.Cleanup(ctx); err != nil {
+			log.Printf("failed to clean up revoked tokens: %v", err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects every field that failed validation so the
+// client can map each message back to its input
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, fe := range v {
+		msgs = append(msgs, fe.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is makes every ValidationErrors an ErrValidation
+func (v ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Add appends a field error
+func (v *ValidationErrors) Add(field, message string) {
+	*v = append(*v, FieldError{Field: field, Message: message})
+}
+
+// Err returns nil when nothing was collected, so callers never end up with
+// a non-nil error interface holding an empty slice
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+type ValidationErrorResponse struct {
+	// always CodeValidationFailed
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+func NewValidationErrorResponse(err error) ValidationErrorResponse {
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		return ValidationErrorResponse{Code: CodeValidationFailed, Message: verrs.Error(), Errors: verrs}
+	}
+	return ValidationErrorResponse{Code: CodeValidationFailed, Message: err.Error()}
+}
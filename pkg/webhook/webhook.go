@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// event types sent to subscribers
+const (
+	EventUserCreated = "user.created"
+)
+
+type Event struct {
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// Webhook notifies an external subscriber of an event. Dispatch returns
+// without waiting for the delivery, a failed one is only logged
+type Webhook interface {
+	Dispatch(ctx context.Context, event Event)
+}
+
+type nopWebhookImpl struct{}
+
+// NewNopWebhook returns a webhook that drops every event, it is used when
+// no url is configured
+func NewNopWebhook() Webhook {
+	return nopWebhookImpl{}
+}
+
+func (nopWebhookImpl) Dispatch(ctx context.Context, event Event) {}
+
+type Config struct {
+	URL string
+	// bounds every attempt on its own
+	Timeout time.Duration
+	// attempts after the first one, the wait doubles from RetryBackoff
+	MaxRetries   int
+	RetryBackoff time.Duration
+}
+
+// HTTPWebhook POSTs events as JSON to a url in the background
+type HTTPWebhook struct {
+	cfg    Config
+	client *http.Client
+	logger *slog.Logger
+	wg     sync.WaitGroup
+}
+
+func NewHTTPWebhook(cfg Config, logger *slog.Logger) *HTTPWebhook {
+	return &HTTPWebhook{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+	}
+}
+
+func (h *HTTPWebhook) Dispatch(ctx context.Context, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode webhook event", slog.String("type", event.Type), slog.Any("error", err))
+		return
+	}
+	// the delivery outlives the request that triggered it
+	ctx = context.WithoutCancel(ctx)
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		if err := h.deliver(ctx, body); err != nil {
+			h.logger.ErrorContext(ctx, "failed to deliver webhook", slog.String("type", event.Type), slog.Any("error", err))
+		}
+	}()
+}
+
+// Wait blocks until every dispatched event is delivered or given up on
+func (h *HTTPWebhook) Wait() {
+	h.wg.Wait()
+}
+
+func (h *HTTPWebhook) deliver(ctx context.Context, body []byte) error {
+	backoff := h.cfg.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = h.post(ctx, body)
+		if err == nil || !retry || attempt >= h.cfg.MaxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends one attempt and reports whether a failure is worth retrying
+func (h *HTTPWebhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	res.Body.Close()
+	if res.StatusCode < 300 {
+		return false, nil
+	}
+	// other client errors won't change on a second try
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+}
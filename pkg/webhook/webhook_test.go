@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPWebhook(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		statuses []int
+		attempts int32
+	}{
+		{desc: "success first attempt", statuses: []int{http.StatusNoContent}, attempts: 1},
+		{desc: "success after server errors", statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK}, attempts: 3},
+		{desc: "error gives up after the retries", statuses: []int{http.StatusInternalServerError}, attempts: 3},
+		{desc: "error client error is not retried", statuses: []int{http.StatusBadRequest}, attempts: 1},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1))
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				var event Event
+				assert.Nil(t, json.NewDecoder(r.Body).Decode(&event))
+				assert.Equal(t, EventUserCreated, event.Type)
+				w.WriteHeader(tC.statuses[min(n, len(tC.statuses))-1])
+			}))
+			defer srv.Close()
+
+			hook := NewHTTPWebhook(Config{URL: srv.URL, Timeout: time.Second, MaxRetries: 2, RetryBackoff: time.Millisecond}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			ctx, cancel := context.WithCancel(context.Background())
+			hook.Dispatch(ctx, Event{Type: EventUserCreated, Data: map[string]any{"id": 1}})
+			// the delivery doesn't depend on the request context
+			cancel()
+			hook.Wait()
+
+			assert.Equal(t, tC.attempts, attempts.Load())
+		})
+	}
+
+	t.Run("error timeout is retried", func(t *testing.T) {
+		var attempts atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				time.Sleep(100 * time.Millisecond)
+			}
+		}))
+		defer srv.Close()
+
+		hook := NewHTTPWebhook(Config{URL: srv.URL, Timeout: 20 * time.Millisecond, MaxRetries: 1, RetryBackoff: time.Millisecond}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		hook.Dispatch(context.Background(), Event{Type: EventUserCreated})
+		hook.Wait()
+
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+}